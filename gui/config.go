@@ -104,8 +104,8 @@ func (a *App) ValidateConfig(cfg *config.Config) error {
 	}
 	
 	// 检查数值范围
-	if cfg.Common.MainMode < 0 || cfg.Common.MainMode > 3 {
-		return fmt.Errorf("运行模式值无效 (0-3): %d", cfg.Common.MainMode)
+	if cfg.Common.MainMode < 0 || cfg.Common.MainMode > 4 {
+		return fmt.Errorf("运行模式值无效 (0-4): %d", cfg.Common.MainMode)
 	}
 	
 	if cfg.Common.LinkMode < 0 || cfg.Common.LinkMode > 2 {
@@ -204,7 +204,7 @@ func (r *Runner) run() {
 		r.app.SendProgress()
 		
 		// 延迟（防止请求过快）
-		if r.app.config.Common.Sleep > 0 {
+		if r.app.config.Common.Sleep > 0 && !r.app.config.Common.AdaptiveSleep {
 			time.Sleep(time.Duration(r.app.config.Common.Sleep) * time.Second)
 		}
 	}
@@ -268,16 +268,8 @@ func (r *Runner) getMediaTypes() []string {
 		return []string{".mp4", ".avi", ".mkv", ".rmvb", ".wmv", ".mov", ".flv", ".ts", ".webm", ".iso"}
 	}
 	
-	// 从配置中解析媒体类型
-	types := strings.Split(r.app.config.Media.MediaType, ",")
-	result := make([]string, 0, len(types))
-	for _, t := range types {
-		t = strings.TrimSpace(t)
-		if t != "" {
-			result = append(result, strings.ToLower(t))
-		}
-	}
-	return result
+	// 从配置中解析媒体类型（含 video_extensions）
+	return r.app.config.GetMediaTypes()
 }
 
 // shouldSkipFolder 判断是否应该跳过文件夹
@@ -291,6 +283,8 @@ func (r *Runner) shouldSkipFolder(path, name string) bool {
 	skipFolders := []string{
 		r.app.config.Common.SuccessOutputFolder,
 		r.app.config.Common.FailedOutputFolder,
+		r.app.config.NameRule.CensoredRoot,
+		r.app.config.NameRule.UncensoredRoot,
 		"failed",
 		"JAV_output",
 	}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"gopkg.in/yaml.v3"
 )
@@ -29,10 +30,12 @@ type Config struct {
 	CCConvert    CCConvertConfig    `yaml:"cc_convert"`
 	Javdb        JavdbConfig        `yaml:"javdb"`
 	Face         FaceConfig         `yaml:"face"`
+	Image        ImageConfig        `yaml:"image"`
 	Jellyfin     JellyfinConfig     `yaml:"jellyfin"`
 	ActorPhoto   ActorPhotoConfig   `yaml:"actor_photo"`
 	STRM         STRMConfig         `yaml:"strm"`
 	Scraper      ScraperConfig      `yaml:"scraper"`
+	Content      ContentConfig      `yaml:"content"`
 }
 
 type CommonConfig struct {
@@ -58,15 +61,54 @@ type CommonConfig struct {
 	MultiThreading             int    `yaml:"multi_threading"`
 	StopCounter                int    `yaml:"stop_counter"`
 	RerunDelay                 string `yaml:"rerun_delay"`
+	MaxDuration                string `yaml:"max_duration"` // 处理总时长上限，如 30m、1h30m，到时不再开始新的影片（0=不限制）
+	FailureCooldownAfter       int    `yaml:"failure_cooldown_after"` // 连续多少部影片因网站不可用（网络错误、5xx、429、熔断）刮削失败后暂停整个运行，未找到番号不计入（0=不暂停）
+	FailureCooldown            string `yaml:"failure_cooldown"`       // 暂停时长，如 10m、1h（留空则10分钟），避免在被封禁时继续请求
+	MaxInflightRequests        int     `yaml:"max_inflight_requests"`    // 全局最大并发HTTP请求数（抓取+下载共享，0=不限制）
+	MaxConcurrentDownloads     int     `yaml:"max_concurrent_downloads"` // 所有影片合计同时进行的下载数（封面、预告片、剧照、演员头像，0=不限制）
+	ScrapeRetries              int     `yaml:"scrape_retries"`           // 刮削请求遇到网络错误、5xx或429时的重试次数（404等不重试，0=不重试）
+	ScrapeRetryDelay           float64 `yaml:"scrape_retry_delay"`       // 第一次重试前的等待秒数，之后指数退避（0=默认0.5秒）
+	ReportFile                 string  `yaml:"report_file"`              // 运行报告输出路径（JSON，留空则只输出到日志）
+	LowConfidenceThreshold     float64 `yaml:"low_confidence_threshold"` // 低于该置信度的结果在报告中标记（默认0.6）
+	ScanMaxDepth               int     `yaml:"scan_max_depth"`           // 扫描源目录的最大深度（0=使用默认值32）
+	DownloadTempSuffix         string  `yaml:"download_temp_suffix"`     // 下载中的临时文件后缀，完成后再重命名（留空则使用 .part）
+	ImageCacheDir              string  `yaml:"image_cache_dir"`          // 图片缓存目录，再次下载时用 ETag/Last-Modified 校验，未变化的图片直接取缓存（留空则不缓存）
+	ImageCacheMaxSize          int     `yaml:"image_cache_max_size"`     // 图片缓存目录的大小上限（MB，0=不限制），启动时删除最久未使用的图片
+	HTMLIndexDir               string  `yaml:"html_index_dir"`           // 生成按演员/片商浏览的静态 index.html 的目录，每次整理后更新（留空则不生成）
+	FilenameEncoding           string  `yaml:"filename_encoding"`        // 非UTF-8文件名的编码，移动时转换为UTF-8：shift_jis, euc-jp, gbk, big5, latin1（留空则保留原始字节）
+	MaxDNSLookups              int     `yaml:"max_dns_lookups"`          // 同时进行的DNS查询上限（0=不限制）
+	DNSCacheTTL                int     `yaml:"dns_cache_ttl"`            // DNS查询结果缓存时间（秒，0=不缓存）
+	ProcessedMarker            bool    `yaml:"processed_marker"`         // 子目录中的影片全部处理成功后写入 .mdc_processed 标记，之后扫描跳过该目录
+	ProcessedManifest          string  `yaml:"processed_manifest"`       // 已处理清单路径（JSON），记录每个文件的处理结果，并发处理时由单独的写入协程原子写入（留空则不记录）
+	ForceRescan                bool    `yaml:"-"`                        // 忽略已处理标记重新扫描（仅由命令行 --force 设置）
+	RecoveryFile               string  `yaml:"recovery_file"`            // 断点续传状态文件，每处理完一个文件记录一次，中断后再次运行从断点继续（留空则不启用）
+	StatsAddr                  string  `yaml:"stats_addr"`               // 运行状态HTTP服务监听地址，如 127.0.0.1:9311，提供 /stats 和 /recovery（留空则不启用）
+	FileLock                   bool    `yaml:"file_lock"`                // 处理影片时在同目录创建 .mdc.lock 锁文件，防止多个实例同时处理同一文件
+	LibraryIndex               string  `yaml:"library_index"`            // 影片库SQLite索引文件路径，每处理成功一部影片更新一次（留空则不启用）
+	AllowedPrefixes            []string `yaml:"allowed_prefixes"`        // 只处理番号以这些前缀开头的文件，如 ["SSIS", "IPX", "FC2"]，其余跳过（留空则不限制）
+	NFOParseWorkers            int     `yaml:"nfo_parse_workers"`        // 批量读取已有NFO（重新整理/刷新）时的并发数（0=CPU核数）
+	VerifyWorkers              int     `yaml:"verify_workers"`           // --verify 同时检查的影片文件夹数（0=CPU核数）
+	AdaptiveSleep              bool    `yaml:"adaptive_sleep"`           // 按各网站实测延迟和错误率自动调整请求间隔，替代固定的 sleep
+	AdaptiveSleepMax           int     `yaml:"adaptive_sleep_max"`       // 自适应间隔的上限（秒，0=使用默认值10）
+	SkipInUse                  bool    `yaml:"skip_in_use"`              // 跳过正在被写入或占用的文件（下载中、播放中），下次运行再处理
+	InUseQuietPeriod           int     `yaml:"in_use_quiet_period"`      // 文件最近多少秒内被修改过即视为仍在写入（0=只检查能否独占打开）
+	DryRun                     bool    `yaml:"dry_run"`                  // 演练模式：只记录计划的移动（源→目标），不创建目录、不移动文件、不下载图片和写NFO，结束时输出汇总
+	SkipExisting               bool    `yaml:"skip_existing"`            // 输出目录中已有该番号的NFO或影片文件时跳过，不再刮削（用于增量整理）
+	FailureReasons             bool    `yaml:"failure_reasons"`          // 刮削失败时将各数据源的失败原因写入失败文件夹的 failed_reasons.txt
+	MetadataCacheDir           string  `yaml:"metadata_cache_dir"`       // 影片元数据缓存目录，保存翻译后的结果，再次运行时直接使用，不再刮削和翻译（留空则不缓存）
+	MetadataCacheDays          int     `yaml:"metadata_cache_days"`      // 元数据缓存有效天数（0=永不过期）
+	StrictValidation           bool    `yaml:"strict_validation"`        // 严格校验：番号、标题为空或封面不是图片地址时视为刮削失败，移入失败文件夹
 }
 
 type ProxyConfig struct {
-	Switch     bool   `yaml:"switch"`
-	Proxy      string `yaml:"proxy"`
-	Timeout    int    `yaml:"timeout"`
-	Retry      int    `yaml:"retry"`
-	Type       string `yaml:"type"`
-	CACertFile string `yaml:"cacert_file"`
+	Switch     bool                   `yaml:"switch"`
+	Proxy      string                 `yaml:"proxy"`
+	Timeout    int                    `yaml:"timeout"`
+	Retry      int                    `yaml:"retry"`
+	Type       string                 `yaml:"type"`
+	CACertFile string                 `yaml:"cacert_file"`
+	PerSource  map[string]ProxyConfig `yaml:"per_source,omitempty"` // 按数据源覆盖代理（键为数据源名，switch: false 表示该数据源直连），未配置的数据源使用全局代理
+	ImageProxy *ProxyConfig           `yaml:"image_proxy,omitempty"` // 图片和预告片下载使用的代理（switch: false 表示直连），未配置时使用全局代理
 }
 
 type NameRuleConfig struct {
@@ -74,8 +116,25 @@ type NameRuleConfig struct {
 	NamingRule             string `yaml:"naming_rule"`
 	MaxTitleLen            int    `yaml:"max_title_len"`
 	ImageNamingWithNumber  bool   `yaml:"image_naming_with_number"`
+	ImageNumberPrefix      []string `yaml:"image_number_prefix"` // 使用番号前缀命名的图片类型（poster, fanart, thumb），其余使用 poster.jpg 这样的简单名称；留空则由 image_naming_with_number 决定全部图片
 	NumberUppercase        bool   `yaml:"number_uppercase"`
 	NumberRegexs           string `yaml:"number_regexs"`
+	NFODialect             string `yaml:"nfo_dialect"` // NFO方言: kodi(默认), emby, both
+	NfoFlavor              string `yaml:"nfo_flavor"`  // NFO风格: kodi, jellyfin, emby；设置后完全取代 nfo_dialect（留空则由 jellyfin 和 nfo_dialect 决定）
+	ActorAliasFile         string `yaml:"actor_alias_file"` // 演员别名文件（YAML），用于统一名字和区分同名演员
+	MaxNFOActors           int    `yaml:"max_nfo_actors"`   // NFO中最多写入的演员数（0=不限制），其余演员汇总记录
+	StudioAliasFile        string `yaml:"studio_alias_file"` // 片商别名文件（YAML），将同一片商的不同写法统一为一个名字
+	YearSource             string `yaml:"year_source"`       // 刮削年份与文件名年份不一致时使用哪个：scraped(默认) 或 filename（同时替换发行日期的年份）
+	NFOExtraFields         []string `yaml:"nfo_extra_fields"` // 写入NFO的数据源特有字段（MovieData.Extra 的名称，"*"=全部，留空则不写入）
+	CensoredRoot           string   `yaml:"censored_root"`   // 有码影片的输出根目录（留空则使用 success_output_folder）
+	UncensoredRoot         string   `yaml:"uncensored_root"` // 无码影片的输出根目录（留空则使用 success_output_folder）
+	SeriesIndex            bool     `yaml:"series_index"`    // 从标题中提取系列序号（Vol.3、第3弾等），写入NFO排序标题使系列按顺序排列
+	ValidateNFO            bool     `yaml:"validate_nfo"`    // 写入NFO后检查结构（格式、必需元素、控制字符），能修复的自动修复
+	WindowsSafePaths       bool     `yaml:"windows_safe_paths"` // 在非Windows系统上也清理目录名中的结尾点/空格和保留设备名（CON、NUL等），用于写入Windows共享的媒体库
+	OutlineFallback        string   `yaml:"outline_fallback"`   // 未抓取到简介时的处理：empty(默认，保持为空) 或 generate(由标题、片商和演员生成简介)
+	SortTitleTemplate      string   `yaml:"sort_title_template"` // NFO排序标题模板（Go模板，字段同MovieData，如 {{.Series}} {{pad .SeriesIndex 3}} {{.Number}}；留空则使用默认规则）
+	BackupNFO              string   `yaml:"backup_nfo"`          // 覆盖已有NFO前的备份方式：off(默认，不备份), bak(保存为 .nfo.bak), timestamp(保存为 .nfo.<时间>.bak)
+	BackupNFOKeep          int      `yaml:"backup_nfo_keep"`     // timestamp 方式保留的备份数量（0=使用默认值5），超出时删除最旧的备份
 }
 
 type UpdateConfig struct {
@@ -92,7 +151,8 @@ type EscapeConfig struct {
 }
 
 type DebugModeConfig struct {
-	Switch bool `yaml:"switch"`
+	Switch    bool `yaml:"switch"`
+	HTTPTrace bool `yaml:"http_trace"` // 以debug级别记录每个HTTP请求（方法、URL、状态码、字节数、耗时，Cookie已脱敏）
 }
 
 type TranslateConfig struct {
@@ -106,7 +166,8 @@ type TranslateConfig struct {
 }
 
 type TrailerConfig struct {
-	Switch bool `yaml:"switch"`
+	Switch  bool   `yaml:"switch"`
+	Quality string `yaml:"quality"` // 有多个清晰度时的选择: highest(默认), lowest, first, 或上限如 720p
 }
 
 type UncensoredConfig struct {
@@ -114,19 +175,38 @@ type UncensoredConfig struct {
 }
 
 type MediaConfig struct {
-	MediaType string `yaml:"media_type"`
-	SubType   string `yaml:"sub_type"`
+	MediaType             string `yaml:"media_type"`
+	SubType               string `yaml:"sub_type"`
+	SubtitleGrouping      bool   `yaml:"subtitle_grouping"`       // 同名字幕（如idx+sub）成组移动并避免命名冲突
+	ConvertAssToSrt       bool   `yaml:"convert_ass_to_srt"`      // 移动后将ASS/SSA字幕额外转换为SRT
+	TagResolution         int    `yaml:"tag_resolution"`          // 按实际分辨率打标签：0=关闭，1=NFO标签，2=NFO标签+文件名
+	FFprobePath           string `yaml:"ffprobe_path"`            // ffprobe可执行文件路径（留空则从PATH查找）
+	ChineseSubtitleDetect int    `yaml:"chinese_subtitle_detect"` // 文件名无-C时检测中文字幕：0=关闭，1=外挂字幕，2=外挂字幕+内嵌字幕轨
+	MuxSubtitles          bool   `yaml:"mux_subtitles"`           // 整理时将外挂字幕封装进mkv（流复制，需要ffmpeg），成功后删除外挂字幕
+	MuxChapters           bool   `yaml:"mux_chapters"`            // 整理时将同名 .chapters.txt（FFMETADATA格式）中的章节封装进mkv
+	FFmpegPath            string `yaml:"ffmpeg_path"`             // ffmpeg可执行文件路径（留空则从PATH查找）
+	VideoExtensions       []string `yaml:"video_extensions"`               // 额外的视频扩展名，与 media_type 合并（如 [".m2ts"]）
+	ExtensionRules        map[string]ExtensionRule `yaml:"extension_rules"` // 按扩展名的扫描规则（键为扩展名，如 ".mkv"）
+}
+
+// ExtensionRule 扫描源目录时某一视频扩展名的处理规则
+type ExtensionRule struct {
+	MinSizeMB      int      `yaml:"min_size_mb"`     // 小于该大小的文件视为广告跳过（0=默认120MB，-1=不限制）
+	IgnorePatterns []string `yaml:"ignore_patterns"` // 文件名包含这些关键字（不区分大小写）时跳过，如 ["sample"]
 }
 
 type WatermarkConfig struct {
-	Switch bool `yaml:"switch"`
-	Water  int  `yaml:"water"`
+	Switch     bool   `yaml:"switch"`
+	Water      int    `yaml:"water"`
+	ApplyTo    string `yaml:"apply_to"`    // 水印应用的图片: poster, thumb, both（默认both）
+	SkipMarked bool   `yaml:"skip_marked"` // 在图片旁记录已添加的水印（.watermark 文件），重复处理时跳过已加过水印且未改变的图片
 }
 
 type ExtrafanartConfig struct {
 	Switch           bool   `yaml:"switch"`
 	ExtrafanartFolder string `yaml:"extrafanart_folder"`
 	ParallelDownload int    `yaml:"parallel_download"`
+	Dedup            bool   `yaml:"dedup"` // 去除重复的剧照（下载前按URL，下载后按文件内容）
 }
 
 type StorylineConfig struct {
@@ -152,6 +232,16 @@ type FaceConfig struct {
 	UncensoredOnly  bool    `yaml:"uncensored_only"`
 	AlwaysImagecut  bool    `yaml:"always_imagecut"`
 	AspectRatio     float64 `yaml:"aspect_ratio"`
+	CutRetries      int     `yaml:"cut_retries"` // 图片裁剪失败时的重试次数（0=使用默认值2）
+	MaxWorkers      int     `yaml:"max_workers"` // 同时进行人脸检测的最大数量（0=CPU核心数）
+	SmallCoverPoster []string `yaml:"small_cover_poster"` // 这些数据源的小封面直接作为海报，不再裁剪大封面（"*"=所有数据源）
+	SameSmallCover   string   `yaml:"same_small_cover"`   // 小封面与大封面是同一图片时（如DMM）：crop(默认)=裁剪大封面，download=仍然下载小封面
+}
+
+// ImageConfig 海报和背景图的来源，留空时沿用原有行为（由裁剪配置和 jellyfin 决定）
+type ImageConfig struct {
+	PosterSource string `yaml:"poster_source"` // 海报来源：cut=裁剪大封面, cover=直接使用大封面, cover_small=下载小封面（留空=按数据源和 face.small_cover_poster 决定）
+	FanartSource string `yaml:"fanart_source"` // 背景图来源：cover=使用大封面, none=不生成（留空=非Jellyfin时使用大封面）
 }
 
 type JellyfinConfig struct {
@@ -160,6 +250,7 @@ type JellyfinConfig struct {
 
 type ActorPhotoConfig struct {
 	DownloadForKodi bool `yaml:"download_for_kodi"`
+	PersonNFO       bool `yaml:"person_nfo"` // 在 .actors/<演员名>/ 下生成 person.nfo，供Jellyfin等显示演员页面
 }
 
 // STRMConfig STRM文件生成配置
@@ -177,10 +268,45 @@ type STRMConfig struct {
 
 // ScraperConfig 数据抓取模式配置
 type ScraperConfig struct {
-	Mode              string `yaml:"mode"`                // 抓取模式: legacy(直接抓取) 或 metatube(使用MetaTube API)
-	MetaTubeURL       string `yaml:"metatube_url"`        // MetaTube API服务器地址（仅当mode为metatube时需要）
-	MetaTubeToken     string `yaml:"metatube_token"`      // MetaTube API认证令牌（可选）
-	FallbackToLegacy  bool   `yaml:"fallback_to_legacy"`  // MetaTube失败时是否回退到Legacy模式
+	Mode              string                       `yaml:"mode"`                // 抓取模式: legacy(直接抓取) 或 metatube(使用MetaTube API)
+	MetaTubeURL       string                       `yaml:"metatube_url"`        // MetaTube API服务器地址（仅当mode为metatube时需要）
+	MetaTubeToken     string                       `yaml:"metatube_token"`      // MetaTube API认证令牌（可选）
+	FallbackToLegacy  bool                         `yaml:"fallback_to_legacy"`  // MetaTube失败时是否回退到Legacy模式
+	SourceDelay       string                       `yaml:"source_delay"`        // 每个数据源的最小请求间隔（秒），格式: dmm:2,fanza:2，留空不限制
+	SourceDelayJitter float64                      `yaml:"source_delay_jitter"` // 请求间隔的随机抖动范围（秒）
+	TitlePrefixStrip  string                       `yaml:"title_prefix_strip"`  // 标题开头番号的清理规则: number, always, off（可按数据源设置，如 number,fanza:off）
+	Cookies           map[string]map[string]string `yaml:"cookies"`             // 各数据源请求时附带的Cookie（如年龄验证、地区），与内置默认值合并
+	URLTransforms     map[string][]URLTransform    `yaml:"url_transforms"`      // 各数据源图片和预告片URL的正则替换规则（* 表示所有数据源），列出的数据源替换其内置规则
+	MaxPageSize       int                          `yaml:"max_page_size"`       // 单个页面的最大大小（MB，0=使用默认值10），超过则放弃解析
+	ParseTimeout      int                          `yaml:"parse_timeout"`       // 解析单个HTML页面的超时时间（秒，0=使用默认值20）
+	MovieTimeBudget   int                          `yaml:"movie_time_budget"`   // 一部影片在所有数据源上抓取（含重试和回退）的总时间上限（秒，0=使用默认值60）
+	MovieRetryBudget  int                          `yaml:"movie_retry_budget"`  // 一部影片在所有数据源上共用的请求重试次数（0=不限制，各数据源按 proxy.retry 独立重试）
+
+	TitleCrossCheck        bool     `yaml:"title_cross_check"`        // 抓取成功后再查询下一个数据源对比标题，差异过大时警告并写入报告
+	TitleMismatchThreshold float64  `yaml:"title_mismatch_threshold"` // 标题相似度低于该值视为不一致（0-1，0=使用默认值0.5）
+	CoverSources           []string `yaml:"cover_sources"`            // 封面按顺序从这些数据源获取，与元数据来源无关（留空则使用元数据来源的封面）
+	EditionPreference      string   `yaml:"edition_preference"`       // 同一番号有多个版本时优先的版本：dvd、digital、rental（留空则按默认顺序）
+	DMMContentSelectors    []string `yaml:"dmm_content_selectors"`    // 额外的CSS选择器，页面中存在匹配内容时视为有效的DMM商品页（内置 og:title、商品标题等）
+	DMMSampleSize          string   `yaml:"dmm_sample_size"`          // DMM剧照尺寸：large(默认，由内置的 url_transforms 规则将 xxx-1.jpg 转为 xxxjp-1.jpg) 或 small(使用页面上的缩略图)
+	BreakerFailures        int      `yaml:"breaker_failures"`         // 数据源连续不可用（网络、超时、5xx、429、屏蔽，其他失败不计）达到该次数后熔断，恢复窗口内直接跳过（0=不熔断）
+	BreakerReset           int      `yaml:"breaker_reset"`            // 熔断后跳过数据源的时长（秒，0=使用默认值300），之后放行一次请求试探是否恢复
+	SearchAllConcurrency   int      `yaml:"search_all_concurrency"`   // -search-all 和 -search -all 同时查询的数据源数量（0=所有数据源同时查询）
+}
+
+// URLTransform 图片URL的正则替换规则
+type URLTransform struct {
+	Pattern string `yaml:"pattern"` // 正则表达式
+	Replace string `yaml:"replace"` // 替换内容，支持 $1 等分组引用
+}
+
+type ContentConfig struct {
+	SkipTags   []string `yaml:"skip_tags"`   // 带有这些类别/标签的影片整体跳过，不整理（按翻译前的原文匹配，不区分大小写）
+	SkipFolder string   `yaml:"skip_folder"` // 被跳过的影片移动到该文件夹（留空则保留在源目录）
+
+	JunkPatterns []string `yaml:"junk_patterns"` // 扫描前清理源目录中匹配这些文件名模式的样片/预告片等垃圾文件（留空则不清理）
+	JunkMaxSize  int      `yaml:"junk_max_size"` // 只清理小于该大小的匹配文件（MB，0=不限制）
+	JunkAction   string   `yaml:"junk_action"`   // 垃圾文件的处理方式：delete（删除）或 move（移动到 junk_folder）
+	JunkFolder   string   `yaml:"junk_folder"`   // junk_action 为 move 时垃圾文件的存放目录
 }
 
 // Load loads configuration from file
@@ -190,6 +316,8 @@ func Load(configPath string) (*Config, error) {
 		configPath,
 		filepath.Join(".", "config.yaml"),
 		filepath.Join(".", "config.yml"),
+		filepath.Join(".", "config.toml"),
+		filepath.Join(".", "config.json"),
 		filepath.Join(os.Getenv("HOME"), "mdc.yaml"),
 		filepath.Join(os.Getenv("HOME"), ".mdc.yaml"),
 		filepath.Join(os.Getenv("HOME"), ".mdc", "config.yaml"),
@@ -214,8 +342,9 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Format is detected by extension: .toml, .json, otherwise YAML
 	config := &Config{}
-	err = yaml.Unmarshal(data, config)
+	err = decodeConfig(data, DetectFormat(actualPath), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
@@ -249,6 +378,42 @@ func createDefaultConfig(path string) (*Config, error) {
 			MultiThreading:            0,
 			StopCounter:               0,
 			RerunDelay:                "0",
+			MaxDuration:               "0",
+			FailureCooldownAfter:      0,
+			FailureCooldown:           "10m",
+			MaxInflightRequests:       0,
+			MaxConcurrentDownloads:    0,
+			ScrapeRetries:             2,
+			ScrapeRetryDelay:          0.5,
+			ReportFile:                "",
+			LowConfidenceThreshold:    0.6,
+			ScanMaxDepth:              32,
+			DownloadTempSuffix:        ".part",
+			ImageCacheDir:             "",
+			ImageCacheMaxSize:         1024,
+			HTMLIndexDir:              "",
+			FilenameEncoding:          "",
+			MaxDNSLookups:             4,
+			DNSCacheTTL:               0,
+			ProcessedMarker:           false,
+			ProcessedManifest:         "",
+			RecoveryFile:              "",
+			StatsAddr:                 "",
+			FileLock:                  true,
+			LibraryIndex:              "",
+			AllowedPrefixes:           []string{},
+			NFOParseWorkers:           0,
+			VerifyWorkers:             0,
+			AdaptiveSleep:             false,
+			AdaptiveSleepMax:          10,
+			SkipInUse:                 true,
+			InUseQuietPeriod:          60,
+			DryRun:                    false,
+			SkipExisting:              false,
+			FailureReasons:            false,
+			MetadataCacheDir:          "",
+			MetadataCacheDays:         0,
+			StrictValidation:          false,
 		},
 		Proxy: ProxyConfig{
 			Switch:  false,
@@ -262,7 +427,24 @@ func createDefaultConfig(path string) (*Config, error) {
 			NamingRule:            "number + '-' + title",
 			MaxTitleLen:           50,
 			ImageNamingWithNumber: false,
+			ImageNumberPrefix:     []string{},
 			NumberUppercase:       false,
+			NFODialect:            "kodi",
+			NfoFlavor:             "",
+			ActorAliasFile:        "",
+			MaxNFOActors:          0,
+			StudioAliasFile:       "",
+			YearSource:            "scraped",
+			NFOExtraFields:        []string{},
+			CensoredRoot:          "",
+			UncensoredRoot:        "",
+			SeriesIndex:           false,
+			ValidateNFO:           false,
+			WindowsSafePaths:      false,
+			OutlineFallback:       "empty",
+			SortTitleTemplate:     "",
+			BackupNFO:             "off",
+			BackupNFOKeep:         5,
 		},
 		Update: UpdateConfig{
 			UpdateCheck: true,
@@ -275,7 +457,8 @@ func createDefaultConfig(path string) (*Config, error) {
 			Folders:  "failed, JAV_output",
 		},
 		DebugMode: DebugModeConfig{
-			Switch: false,
+			Switch:    false,
+			HTTPTrace: false,
 		},
 		Translate: TranslateConfig{
 			Switch:      false,
@@ -283,26 +466,40 @@ func createDefaultConfig(path string) (*Config, error) {
 			TargetLang:  "zh_cn",
 			Delay:       1,
 			Values:      "title,outline",
-			ServiceSite: "translate.google.cn",
+			ServiceSite: "translate.googleapis.com",
 		},
 		Trailer: TrailerConfig{
-			Switch: false,
+			Switch:  false,
+			Quality: "highest",
 		},
 		Uncensored: UncensoredConfig{
 			UncensoredPrefix: "S2M,BT,LAF,SMD",
 		},
 		Media: MediaConfig{
-			MediaType: ".mp4,.avi,.rmvb,.wmv,.mov,.mkv,.flv,.ts,.webm,.iso",
-			SubType:   ".smi,.srt,.idx,.sub,.sup,.psb,.ssa,.ass,.usf,.xss,.ssf,.rt,.lrc,.sbv,.vtt,.ttml",
+			MediaType:             ".mp4,.avi,.rmvb,.wmv,.mov,.mkv,.flv,.ts,.webm,.iso",
+			SubType:               ".smi,.srt,.idx,.sub,.sup,.psb,.ssa,.ass,.usf,.xss,.ssf,.rt,.lrc,.sbv,.vtt,.ttml",
+			SubtitleGrouping:      true,
+			ConvertAssToSrt:       false,
+			TagResolution:         0,
+			FFprobePath:           "",
+			MuxSubtitles:          false,
+			MuxChapters:           false,
+			FFmpegPath:            "",
+			ChineseSubtitleDetect: 1,
+			VideoExtensions:       []string{},
+			ExtensionRules:        map[string]ExtensionRule{},
 		},
 		Watermark: WatermarkConfig{
-			Switch: true,
-			Water:  2,
+			Switch:     true,
+			Water:      2,
+			ApplyTo:    "both",
+			SkipMarked: false,
 		},
 		Extrafanart: ExtrafanartConfig{
 			Switch:            true,
 			ExtrafanartFolder: "extrafanart",
-			ParallelDownload:  1,
+			ParallelDownload:  4,
+			Dedup:             true,
 		},
 		Storyline: StorylineConfig{
 			Switch:         true,
@@ -324,12 +521,21 @@ func createDefaultConfig(path string) (*Config, error) {
 			UncensoredOnly: true,
 			AlwaysImagecut: false,
 			AspectRatio:    2.12,
+			CutRetries:     2,
+			MaxWorkers:     0,
+			SmallCoverPoster: []string{},
+			SameSmallCover:   "crop",
+		},
+		Image: ImageConfig{
+			PosterSource: "",
+			FanartSource: "",
 		},
 		Jellyfin: JellyfinConfig{
 			MultiPartFanart: false,
 		},
 		ActorPhoto: ActorPhotoConfig{
 			DownloadForKodi: false,
+			PersonNFO:       false,
 		},
 		STRM: STRMConfig{
 			Enable:           false,
@@ -343,10 +549,37 @@ func createDefaultConfig(path string) (*Config, error) {
 			OutputSuffix:     "",
 		},
 		Scraper: ScraperConfig{
-			Mode:             "legacy",
-			MetaTubeURL:      "http://localhost:8080",
-			MetaTubeToken:    "",
-			FallbackToLegacy: true,
+			Mode:              "legacy",
+			MetaTubeURL:       "http://localhost:8080",
+			MetaTubeToken:     "",
+			FallbackToLegacy:  true,
+			SourceDelay:       "",
+			SourceDelayJitter: DefaultSourceDelayJitter,
+			TitlePrefixStrip:  "number",
+			Cookies:           DefaultSourceCookies,
+
+			MaxPageSize:       10,
+			ParseTimeout:      20,
+			MovieTimeBudget:   60,
+			MovieRetryBudget:  0,
+			TitleCrossCheck:        false,
+			TitleMismatchThreshold: 0.5,
+			CoverSources:           []string{},
+			EditionPreference:      "",
+			DMMContentSelectors:    []string{},
+			DMMSampleSize:          "large",
+			BreakerFailures:        5,
+			BreakerReset:           300,
+			SearchAllConcurrency:   0,
+		},
+		Content: ContentConfig{
+			SkipTags:   []string{},
+			SkipFolder: "",
+
+			JunkPatterns: []string{},
+			JunkMaxSize:  200,
+			JunkAction:   "move",
+			JunkFolder:   "junk",
 		},
 	}
 
@@ -369,13 +602,317 @@ func (c *Config) GetSources() []string {
 	return strings.Split(c.Priority.Website, ",")
 }
 
+// DefaultSourceDelayJitter is used when neither scraper.source_delay nor its jitter is configured.
+const DefaultSourceDelayJitter = 0.5
+
+// DefaultSourceCookies are the cookies gated sources need to serve their pages.
+// Entries in scraper.cookies are merged on top of them.
+var DefaultSourceCookies = map[string]map[string]string{
+	"dmm": {
+		"age_check_done": "1",
+		"ckcy":           "1",
+		"cklg":           "ja",
+	},
+	"javbus": {
+		"existmag": "all",
+		"over18":   "18",
+	},
+	"javdb": {
+		"over18": "1",
+		"locale": "zh",
+		"theme":  "auto",
+	},
+	"javlibrary": {
+		"over18": "18",
+	},
+	"fc2": {
+		"adult":    "1",
+		"fc2_lang": "ja",
+	},
+	"mgstage": {
+		"adc": "1",
+	},
+}
+
+// GetWatermarkTargets reports whether watermarks go on the poster and/or the thumb
+func (c *Config) GetWatermarkTargets() (poster, thumb bool) {
+	switch strings.ToLower(strings.TrimSpace(c.Watermark.ApplyTo)) {
+	case "poster":
+		return true, false
+	case "thumb":
+		return false, true
+	default:
+		return true, true
+	}
+}
+
+// DMMLargeSampleTransform rewrites a DMM sample thumbnail (ssis00001-1.jpg) to the
+// large image (ssis00001jp-1.jpg). Only the file name is touched, and names that
+// already end in jp are left alone. Dropped when dmm_sample_size is small.
+var DMMLargeSampleTransform = URLTransform{
+	Pattern: `^(.*/[^/?#]*(?:[^/?#p]|[^/?#j]p))-(\d+\.(?:jpe?g|png|webp))$`,
+	Replace: "${1}jp-${2}",
+}
+
+// DefaultURLTransforms are the built-in url_transforms rules. A source listed in
+// scraper.url_transforms replaces its built-in rules; an empty list disables them.
+var DefaultURLTransforms = map[string][]URLTransform{
+	"dmm": {
+		{Pattern: `^//`, Replace: "https://"},
+		{Pattern: `^/([^/])`, Replace: "https://www.dmm.co.jp/$1"},
+		DMMLargeSampleTransform,
+	},
+}
+
+// GetURLTransforms returns the url_transforms rules per lower-cased source,
+// built-in rules first filled in for sources the config does not list.
+func (c *Config) GetURLTransforms() map[string][]URLTransform {
+	rules := make(map[string][]URLTransform)
+	for source, list := range c.Scraper.URLTransforms {
+		key := strings.ToLower(strings.TrimSpace(source))
+		rules[key] = append(rules[key], list...)
+	}
+	smallSamples := strings.EqualFold(c.Scraper.DMMSampleSize, "small")
+	for source, list := range DefaultURLTransforms {
+		if _, configured := rules[source]; configured {
+			continue
+		}
+		rules[source] = []URLTransform{}
+		for _, rule := range list {
+			if smallSamples && rule == DMMLargeSampleTransform {
+				continue
+			}
+			rules[source] = append(rules[source], rule)
+		}
+	}
+	return rules
+}
+
+// GetSourceCookies returns the cookies to send with requests to the given source
+func (c *Config) GetSourceCookies(source string) map[string]string {
+	source = strings.ToLower(strings.TrimSpace(source))
+
+	cookies := make(map[string]string)
+	for name, value := range DefaultSourceCookies[source] {
+		cookies[name] = value
+	}
+	for configured, values := range c.Scraper.Cookies {
+		if strings.ToLower(strings.TrimSpace(configured)) != source {
+			continue
+		}
+		for name, value := range values {
+			cookies[name] = value
+		}
+	}
+	return cookies
+}
+
+// GetSourceProxy returns the proxy override configured for source in proxy.per_source,
+// or nil when the source uses the global proxy. Type defaults to the global type.
+func (c *Config) GetSourceProxy(source string) *ProxyConfig {
+	source = strings.ToLower(strings.TrimSpace(source))
+	for configured, override := range c.Proxy.PerSource {
+		if strings.ToLower(strings.TrimSpace(configured)) != source {
+			continue
+		}
+		if override.Type == "" {
+			override.Type = c.Proxy.Type
+		}
+		override.PerSource = nil
+		return &override
+	}
+	return nil
+}
+
+// GetImageProxy returns the proxy used for image and trailer downloads: proxy.image_proxy
+// when set, otherwise the global proxy. Unset type, timeout and retry fall back to the global values.
+func (c *Config) GetImageProxy() *ProxyConfig {
+	if c.Proxy.ImageProxy == nil {
+		return &c.Proxy
+	}
+	image := *c.Proxy.ImageProxy
+	if image.Type == "" {
+		image.Type = c.Proxy.Type
+	}
+	if image.Timeout == 0 {
+		image.Timeout = c.Proxy.Timeout
+	}
+	if image.Retry == 0 {
+		image.Retry = c.Proxy.Retry
+	}
+	image.PerSource, image.ImageProxy = nil, nil
+	return &image
+}
+
+// GetSourceDelays returns the per-source minimum request interval in seconds.
+// Sources without a configured delay are not throttled.
+func (c *Config) GetSourceDelays() map[string]float64 {
+	delays := make(map[string]float64)
+	if strings.TrimSpace(c.Scraper.SourceDelay) == "" {
+		return delays
+	}
+
+	for _, item := range strings.Split(c.Scraper.SourceDelay, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || seconds <= 0 {
+			continue
+		}
+		delays[strings.ToLower(strings.TrimSpace(parts[0]))] = seconds
+	}
+	return delays
+}
+
+// GetSourceDelayJitter returns the random jitter applied to per-source delays in seconds
+func (c *Config) GetSourceDelayJitter() float64 {
+	if strings.TrimSpace(c.Scraper.SourceDelay) == "" && c.Scraper.SourceDelayJitter == 0 {
+		return DefaultSourceDelayJitter
+	}
+	if c.Scraper.SourceDelayJitter < 0 {
+		return 0
+	}
+	return c.Scraper.SourceDelayJitter
+}
+
+// GetTitlePrefixStrip returns the leading-number title cleanup rule for a source.
+// Entries without a source prefix set the default; the default is "number".
+func (c *Config) GetTitlePrefixStrip(source string) string {
+	mode := "number"
+	for _, item := range strings.Split(c.Scraper.TitlePrefixStrip, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if parts := strings.SplitN(item, ":", 2); len(parts) == 2 {
+			if strings.TrimSpace(parts[0]) == strings.ToLower(source) {
+				return strings.TrimSpace(parts[1])
+			}
+			continue
+		}
+		mode = item
+	}
+	return mode
+}
+
+// GetNFODialect returns the normalized NFO dialect (kodi, emby or both).
+// An explicit NameRule.NfoFlavor overrides NameRule.NFODialect: emby selects the
+// emby dialect, kodi and jellyfin select kodi (no Emby-only fields).
+func (c *Config) GetNFODialect() string {
+	switch strings.ToLower(strings.TrimSpace(c.NameRule.NfoFlavor)) {
+	case "kodi", "jellyfin":
+		return "kodi"
+	case "emby":
+		return "emby"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.NameRule.NFODialect)) {
+	case "emby":
+		return "emby"
+	case "both":
+		return "both"
+	default:
+		return "kodi"
+	}
+}
+
+// GetNfoFlavor returns the media server the NFO is written for (kodi, jellyfin or emby).
+// Without NameRule.NfoFlavor it follows Common.Jellyfin and NameRule.NFODialect.
+func (c *Config) GetNfoFlavor() string {
+	switch strings.ToLower(strings.TrimSpace(c.NameRule.NfoFlavor)) {
+	case "kodi":
+		return "kodi"
+	case "jellyfin":
+		return "jellyfin"
+	case "emby":
+		return "emby"
+	}
+	if c.Common.Jellyfin > 0 {
+		return "jellyfin"
+	}
+	if c.GetNFODialect() == "emby" {
+		return "emby"
+	}
+	return "kodi"
+}
+
+// GetPosterSource returns the configured poster source (cut, cover or cover_small),
+// or "" to keep the default of cutting the cover unless the small cover is preferred
+func (c *Config) GetPosterSource() string {
+	switch source := strings.ToLower(strings.TrimSpace(c.Image.PosterSource)); source {
+	case "cut", "cover", "cover_small":
+		return source
+	default:
+		return ""
+	}
+}
+
+// GetFanartSource returns where fanart comes from: "cover" or "none".
+// Without Image.FanartSource, fanart is written unless Jellyfin mode is on.
+func (c *Config) GetFanartSource() string {
+	switch source := strings.ToLower(strings.TrimSpace(c.Image.FanartSource)); source {
+	case "cover", "none":
+		return source
+	}
+	if c.Common.Jellyfin > 0 {
+		return "none"
+	}
+	return "cover"
+}
+
+// ParseSortTitleTemplate parses NameRule.SortTitleTemplate, returning nil when it is not set.
+// Besides the MovieData fields the template can use pad, e.g. {{pad .SeriesIndex 3}} gives 003.
+func (c *Config) ParseSortTitleTemplate() (*template.Template, error) {
+	if strings.TrimSpace(c.NameRule.SortTitleTemplate) == "" {
+		return nil, nil
+	}
+	funcs := template.FuncMap{
+		"pad": func(n, width int) string {
+			return fmt.Sprintf("%0*d", width, n)
+		},
+	}
+	return template.New("sorttitle").Funcs(funcs).Option("missingkey=zero").Parse(c.NameRule.SortTitleTemplate)
+}
+
 // GetMediaTypes returns list of supported media file extensions
+// (media_type plus video_extensions, lowercase and without duplicates)
 func (c *Config) GetMediaTypes() []string {
-	types := strings.Split(strings.ToLower(c.Media.MediaType), ",")
-	for i, t := range types {
-		types[i] = strings.TrimSpace(t)
+	types := strings.Split(c.Media.MediaType, ",")
+	types = append(types, c.Media.VideoExtensions...)
+
+	result := make([]string, 0, len(types))
+	seen := make(map[string]bool)
+	for _, t := range types {
+		t = normalizeExtension(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		result = append(result, t)
 	}
-	return types
+	return result
+}
+
+// GetExtensionRule returns the scan rule configured for a video extension (zero rule if none)
+func (c *Config) GetExtensionRule(ext string) ExtensionRule {
+	ext = normalizeExtension(ext)
+	for key, rule := range c.Media.ExtensionRules {
+		if normalizeExtension(key) == ext {
+			return rule
+		}
+	}
+	return ExtensionRule{}
+}
+
+// normalizeExtension lowercases an extension and makes sure it starts with a dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
 }
 
 // GetSubTypes returns list of supported subtitle file extensions  
@@ -389,7 +926,21 @@ func (c *Config) GetSubTypes() []string {
 
 // ParseRerunDelay parses rerun delay string to seconds
 func (c *Config) ParseRerunDelay() int {
-	value := c.Common.RerunDelay
+	return parseSeconds(c.Common.RerunDelay)
+}
+
+// ParseMaxDuration parses the processing time budget to seconds (0 = unlimited)
+func (c *Config) ParseMaxDuration() int {
+	return parseSeconds(c.Common.MaxDuration)
+}
+
+// ParseFailureCooldown parses the pause after consecutive scrape failures to seconds
+func (c *Config) ParseFailureCooldown() int {
+	return parseSeconds(c.Common.FailureCooldown)
+}
+
+// parseSeconds parses a duration like "90", "30m" or "1h30m45s" to seconds
+func parseSeconds(value string) int {
 	if value == "" || value == "0" {
 		return 0
 	}
@@ -427,4 +978,53 @@ func (c *Config) ParseRerunDelay() int {
 	}
 
 	return total
-}
\ No newline at end of file
+}
+
+// UseSmallCoverPoster 判断该数据源的小封面是否直接作为海报
+func (c *Config) UseSmallCoverPoster(source string) bool {
+	for _, name := range c.Face.SmallCoverPoster {
+		name = strings.TrimSpace(name)
+		if name == "*" || (name != "" && strings.EqualFold(name, source)) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutputRoot 返回影片的输出根目录：配置了有码/无码根目录时按检测结果分开存放
+func (c *Config) OutputRoot(uncensored bool) string {
+	if uncensored && c.NameRule.UncensoredRoot != "" {
+		return c.NameRule.UncensoredRoot
+	}
+	if !uncensored && c.NameRule.CensoredRoot != "" {
+		return c.NameRule.CensoredRoot
+	}
+	return c.Common.SuccessOutputFolder
+}
+
+// OutputRoots 返回所有输出根目录（去重），用于清理空目录和检查整理后的影片库
+func (c *Config) OutputRoots() []string {
+	var roots []string
+	seen := make(map[string]bool)
+	for _, root := range []string{c.Common.SuccessOutputFolder, c.NameRule.CensoredRoot, c.NameRule.UncensoredRoot} {
+		if root == "" || seen[filepath.Clean(root)] {
+			continue
+		}
+		seen[filepath.Clean(root)] = true
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// ImageNamedWithNumber 判断该类型的图片（poster, fanart, thumb）是否使用番号前缀命名
+func (c *Config) ImageNamedWithNumber(kind string) bool {
+	if len(c.NameRule.ImageNumberPrefix) == 0 {
+		return c.NameRule.ImageNamingWithNumber
+	}
+	for _, name := range c.NameRule.ImageNumberPrefix {
+		if strings.EqualFold(strings.TrimSpace(name), kind) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported configuration file formats
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+	FormatJSON = "json"
+)
+
+// DetectFormat returns the configuration format implied by the file extension.
+// Unknown extensions are treated as YAML.
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatYAML
+	}
+}
+
+// decodeConfig parses data in the given format into config.
+// TOML and JSON documents use the same keys as the YAML file: they are decoded
+// into a generic map first and then fed through the YAML decoder so that the
+// struct only needs a single set of tags.
+func decodeConfig(data []byte, format string, config *Config) error {
+	if format != FormatTOML && format != FormatJSON {
+		return yaml.Unmarshal(data, config)
+	}
+
+	raw, err := decodeRaw(data, format)
+	if err != nil {
+		return err
+	}
+	converted, err := yaml.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s config: %w", format, err)
+	}
+	return yaml.Unmarshal(converted, config)
+}
+
+// encodeConfig serializes config in the given format using the YAML key names
+func encodeConfig(config *Config, format string) ([]byte, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatYAML {
+		return data, nil
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(raw); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatJSON:
+		return json.MarshalIndent(raw, "", "  ")
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_FormatByExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "config.yaml",
+			content: `
+common:
+  main_mode: 2
+  success_output_folder: "output"
+proxy:
+  timeout: 7
+face:
+  aspect_ratio: 2.12
+`,
+		},
+		{
+			name: "config.toml",
+			content: `
+[common]
+main_mode = 2
+success_output_folder = "output"
+
+[proxy]
+timeout = 7
+
+[face]
+aspect_ratio = 2.12
+`,
+		},
+		{
+			name: "config.json",
+			content: `{
+  "common": {"main_mode": 2, "success_output_folder": "output"},
+  "proxy": {"timeout": 7},
+  "face": {"aspect_ratio": 2.12}
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config file: %v", err)
+			}
+
+			config, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+
+			if config.Common.MainMode != 2 {
+				t.Errorf("Expected main_mode 2, got %d", config.Common.MainMode)
+			}
+			if config.Common.SuccessOutputFolder != "output" {
+				t.Errorf("Expected success_output_folder output, got %s", config.Common.SuccessOutputFolder)
+			}
+			if config.Proxy.Timeout != 7 {
+				t.Errorf("Expected proxy timeout 7, got %d", config.Proxy.Timeout)
+			}
+			if config.Face.AspectRatio != 2.12 {
+				t.Errorf("Expected aspect_ratio 2.12, got %f", config.Face.AspectRatio)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidTOML(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(configPath, []byte("[common\nmain_mode = "), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Expected error for invalid TOML")
+	}
+}
+
+func TestLoadFiles_DeepMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overrides := filepath.Join(dir, "overrides.toml")
+	if err := os.WriteFile(base, []byte(`
+common:
+  main_mode: 2
+  success_output_folder: "output"
+proxy:
+  timeout: 7
+  switch: true
+`), 0644); err != nil {
+		t.Fatalf("Failed to create base config: %v", err)
+	}
+	if err := os.WriteFile(overrides, []byte(`
+[common]
+success_output_folder = "/mnt/library"
+
+[proxy]
+timeout = 20
+`), 0644); err != nil {
+		t.Fatalf("Failed to create overrides config: %v", err)
+	}
+
+	config, err := LoadFiles([]string{base, overrides})
+	if err != nil {
+		t.Fatalf("Failed to load configs: %v", err)
+	}
+
+	if config.Common.MainMode != 2 {
+		t.Errorf("Expected main_mode 2 from base, got %d", config.Common.MainMode)
+	}
+	if config.Common.SuccessOutputFolder != "/mnt/library" {
+		t.Errorf("Expected overridden success_output_folder, got %s", config.Common.SuccessOutputFolder)
+	}
+	if config.Proxy.Timeout != 20 || !config.Proxy.Switch {
+		t.Errorf("Expected proxy timeout 20 with switch kept, got %d/%v", config.Proxy.Timeout, config.Proxy.Switch)
+	}
+
+	if _, err := LoadFiles([]string{base, filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Error("Expected error for missing override file")
+	}
+}
@@ -295,7 +295,7 @@ func (cm *ConfigManager) validateConfig() error {
 
 // saveConfig saves the current configuration to file
 func (cm *ConfigManager) saveConfig() error {
-	data, err := yaml.Marshal(cm.config)
+	data, err := encodeConfig(cm.config, DetectFormat(cm.configPath))
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
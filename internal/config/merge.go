@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFiles loads several configuration files and deep-merges them in order:
+// sections and keys of later files override the same keys of earlier ones, while
+// keys they leave out keep the earlier value. Lists are replaced as a whole.
+// A single path behaves exactly like Load, including the default search locations.
+func LoadFiles(paths []string) (*Config, error) {
+	if len(paths) == 0 {
+		return Load("config.yaml")
+	}
+	if len(paths) == 1 {
+		return Load(paths[0])
+	}
+
+	merged := make(map[string]interface{})
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		raw, err := decodeRaw(data, DetectFormat(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		mergeMaps(merged, raw)
+	}
+
+	converted, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(converted, config); err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	return config, nil
+}
+
+// decodeRaw parses data in the given format into a generic map keyed like the YAML file
+func decodeRaw(data []byte, format string) (map[string]interface{}, error) {
+	var raw map[string]interface{}
+	switch format {
+	case FormatTOML:
+		if _, err := toml.Decode(string(data), &raw); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+	case FormatJSON:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	default:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	return raw, nil
+}
+
+// mergeMaps deep-merges src into dst. Nested maps are merged key by key,
+// any other value in src replaces the one in dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -44,14 +44,74 @@ func (v *BasicConfigValidator) Validate(config *Config) error {
 		return fmt.Errorf("media config validation failed: %w", err)
 	}
 
+	validPosterSources := []string{"cut", "cover", "cover_small", ""}
+	if !v.contains(validPosterSources, strings.ToLower(config.Image.PosterSource)) {
+		return fmt.Errorf("invalid image poster_source: %s, must be one of: %v", config.Image.PosterSource, validPosterSources)
+	}
+	validFanartSources := []string{"cover", "none", ""}
+	if !v.contains(validFanartSources, strings.ToLower(config.Image.FanartSource)) {
+		return fmt.Errorf("invalid image fanart_source: %s, must be one of: %v", config.Image.FanartSource, validFanartSources)
+	}
+
+	if applyTo := strings.ToLower(config.Watermark.ApplyTo); applyTo != "" {
+		validTargets := []string{"poster", "thumb", "both"}
+		if !v.contains(validTargets, applyTo) {
+			return fmt.Errorf("invalid watermark apply_to: %s, must be one of: %v", config.Watermark.ApplyTo, validTargets)
+		}
+	}
+
+	if action := strings.ToLower(config.Content.JunkAction); action != "" {
+		validActions := []string{"delete", "move"}
+		if !v.contains(validActions, action) {
+			return fmt.Errorf("invalid content junk_action: %s, must be one of: %v", config.Content.JunkAction, validActions)
+		}
+		if action == "move" && len(config.Content.JunkPatterns) > 0 && config.Content.JunkFolder == "" {
+			return fmt.Errorf("content junk_folder is required when junk_action is move")
+		}
+	}
+
+	if edition := strings.ToLower(config.Scraper.EditionPreference); edition != "" {
+		validEditions := []string{"dvd", "digital", "rental"}
+		if !v.contains(validEditions, edition) {
+			return fmt.Errorf("invalid scraper edition_preference: %s, must be one of: %v", config.Scraper.EditionPreference, validEditions)
+		}
+	}
+
+	if size := strings.ToLower(config.Scraper.DMMSampleSize); size != "" {
+		validSizes := []string{"large", "small"}
+		if !v.contains(validSizes, size) {
+			return fmt.Errorf("invalid scraper dmm_sample_size: %s, must be one of: %v", config.Scraper.DMMSampleSize, validSizes)
+		}
+	}
+
+	for source, rules := range config.Scraper.URLTransforms {
+		for _, rule := range rules {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("invalid scraper url_transforms pattern for %s '%s': %w", source, rule.Pattern, err)
+			}
+		}
+	}
+
+	if config.Scraper.BreakerFailures < 0 {
+		return fmt.Errorf("invalid scraper breaker_failures: %d, must be non-negative", config.Scraper.BreakerFailures)
+	}
+
+	if config.Scraper.BreakerReset < 0 {
+		return fmt.Errorf("invalid scraper breaker_reset: %d, must be non-negative", config.Scraper.BreakerReset)
+	}
+
+	if config.Scraper.SearchAllConcurrency < 0 {
+		return fmt.Errorf("invalid scraper search_all_concurrency: %d, must be non-negative", config.Scraper.SearchAllConcurrency)
+	}
+
 	return nil
 }
 
 // validateCommon validates common configuration
 func (v *BasicConfigValidator) validateCommon(config *CommonConfig) error {
 	// Validate main mode
-	if config.MainMode < 0 || config.MainMode > 3 {
-		return fmt.Errorf("invalid main_mode: %d, must be 0-3", config.MainMode)
+	if config.MainMode < 0 || config.MainMode > 4 {
+		return fmt.Errorf("invalid main_mode: %d, must be 0-4", config.MainMode)
 	}
 
 	// Validate link mode
@@ -59,6 +119,15 @@ func (v *BasicConfigValidator) validateCommon(config *CommonConfig) error {
 		return fmt.Errorf("invalid link_mode: %d, must be 0-2", config.LinkMode)
 	}
 
+	if config.ImageCacheMaxSize < 0 {
+		return fmt.Errorf("invalid image_cache_max_size: %d, must be non-negative", config.ImageCacheMaxSize)
+	}
+
+	validEncodings := []string{"", "shift_jis", "euc-jp", "gbk", "big5", "latin1"}
+	if !v.contains(validEncodings, strings.ToLower(config.FilenameEncoding)) {
+		return fmt.Errorf("invalid filename_encoding: %s, must be one of: %v", config.FilenameEncoding, validEncodings)
+	}
+
 	// Validate source folder exists
 	if config.SourceFolder != "" {
 		if _, err := os.Stat(config.SourceFolder); os.IsNotExist(err) {
@@ -75,6 +144,22 @@ func (v *BasicConfigValidator) validateCommon(config *CommonConfig) error {
 		return fmt.Errorf("multi_threading must be non-negative, got: %d", config.MultiThreading)
 	}
 
+	if config.MaxInflightRequests < 0 {
+		return fmt.Errorf("max_inflight_requests must be non-negative, got: %d", config.MaxInflightRequests)
+	}
+
+	if config.MaxConcurrentDownloads < 0 {
+		return fmt.Errorf("max_concurrent_downloads must be non-negative, got: %d", config.MaxConcurrentDownloads)
+	}
+
+	if config.ScrapeRetries < 0 {
+		return fmt.Errorf("scrape_retries must be non-negative, got: %d", config.ScrapeRetries)
+	}
+
+	if config.ScrapeRetryDelay < 0 {
+		return fmt.Errorf("scrape_retry_delay must be non-negative, got: %f", config.ScrapeRetryDelay)
+	}
+
 	if config.NFOSkipDays < 0 {
 		return fmt.Errorf("nfo_skip_days must be non-negative, got: %d", config.NFOSkipDays)
 	}
@@ -96,11 +181,57 @@ func (v *BasicConfigValidator) validateCommon(config *CommonConfig) error {
 		}
 	}
 
+	// Validate max duration format
+	if config.MaxDuration != "" && config.MaxDuration != "0" {
+		if err := v.validateTimeFormat(config.MaxDuration); err != nil {
+			return fmt.Errorf("invalid max_duration format: %w", err)
+		}
+	}
+
+	if config.FailureCooldownAfter < 0 {
+		return fmt.Errorf("failure_cooldown_after must be non-negative, got: %d", config.FailureCooldownAfter)
+	}
+
+	// Validate failure cooldown format
+	if config.FailureCooldown != "" && config.FailureCooldown != "0" {
+		if err := v.validateTimeFormat(config.FailureCooldown); err != nil {
+			return fmt.Errorf("invalid failure_cooldown format: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // validateProxy validates proxy configuration
 func (v *BasicConfigValidator) validateProxy(config *ProxyConfig) error {
+	// Validate per-source overrides, which apply even when the global proxy is off
+	for source, override := range config.PerSource {
+		if !override.Switch {
+			continue
+		}
+		if override.Proxy == "" {
+			return fmt.Errorf("proxy for source %s is enabled but has no address", source)
+		}
+		if _, err := url.Parse(override.Proxy); err != nil {
+			return fmt.Errorf("invalid proxy URL for source %s: %s, error: %w", source, override.Proxy, err)
+		}
+		if override.Type != "" && !v.contains([]string{"http", "https", "socks5", "socks5h", "socks4"}, override.Type) {
+			return fmt.Errorf("invalid proxy type for source %s: %s", source, override.Type)
+		}
+	}
+
+	if image := config.ImageProxy; image != nil && image.Switch {
+		if image.Proxy == "" {
+			return fmt.Errorf("image proxy is enabled but has no address")
+		}
+		if _, err := url.Parse(image.Proxy); err != nil {
+			return fmt.Errorf("invalid image proxy URL: %s, error: %w", image.Proxy, err)
+		}
+		if image.Type != "" && !v.contains([]string{"http", "https", "socks5", "socks5h", "socks4"}, image.Type) {
+			return fmt.Errorf("invalid image proxy type: %s", image.Type)
+		}
+	}
+
 	if !config.Switch {
 		return nil // Skip validation if proxy is disabled
 	}
@@ -159,6 +290,61 @@ func (v *BasicConfigValidator) validateNameRule(config *NameRuleConfig) error {
 		}
 	}
 
+	// Validate NFO dialect
+	if config.NFODialect != "" {
+		validDialects := []string{"kodi", "emby", "both"}
+		if !v.contains(validDialects, strings.ToLower(config.NFODialect)) {
+			return fmt.Errorf("invalid nfo_dialect: %s, must be one of: %v", config.NFODialect, validDialects)
+		}
+	}
+
+	// Validate NFO flavor
+	if config.NfoFlavor != "" {
+		validFlavors := []string{"kodi", "jellyfin", "emby"}
+		if !v.contains(validFlavors, strings.ToLower(config.NfoFlavor)) {
+			return fmt.Errorf("invalid nfo_flavor: %s, must be one of: %v", config.NfoFlavor, validFlavors)
+		}
+	}
+
+	// Validate year source
+	if config.YearSource != "" {
+		validSources := []string{"scraped", "filename"}
+		if !v.contains(validSources, strings.ToLower(config.YearSource)) {
+			return fmt.Errorf("invalid year_source: %s, must be one of: %v", config.YearSource, validSources)
+		}
+	}
+
+	// Validate outline fallback
+	if config.OutlineFallback != "" {
+		validFallbacks := []string{"empty", "generate"}
+		if !v.contains(validFallbacks, strings.ToLower(config.OutlineFallback)) {
+			return fmt.Errorf("invalid outline_fallback: %s, must be one of: %v", config.OutlineFallback, validFallbacks)
+		}
+	}
+
+	// Validate NFO backup
+	if config.BackupNFO != "" {
+		validModes := []string{"off", "bak", "timestamp"}
+		if !v.contains(validModes, strings.ToLower(config.BackupNFO)) {
+			return fmt.Errorf("invalid backup_nfo: %s, must be one of: %v", config.BackupNFO, validModes)
+		}
+	}
+	if config.BackupNFOKeep < 0 {
+		return fmt.Errorf("backup_nfo_keep cannot be negative, got: %d", config.BackupNFOKeep)
+	}
+
+	// Validate sort title template
+	if config.SortTitleTemplate != "" {
+		if _, err := (&Config{NameRule: *config}).ParseSortTitleTemplate(); err != nil {
+			return fmt.Errorf("invalid sort_title_template: %w", err)
+		}
+	}
+
+	// Validate actor cap
+	if config.MaxNFOActors < 0 {
+		return fmt.Errorf("max_nfo_actors cannot be negative, got: %d", config.MaxNFOActors)
+	}
+
 	// Validate number regex if specified
 	if config.NumberRegexs != "" {
 		regexes := strings.Split(config.NumberRegexs, ",")
@@ -228,6 +414,11 @@ func (v *BasicConfigValidator) validateFace(config *FaceConfig) error {
 		return fmt.Errorf("invalid locations_model: %s, must be one of: %v", config.LocationsModel, validModels)
 	}
 
+	validActions := []string{"crop", "download", ""}
+	if !v.contains(validActions, config.SameSmallCover) {
+		return fmt.Errorf("invalid same_small_cover: %s, must be one of: %v", config.SameSmallCover, validActions)
+	}
+
 	// Validate aspect ratio
 	if config.AspectRatio <= 0 {
 		return fmt.Errorf("aspect_ratio must be positive, got: %f", config.AspectRatio)
@@ -264,6 +455,15 @@ func (v *BasicConfigValidator) validateMedia(config *MediaConfig) error {
 		}
 	}
 
+	// Validate resolution tagging mode
+	if config.TagResolution < 0 || config.TagResolution > 2 {
+		return fmt.Errorf("tag_resolution must be 0, 1 or 2, got: %d", config.TagResolution)
+	}
+
+	if config.ChineseSubtitleDetect < 0 || config.ChineseSubtitleDetect > 2 {
+		return fmt.Errorf("chinese_subtitle_detect must be 0, 1 or 2, got: %d", config.ChineseSubtitleDetect)
+	}
+
 	return nil
 }
 
@@ -288,6 +488,11 @@ func (v *BasicConfigValidator) validateTimeFormat(timeStr string) error {
 	return nil
 }
 
+// ValidateTimeFormat validates a time option given outside the config file, such as --max-duration
+func ValidateTimeFormat(timeStr string) error {
+	return NewBasicConfigValidator().validateTimeFormat(timeStr)
+}
+
 // contains checks if a slice contains a string
 func (v *BasicConfigValidator) contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/logger"
+)
+
+// DefaultFailureCooldown is the pause used when common.failure_cooldown is not set
+const DefaultFailureCooldown = 10 * time.Minute
+
+// failureCooldown pauses scraping for the whole run after too many consecutive
+// scrape failures, which usually means the sites block us or are down
+type failureCooldown struct {
+	threshold int
+	duration  time.Duration
+
+	mu          sync.Mutex
+	consecutive int
+	until       time.Time
+}
+
+// newFailureCooldown returns nil when Common.FailureCooldownAfter is not set
+func newFailureCooldown(cfg *config.Config) *failureCooldown {
+	if cfg.Common.FailureCooldownAfter <= 0 {
+		return nil
+	}
+	duration := time.Duration(cfg.ParseFailureCooldown()) * time.Second
+	if duration <= 0 {
+		duration = DefaultFailureCooldown
+	}
+	return &failureCooldown{
+		threshold: cfg.Common.FailureCooldownAfter,
+		duration:  duration,
+	}
+}
+
+// record counts a scrape outcome and starts a cooldown when the threshold is reached.
+// outage is true when the sites could not be reached; any other outcome, including
+// a number no source has, resets the count.
+func (c *failureCooldown) record(outage bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !outage {
+		c.consecutive = 0
+		return
+	}
+	c.consecutive++
+	if c.consecutive < c.threshold {
+		return
+	}
+
+	c.consecutive = 0
+	c.until = time.Now().Add(c.duration)
+	logger.Warn("%d consecutive scrape failures, pausing for %s until %s", c.threshold, c.duration, c.until.Format("15:04:05"))
+}
+
+// wait blocks while a cooldown is active or until ctx is done
+func (c *failureCooldown) wait(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	remaining := time.Until(c.until)
+	c.mu.Unlock()
+	if remaining <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
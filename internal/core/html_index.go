@@ -0,0 +1,199 @@
+package core
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/nfo"
+)
+
+// htmlMovie is one movie card on a generated page
+type htmlMovie struct {
+	Title  string
+	Number string
+	Year   string
+	Poster string // relative to the page
+	Folder string // relative to the page
+}
+
+// htmlGroup is the list of movies of one actor or studio
+type htmlGroup struct {
+	Name   string
+	Page   string // relative to the index
+	Movies []htmlMovie
+
+	posters []string // absolute poster paths, resolved per page
+	folders []string
+}
+
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Library</title>
+<style>body{font-family:sans-serif;margin:2em}ul{columns:4;list-style:none;padding:0}li{margin:.2em 0}</style>
+</head><body>
+<h1>Library ({{.Movies}} movies)</h1>
+<h2>Actors ({{len .Actors}})</h2>
+<ul>{{range .Actors}}<li><a href="{{.Page}}">{{.Name}}</a> ({{len .Movies}})</li>{{end}}</ul>
+<h2>Studios ({{len .Studios}})</h2>
+<ul>{{range .Studios}}<li><a href="{{.Page}}">{{.Name}}</a> ({{len .Movies}})</li>{{end}}</ul>
+</body></html>
+`))
+
+var htmlGroupTemplate = template.Must(template.New("group").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Name}}</title>
+<style>body{font-family:sans-serif;margin:2em}.grid{display:flex;flex-wrap:wrap;gap:1em}
+.card{width:160px}.card img{width:160px;height:240px;object-fit:cover;background:#ddd}.card p{margin:.3em 0;font-size:.85em}</style>
+</head><body>
+<p><a href="../index.html">&larr; Library</a></p>
+<h1>{{.Name}} ({{len .Movies}})</h1>
+<div class="grid">{{range .Movies}}
+<div class="card"><a href="{{.Folder}}">{{if .Poster}}<img src="{{.Poster}}" alt="{{.Number}}" loading="lazy">{{end}}</a>
+<p><b>{{.Number}}</b>{{if .Year}} ({{.Year}}){{end}}</p><p>{{.Title}}</p></div>{{end}}
+</div>
+</body></html>
+`))
+
+// GenerateHTMLIndex writes static pages listing the organized movies per actor and
+// studio (with poster thumbnails) into Common.HTMLIndexDir, built from the NFOs
+// found under the output roots
+func GenerateHTMLIndex(cfg *config.Config) error {
+	outDir := cfg.Common.HTMLIndexDir
+	if outDir == "" {
+		return fmt.Errorf("html_index_dir is not configured")
+	}
+
+	var paths []string
+	for _, root := range cfg.OutputRoots() {
+		files, err := nfo.FindNFOFiles(root, cfg.Extrafanart.ExtrafanartFolder)
+		if err != nil {
+			logger.Warn("Failed to scan %s for NFOs: %v", root, err)
+		}
+		paths = append(paths, files...)
+	}
+	sort.Strings(paths)
+
+	actors := make(map[string]*htmlGroup)
+	studios := make(map[string]*htmlGroup)
+	add := func(groups map[string]*htmlGroup, kind, name, folder, poster string, movie htmlMovie) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = &htmlGroup{Name: name, Page: kind + "/" + url.PathEscape(htmlPageName(name))}
+			groups[name] = group
+		}
+		group.Movies = append(group.Movies, movie)
+		group.posters = append(group.posters, poster)
+		group.folders = append(group.folders, folder)
+	}
+
+	movies := 0
+	nfo.ParseEach(paths, cfg.Common.NFOParseWorkers, func(path string, movie *nfo.Movie) {
+		folder := filepath.Dir(path)
+		poster := htmlPosterPath(folder, movie)
+		card := htmlMovie{Title: movie.Title, Number: movie.Number, Year: movie.Year}
+		for _, actor := range movie.Actors {
+			add(actors, "actors", actor.Name, folder, poster, card)
+		}
+		add(studios, "studios", movie.Studio, folder, poster, card)
+		movies++
+	})
+
+	for kind, groups := range map[string]map[string]*htmlGroup{"actors": actors, "studios": studios} {
+		pageDir := filepath.Join(outDir, kind)
+		if err := os.MkdirAll(pageDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", pageDir, err)
+		}
+		for _, group := range groups {
+			for i := range group.Movies {
+				group.Movies[i].Folder = htmlRelLink(pageDir, group.folders[i])
+				if group.posters[i] != "" {
+					group.Movies[i].Poster = htmlRelLink(pageDir, group.posters[i])
+				}
+			}
+			if err := writeHTMLPage(filepath.Join(pageDir, htmlPageName(group.Name)), htmlGroupTemplate, group); err != nil {
+				return err
+			}
+		}
+	}
+
+	data := struct {
+		Movies  int
+		Actors  []*htmlGroup
+		Studios []*htmlGroup
+	}{movies, sortedHTMLGroups(actors), sortedHTMLGroups(studios)}
+	if err := writeHTMLPage(filepath.Join(outDir, "index.html"), htmlIndexTemplate, data); err != nil {
+		return err
+	}
+
+	logger.Info("Generated HTML index for %d movies (%d actors, %d studios) in %s", movies, len(actors), len(studios), outDir)
+	return nil
+}
+
+// htmlPosterPath returns the absolute poster (or thumb) path of an NFO folder, "" when missing
+func htmlPosterPath(folder string, movie *nfo.Movie) string {
+	for _, name := range []string{movie.Poster, movie.Thumb} {
+		if name == "" || strings.Contains(name, "://") {
+			continue
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(folder, name)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// htmlRelLink returns target relative to dir as a URL path
+func htmlRelLink(dir, target string) string {
+	absDir, err1 := filepath.Abs(dir)
+	absTarget, err2 := filepath.Abs(target)
+	if err1 == nil && err2 == nil {
+		if rel, err := filepath.Rel(absDir, absTarget); err == nil {
+			target = rel
+		}
+	}
+	parts := strings.Split(filepath.ToSlash(target), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// htmlPageName returns the file name of the page of an actor or studio
+func htmlPageName(name string) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+	return replacer.Replace(name) + ".html"
+}
+
+func sortedHTMLGroups(groups map[string]*htmlGroup) []*htmlGroup {
+	result := make([]*htmlGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func writeHTMLPage(path string, tmpl *template.Template, data interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
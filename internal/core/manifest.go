@@ -0,0 +1,152 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// Statuses recorded in the processed manifest
+const (
+	manifestSuccess = "success"
+	manifestFailed  = "failed"
+	manifestSkipped = "skipped"
+)
+
+// manifestEntry is the outcome of one file in the processed manifest
+type manifestEntry struct {
+	Number      string `json:"number"`
+	Status      string `json:"status"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Time        string `json:"time"`
+}
+
+// manifestWriter owns the processed manifest. Workers hand their results to it over
+// a channel and a single goroutine applies them and rewrites the file atomically,
+// so concurrent workers never write the file themselves.
+type manifestWriter struct {
+	path    string
+	entries map[string]manifestEntry // by file path, only touched by the writer goroutine
+	updates chan manifestUpdate
+	done    chan struct{}
+}
+
+// manifestUpdate is a result sent to the writer goroutine
+type manifestUpdate struct {
+	file  string
+	entry manifestEntry
+}
+
+// newManifestWriter loads the manifest at path, keeping the entries of earlier runs,
+// and starts the writer goroutine
+func newManifestWriter(path string) (*manifestWriter, error) {
+	w := &manifestWriter{
+		path:    path,
+		entries: make(map[string]manifestEntry),
+		updates: make(chan manifestUpdate, 64),
+		done:    make(chan struct{}),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read processed manifest: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &w.entries); err != nil {
+			return nil, fmt.Errorf("failed to parse processed manifest: %w", err)
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// record queues the outcome of one processed movie; safe for concurrent use
+func (w *manifestWriter) record(result ProcessResult) {
+	entry := manifestEntry{
+		Number:      result.Number,
+		Status:      manifestFailed,
+		Source:      result.Source,
+		Destination: result.Destination,
+		Time:        time.Now().Format(time.RFC3339),
+	}
+	switch {
+	case result.Success:
+		entry.Status = manifestSuccess
+	case result.Skipped:
+		entry.Status = manifestSkipped
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	w.updates <- manifestUpdate{file: result.FilePath, entry: entry}
+}
+
+// run applies queued results and writes the manifest, once per batch of results
+// that arrived while the previous write was in progress
+func (w *manifestWriter) run() {
+	defer close(w.done)
+	for update := range w.updates {
+		w.entries[update.file] = update.entry
+	drain:
+		for {
+			select {
+			case next, ok := <-w.updates:
+				if !ok {
+					break drain
+				}
+				w.entries[next.file] = next.entry
+			default:
+				break drain
+			}
+		}
+		if err := w.write(); err != nil {
+			logger.Warn("Failed to write processed manifest: %v", err)
+		}
+	}
+}
+
+// write replaces the manifest with the current entries through a temporary file,
+// so a crash never leaves a partially written manifest behind
+func (w *manifestWriter) write() error {
+	data, err := json.MarshalIndent(w.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(w.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), w.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// close waits until every queued result is written; record must not be called afterwards
+func (w *manifestWriter) close() {
+	close(w.updates)
+	<-w.done
+}
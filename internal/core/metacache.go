@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+)
+
+// metadataCache stores scraped movie data per number in Common.MetadataCacheDir so that
+// reruns neither scrape nor translate a movie again
+type metadataCache struct {
+	dir    string
+	maxAge time.Duration
+}
+
+// metadataEntry is one cached movie. Original is the scraped data; Translated is the same
+// data after translation into Language for the fields in Fields.
+type metadataEntry struct {
+	Number     string          `json:"number"`
+	Cached     time.Time       `json:"cached"`
+	Original   json.RawMessage `json:"original"`
+	Language   string          `json:"language,omitempty"`
+	Fields     string          `json:"fields,omitempty"`
+	Translated json.RawMessage `json:"translated,omitempty"`
+}
+
+// newMetadataCache returns nil when Common.MetadataCacheDir is not set
+func newMetadataCache(cfg *config.Config) *metadataCache {
+	if cfg.Common.MetadataCacheDir == "" {
+		return nil
+	}
+	return &metadataCache{
+		dir:    cfg.Common.MetadataCacheDir,
+		maxAge: time.Duration(cfg.Common.MetadataCacheDays) * 24 * time.Hour,
+	}
+}
+
+// path returns the cache file of number
+func (c *metadataCache) path(number string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(strings.ToUpper(number))
+	return filepath.Join(c.dir, name+".json")
+}
+
+// load returns the cached entry of number, or nil when it is missing or expired
+func (c *metadataCache) load(number string) *metadataEntry {
+	data, err := os.ReadFile(c.path(number))
+	if err != nil {
+		return nil
+	}
+	var entry metadataEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		logger.Debug("Ignoring unreadable metadata cache entry for %s: %v", number, err)
+		return nil
+	}
+	if c.maxAge > 0 && time.Since(entry.Cached) > c.maxAge {
+		return nil
+	}
+	return &entry
+}
+
+// store writes entry to the cache
+func (c *metadataCache) store(entry *metadataEntry) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create metadata cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata cache entry: %w", err)
+	}
+	if err := os.WriteFile(c.path(entry.Number), data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata cache entry: %w", err)
+	}
+	return nil
+}
+
+// movie returns fresh copies of the cached data: the untranslated original, and final when
+// the entry already matches the current translate settings (nil when it has to be translated)
+func (e *metadataEntry) movie(cfg *config.Config) (original, final *scraper.MovieData) {
+	if err := json.Unmarshal(e.Original, &original); err != nil || original == nil {
+		return nil, nil
+	}
+
+	switch {
+	case !cfg.Translate.Switch:
+		if err := json.Unmarshal(e.Original, &final); err != nil {
+			final = nil
+		}
+	case len(e.Translated) > 0 && e.Language == cfg.Translate.TargetLang && e.Fields == cfg.Translate.Values:
+		if err := json.Unmarshal(e.Translated, &final); err != nil {
+			final = nil
+		}
+	}
+	return original, final
+}
+
+// scrapedMovie is the untranslated data of a number together with what is needed to
+// translate and cache it once the caller has decided to keep the movie
+type scrapedMovie struct {
+	data  *scraper.MovieData // untranslated, as scraped
+	entry *metadataEntry     // cache entry to store, nil when the result is not cached
+	final *scraper.MovieData // cached data already matching the translate settings
+}
+
+// scrapeMovie returns the untranslated data of number, from the metadata cache when possible.
+// Callers check the data (validation, skip tags) and then call translateAndCache, so tags
+// are matched in the source language and only accepted movies are translated and cached.
+// It returns nil without an error when no source has the movie.
+func (p *Processor) scrapeMovie(ctx context.Context, number, specifiedSource, specifiedURL string) (*scrapedMovie, error) {
+	// An explicit source or URL always scrapes again
+	useCache := p.metaCache != nil && number != "" && specifiedSource == "" && specifiedURL == ""
+
+	if useCache {
+		if entry := p.metaCache.load(number); entry != nil {
+			if original, final := entry.movie(p.config); original != nil {
+				return &scrapedMovie{data: original, entry: entry, final: final}, nil
+			}
+		}
+	}
+
+	// Wait out a cooldown started by consecutive failures of other movies
+	if err := p.cooldown.wait(ctx); err != nil {
+		return nil, err
+	}
+	data, err := p.scraper.GetDataFromNumber(number, specifiedSource, specifiedURL)
+	// Only sites being unreachable count towards the cooldown; a number no source has does not
+	p.cooldown.record(scraper.IsOutage(err))
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	movie := &scrapedMovie{data: data}
+	if useCache {
+		if original, err := json.Marshal(data); err == nil {
+			movie.entry = &metadataEntry{Number: number, Original: original}
+		}
+	}
+	return movie, nil
+}
+
+// translateAndCache returns the data of movie translated when translate.switch is set,
+// and stores it in the metadata cache. Data strict validation rejects is never cached.
+func (p *Processor) translateAndCache(ctx context.Context, movie *scrapedMovie) *scraper.MovieData {
+	if movie.final != nil {
+		logger.Info("Using cached metadata for %s", movie.entry.Number)
+		return movie.final
+	}
+	if movie.entry != nil && !movie.entry.Cached.IsZero() {
+		logger.Info("Using cached metadata for %s, translating again for the current settings", movie.entry.Number)
+	}
+
+	data := movie.data
+	p.translateMovie(ctx, data)
+
+	entry := movie.entry
+	if entry == nil || p.config.Common.DryRun {
+		return data
+	}
+	if p.config.Common.StrictValidation && data.Validate() != nil {
+		return data
+	}
+
+	entry.Cached = time.Now()
+	entry.Language, entry.Fields, entry.Translated = "", "", nil
+	if p.config.Translate.Switch {
+		if translated, err := json.Marshal(data); err == nil {
+			entry.Language, entry.Fields, entry.Translated = p.config.Translate.TargetLang, p.config.Translate.Values, translated
+		}
+	}
+	if err := p.metaCache.store(entry); err != nil {
+		logger.Warn("Failed to cache metadata of %s: %v", entry.Number, err)
+	}
+	return data
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/mediainfo"
+)
+
+// muxAvailable reports whether ffmpeg can be used, warning once when it cannot
+func (p *Processor) muxAvailable() bool {
+	p.muxerOnce.Do(func() {
+		p.muxerOK = p.muxer.Available()
+		if !p.muxerOK {
+			logger.Warn("ffmpeg not found, subtitle and chapter muxing is disabled")
+		}
+	})
+	return p.muxerOK
+}
+
+// muxIntoMKV copies the external subtitles next to the organized video at destPath,
+// and the chapter file next to the original video at sourcePath, into the mkv when
+// Media.MuxSubtitles or Media.MuxChapters is set. Muxed subtitle files are removed.
+// Failures are logged and leave the video and its subtitles as they were.
+func (p *Processor) muxIntoMKV(sourcePath, destPath string) {
+	media := p.config.Media
+	if !media.MuxSubtitles && !media.MuxChapters {
+		return
+	}
+	if !strings.EqualFold(filepath.Ext(destPath), ".mkv") {
+		return
+	}
+	// Remuxing would replace a link with a copy, and a dry run has no file yet
+	if p.config.Common.LinkMode != 0 || p.config.Common.DryRun {
+		return
+	}
+
+	var subtitles []mediainfo.Subtitle
+	if media.MuxSubtitles {
+		subtitles = mediainfo.MuxableSubtitles(p.storage.FindSubtitleFiles(destPath))
+	}
+	chapterFile := ""
+	if media.MuxChapters {
+		candidate := strings.TrimSuffix(sourcePath, filepath.Ext(sourcePath)) + mediainfo.ChapterFileSuffix
+		if mediainfo.IsChapterFile(candidate) {
+			chapterFile = candidate
+		}
+	}
+	if len(subtitles) == 0 && chapterFile == "" {
+		return
+	}
+	if !p.muxAvailable() {
+		return
+	}
+
+	existing := 0
+	if info := p.probe(destPath); info != nil {
+		existing = info.SubtitleCount()
+	}
+
+	name := filepath.Base(destPath)
+	if err := p.muxer.MuxMKV(destPath, subtitles, chapterFile, existing); err != nil {
+		logger.Warn("Failed to mux subtitles into %s: %v", name, err)
+		return
+	}
+	logger.Info("Muxed %d subtitle(s) into %s", len(subtitles), name)
+	if chapterFile != "" {
+		logger.Info("Muxed chapters from %s into %s", filepath.Base(chapterFile), name)
+	}
+
+	for _, subtitle := range subtitles {
+		if err := os.Remove(subtitle.Path); err != nil {
+			logger.Warn("Failed to remove muxed subtitle %s: %v", filepath.Base(subtitle.Path), err)
+		}
+		// A VobSub .idx is muxed together with its .sub
+		if strings.EqualFold(filepath.Ext(subtitle.Path), ".idx") {
+			os.Remove(strings.TrimSuffix(subtitle.Path, filepath.Ext(subtitle.Path)) + ".sub")
+		}
+	}
+}
@@ -2,9 +2,11 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -13,11 +15,15 @@ import (
 	"movie-data-capture/internal/scraper"
 	"movie-data-capture/pkg/downloader"
 	"movie-data-capture/pkg/fragment"
+	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/imageprocessor"
+	"movie-data-capture/pkg/library"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/mediainfo"
 	"movie-data-capture/pkg/nfo"
 	"movie-data-capture/pkg/storage"
 	"movie-data-capture/pkg/strm"
+	"movie-data-capture/pkg/translator"
 	"movie-data-capture/pkg/utils"
 	"movie-data-capture/pkg/watermark"
 )
@@ -33,6 +39,19 @@ type Processor struct {
 	imageProcessor *imageprocessor.ImageProcessor
 	fragmentMgr   *fragment.FragmentManager
 	strmGen       *strm.STRMGenerator
+	report        *RunReport
+	prober        *mediainfo.Prober
+	proberOnce    sync.Once
+	proberOK      bool
+	probeMu       sync.Mutex
+	probeCache    map[string]*mediainfo.Info
+	muxer         *mediainfo.Muxer
+	muxerOnce     sync.Once
+	muxerOK       bool
+	library       *library.Index
+	translator    translator.Translator
+	metaCache     *metadataCache
+	cooldown      *failureCooldown
 
 	// Concurrency control
 	semaphore  chan struct{}
@@ -40,14 +59,29 @@ type Processor struct {
 	processMux sync.Mutex
 	processed  int
 	failed     int
+	skipped    int
+	existing   int
+	total      int
+	recovery   *runRecovery
 }
 
 // ProcessResult represents the result of processing a movie
 type ProcessResult struct {
-	FilePath string
-	Number   string
-	Success  bool
-	Error    error
+	FilePath   string
+	Number     string
+	Source     string
+	Confidence float64
+	Success    bool
+	Skipped    bool
+	Error      error
+	// Deferred marks a skipped file that should be picked up again by the next run
+	Deferred bool
+	// Existing marks a skipped file whose movie is already in the library (Common.SkipExisting)
+	Existing bool
+	// TitleMismatch is "<source>: <title>" of another source whose title differs significantly
+	TitleMismatch string
+	// Destination is the folder the movie was placed in
+	Destination string
 }
 
 // ProcessItem represents an item to be processed (either a single file or a fragment group)
@@ -65,6 +99,12 @@ func NewProcessor(cfg *config.Config) *Processor {
 		maxWorkers = 1 // Sequential processing
 	}
 
+	// Shared outbound request budget and request trace for scraping and downloading
+	httpclient.SetMaxInflightRequests(cfg.Common.MaxInflightRequests)
+	httpclient.SetRequestLogging(cfg.DebugMode.HTTPTrace)
+	httpclient.SetDNSOptions(cfg.Common.MaxDNSLookups, time.Duration(cfg.Common.DNSCacheTTL)*time.Second)
+	httpclient.SetAdaptivePacing(cfg.Common.AdaptiveSleep, time.Duration(cfg.Common.AdaptiveSleepMax)*time.Second)
+
 	p := &Processor{
 		config:        cfg,
 		scraper:       scraper.New(cfg),
@@ -75,9 +115,27 @@ func NewProcessor(cfg *config.Config) *Processor {
 		imageProcessor: imageprocessor.NewImageProcessor(cfg),
 		fragmentMgr:   fragment.NewFragmentManager(),
 		strmGen:       strm.New(cfg),
+		report:        NewRunReport(cfg),
+		prober:        mediainfo.NewProber(cfg.Media.FFprobePath),
+		probeCache:    make(map[string]*mediainfo.Info),
+		muxer:         mediainfo.NewMuxer(cfg.Media.FFmpegPath),
 		semaphore:     make(chan struct{}, maxWorkers),
 	}
 
+	p.translator = newTranslator(p)
+	p.metaCache = newMetadataCache(cfg)
+	p.cooldown = newFailureCooldown(cfg)
+
+	// Central library index updated after each successful movie
+	if cfg.Common.LibraryIndex != "" {
+		index, err := library.Open(cfg.Common.LibraryIndex)
+		if err != nil {
+			logger.Warn("Library index disabled: %v", err)
+		} else {
+			p.library = index
+		}
+	}
+
 	return p
 }
 
@@ -90,9 +148,16 @@ func (p *Processor) ProcessSingleFile(filePath, number, specifiedSource, specifi
 	logger.Info("Using number: %s", number)
 
 	result := p.processMovie(ctx, filePath, number, specifiedSource, specifiedURL)
+	if p.config.Common.DryRun {
+		p.storage.LogDryRunSummary()
+	}
 	if result.Error != nil {
 		return fmt.Errorf("failed to process %s: %w", filePath, result.Error)
 	}
+	if result.Skipped {
+		logger.Info("Skipped: %s", filePath)
+		return nil
+	}
 
 	logger.Info("Successfully processed: %s", filePath)
 	return nil
@@ -105,8 +170,40 @@ func (p *Processor) processMovieWithFragment(ctx context.Context, item ProcessIt
 		Number:   number,
 	}
 
+	// Another instance may be working on the same file
+	unlock, ok := p.lockMovie(item.FilePath)
+	if !ok {
+		// Left for a later run in case the other instance fails
+		result.Skipped = true
+		result.Deferred = true
+		return result
+	}
+	defer unlock()
+
+	// Files still being downloaded or played are left for the next run
+	files := []string{item.FilePath}
+	if item.IsFragment && item.FragmentGroup != nil {
+		files = files[:0]
+		for _, frag := range item.FragmentGroup.Fragments {
+			files = append(files, frag.FilePath)
+		}
+	}
+	if p.fileInUse(files...) {
+		result.Skipped = true
+		result.Deferred = true
+		return result
+	}
+
+	// Movies already in the library are not scraped again
+	if p.alreadyInLibrary(item.FilePath, number) {
+		result.Skipped = true
+		result.Existing = true
+		return result
+	}
+
 	// Parse movie flags from the main file
 	flags := utils.ParseMovieFlags(filepath.Base(item.FilePath))
+	p.detectChineseSubtitle(item.FilePath, &flags)
 	
 	// Prepare fragment information
 	var isMultiPart bool
@@ -145,19 +242,61 @@ func (p *Processor) processMovieWithFragment(ctx context.Context, item ProcessIt
 	uncensored := utils.IsUncensored(number, p.config)
 
 	// Get movie data from scraper
-	movieData, err := p.scraper.GetDataFromNumber(number, customNumber, customUrl)
+	scraped, err := p.scrapeMovie(ctx, number, customNumber, customUrl)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to scrape data: %w", err)
+		p.recordFailureReasons(item.FilePath, number, err)
 		p.handleFailedFile(item.FilePath)
 		return result
 	}
 
-	if movieData == nil {
+	if scraped == nil {
 		result.Error = fmt.Errorf("no movie data found")
 		p.handleFailedFile(item.FilePath)
 		return result
 	}
 
+	movieData := scraped.data
+
+	// Reject clearly broken scrapes before any folder is created
+	if err := p.validateMovieData(item.FilePath, number, movieData); err != nil {
+		result.Error = err
+		p.handleFailedFile(item.FilePath)
+		return result
+	}
+
+	if result.Number == "" {
+		result.Number = movieData.Number
+	}
+	result.Source = movieData.Source
+	result.Confidence = movieData.Confidence
+	result.TitleMismatch = movieData.TitleMismatch
+
+	// Skip movies carrying a blacklisted genre
+	if tag := p.skipTag(movieData); tag != "" {
+		logger.Info("Skipping %s (%s): blacklisted tag %q", movieData.Number, filepath.Base(item.FilePath), tag)
+		files := []string{item.FilePath}
+		if item.IsFragment && item.FragmentGroup != nil {
+			files = files[:0]
+			for _, frag := range item.FragmentGroup.Fragments {
+				files = append(files, frag.FilePath)
+			}
+		}
+		p.handleSkippedFiles(files)
+		result.Skipped = true
+		return result
+	}
+
+	// Translate only movies that are kept, and cache them once they passed the checks above
+	movieData = p.translateAndCache(ctx, scraped)
+
+	// Tag by the actual video resolution if enabled
+	p.applyResolution(item.FilePath, movieData)
+	p.applyLocationTokens(item.FilePath, movieData, flags)
+
+	// Settle on one year when the filename and the scraped data disagree
+	p.applyYearSource(item.FilePath, movieData)
+
 	// Debug print if enabled
 	if p.config.DebugMode.Switch {
 		utils.DebugPrint(movieData)
@@ -184,6 +323,8 @@ func (p *Processor) processMovieWithFragment(ctx context.Context, item ProcessIt
 		return result
 	}
 
+	result.Destination = p.outputFolder(item.FilePath, movieData)
+	p.indexMovie(result.Destination, movieData)
 	result.Success = true
 	return result
 }
@@ -195,6 +336,43 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 		return nil
 	}
 
+	// Resume an interrupted run from its checkpoints
+	if stateFile := p.config.Common.RecoveryFile; stateFile != "" && !p.config.Common.DryRun {
+		sourceFolder := p.config.Common.SourceFolder
+		if sourceFolder == "" {
+			sourceFolder = "."
+		}
+		rec, err := newRunRecovery(stateFile, sourceFolder)
+		if err != nil {
+			logger.Warn("Recovery disabled: %v", err)
+		} else {
+			movieList = rec.begin(movieList)
+			p.processMux.Lock()
+			p.recovery = rec
+			p.processMux.Unlock()
+		}
+	}
+
+	// Results are also written to the processed manifest by its own goroutine
+	var manifest *manifestWriter
+	if path := p.config.Common.ProcessedManifest; path != "" && !p.config.Common.DryRun {
+		writer, err := newManifestWriter(path)
+		if err != nil {
+			logger.Warn("Processed manifest disabled: %v", err)
+		} else {
+			manifest = writer
+		}
+	}
+
+	// Every scanned file starts as unprocessed for the processed markers
+	var outcomes map[string]bool
+	if p.config.Common.ProcessedMarker && !p.config.Common.DryRun {
+		outcomes = make(map[string]bool, len(movieList))
+		for _, path := range movieList {
+			outcomes[path] = false
+		}
+	}
+
 	// Apply stop counter if configured
 	stopCounter := p.config.Common.StopCounter
 	if stopCounter > 0 && stopCounter < len(movieList) {
@@ -236,6 +414,10 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 		})
 	}
 
+	p.processMux.Lock()
+	p.total = len(processQueue)
+	p.processMux.Unlock()
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -243,11 +425,25 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 	// Channel for results
 	resultChan := make(chan ProcessResult, len(processQueue))
 
+	// Time budget for scheduled runs: stop starting new movies once it is spent
+	var deadline time.Time
+	if seconds := p.config.ParseMaxDuration(); seconds > 0 {
+		deadline = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+
 	// Process movies with concurrency control
 	for i, item := range processQueue {
 		// Acquire semaphore slot
 		p.semaphore <- struct{}{}
 
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			<-p.semaphore
+			remaining := len(processQueue) - i
+			logger.Warn("Max duration %s reached, not starting the remaining %d movie(s)", p.config.Common.MaxDuration, remaining)
+			p.report.SetUnprocessed(remaining)
+			break
+		}
+
 		// Extract number from filename
 		number := utils.GetNumberFromFilename(filepath.Base(item.FilePath))
 		if number == "" {
@@ -256,6 +452,18 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 			continue
 		}
 
+		// Only numbers from known studios are processed when an allowlist is set
+		if !utils.HasAllowedPrefix(number, p.config.Common.AllowedPrefixes) {
+			logger.Info("Skipping %s: number %s has no allowed prefix", filepath.Base(item.FilePath), number)
+			<-p.semaphore
+			result := ProcessResult{FilePath: item.FilePath, Number: number, Skipped: true}
+			if manifest != nil {
+				manifest.record(result)
+			}
+			resultChan <- result
+			continue
+		}
+
 		// Add to wait group and start processing
 		p.wg.Add(1)
 		go func(processItem ProcessItem, num string, index int) {
@@ -275,13 +483,16 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 					percentage, index+1, len(processQueue), filepath.Base(processItem.FilePath))
 			}
 
-			// Add processing delay
-			if p.config.Common.Sleep > 0 {
+			// Add processing delay (adaptive pacing spaces requests per host instead)
+			if p.config.Common.Sleep > 0 && !p.config.Common.AdaptiveSleep {
 				time.Sleep(time.Duration(p.config.Common.Sleep) * time.Second)
 			}
 
 			// Process the movie (with fragment context)
 			result := p.processMovieWithFragment(ctx, processItem, num, "", "")
+			if manifest != nil {
+				manifest.record(result)
+			}
 			resultChan <- result
 		}(item, number, i)
 	}
@@ -292,11 +503,35 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 		close(resultChan)
 	}()
 
+	// Fragment groups report a single result for all their parts
+	groupFiles := make(map[string][]string)
+	for _, item := range processQueue {
+		if item.IsFragment && item.FragmentGroup != nil {
+			for _, frag := range item.FragmentGroup.Fragments {
+				groupFiles[item.FilePath] = append(groupFiles[item.FilePath], frag.FilePath)
+			}
+		}
+	}
+
 	// Collect results
 	for result := range resultChan {
+		p.report.Add(result)
+		if p.recovery != nil {
+			p.recovery.record(result)
+		}
+		if outcomes != nil && (result.Success || (result.Skipped && !result.Deferred)) {
+			outcomes[result.FilePath] = true
+			for _, path := range groupFiles[result.FilePath] {
+				outcomes[path] = true
+			}
+		}
 		p.processMux.Lock()
 		if result.Success {
 			p.processed++
+		} else if result.Existing {
+			p.existing++
+		} else if result.Skipped {
+			p.skipped++
 		} else {
 			p.failed++
 			logger.Error("Failed to process %s: %v", result.FilePath, result.Error)
@@ -304,13 +539,45 @@ func (p *Processor) ProcessMovieList(movieList []string) error {
 		p.processMux.Unlock()
 	}
 
-	logger.Info("Processing completed: %d successful, %d failed", p.processed, p.failed)
+	if manifest != nil {
+		manifest.close()
+	}
+
+	logger.Info("Processing completed: %d successful, %d failed, %d skipped, %d already in library", p.processed, p.failed, p.skipped, p.existing)
+	p.report.Finish()
+	if p.recovery != nil {
+		p.recovery.finish(p.report.Unprocessed > 0)
+	}
+
+	// A dry run ends with the planned moves instead of touching the library
+	if p.config.Common.DryRun {
+		p.storage.LogDryRunSummary()
+		return nil
+	}
 
 	// Clean up empty folders if configured
 	if p.config.Common.DelEmptyFolder {
 		p.cleanupEmptyFolders()
 	}
 
+	// Refresh the static library pages
+	if p.config.Common.HTMLIndexDir != "" {
+		if err := GenerateHTMLIndex(p.config); err != nil {
+			logger.Warn("Failed to generate HTML index: %v", err)
+		}
+	}
+
+	// Mark fully processed source subfolders so the next scan can skip them
+	if outcomes != nil {
+		sourceFolder := p.config.Common.SourceFolder
+		if sourceFolder == "" {
+			sourceFolder = "."
+		}
+		if n := utils.WriteProcessedMarkers(sourceFolder, outcomes); n > 0 {
+			logger.Info("Marked %d source folder(s) as processed", n)
+		}
+	}
+
 	return nil
 }
 
@@ -321,26 +588,83 @@ func (p *Processor) processMovie(ctx context.Context, filePath, number, specifie
 		Number:   number,
 	}
 
+	// Another instance may be working on the same file
+	unlock, ok := p.lockMovie(filePath)
+	if !ok {
+		// Left for a later run in case the other instance fails
+		result.Skipped = true
+		result.Deferred = true
+		return result
+	}
+	defer unlock()
+
+	// Files still being downloaded or played are left for the next run
+	if p.fileInUse(filePath) {
+		result.Skipped = true
+		result.Deferred = true
+		return result
+	}
+
+	// Movies already in the library are not scraped again
+	if p.alreadyInLibrary(filePath, number) {
+		result.Skipped = true
+		result.Existing = true
+		return result
+	}
+
 	// Parse movie flags from filename
 	flags := utils.ParseMovieFlags(filePath)
+	p.detectChineseSubtitle(filePath, &flags)
 
 	// Check if uncensored
 	uncensored := utils.IsUncensored(number, p.config)
 
 	// Get movie data from scraper
-	movieData, err := p.scraper.GetDataFromNumber(number, specifiedSource, specifiedURL)
+	scraped, err := p.scrapeMovie(ctx, number, specifiedSource, specifiedURL)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to scrape data: %w", err)
+		p.recordFailureReasons(filePath, number, err)
 		p.handleFailedFile(filePath)
 		return result
 	}
 
-	if movieData == nil {
+	if scraped == nil {
 		result.Error = fmt.Errorf("no movie data found")
 		p.handleFailedFile(filePath)
 		return result
 	}
 
+	movieData := scraped.data
+
+	// Reject clearly broken scrapes before any folder is created
+	if err := p.validateMovieData(filePath, number, movieData); err != nil {
+		result.Error = err
+		p.handleFailedFile(filePath)
+		return result
+	}
+
+	result.Source = movieData.Source
+	result.Confidence = movieData.Confidence
+	result.TitleMismatch = movieData.TitleMismatch
+
+	// Skip movies carrying a blacklisted genre
+	if tag := p.skipTag(movieData); tag != "" {
+		logger.Info("Skipping %s (%s): blacklisted tag %q", movieData.Number, filepath.Base(filePath), tag)
+		p.handleSkippedFiles([]string{filePath})
+		result.Skipped = true
+		return result
+	}
+
+	// Translate only movies that are kept, and cache them once they passed the checks above
+	movieData = p.translateAndCache(ctx, scraped)
+
+	// Tag by the actual video resolution if enabled
+	p.applyResolution(filePath, movieData)
+	p.applyLocationTokens(filePath, movieData, flags)
+
+	// Settle on one year when the filename and the scraped data disagree
+	p.applyYearSource(filePath, movieData)
+
 	// Debug print if enabled
 	if p.config.DebugMode.Switch {
 		utils.DebugPrint(movieData)
@@ -367,6 +691,8 @@ func (p *Processor) processMovie(ctx context.Context, filePath, number, specifie
 		return result
 	}
 
+	result.Destination = p.outputFolder(filePath, movieData)
+	p.indexMovie(result.Destination, movieData)
 	result.Success = true
 	return result
 }
@@ -379,60 +705,84 @@ func (p *Processor) processScrapingModeWithFragment(ctx context.Context, filePat
 		return fmt.Errorf("failed to create folder: %w", err)
 	}
 
-	// Download images and generate file names
-	ext := utils.GetImageExtension(data.Cover)
-	var fanartPath, posterPath, thumbPath string
-	
-	if p.config.NameRule.ImageNamingWithNumber {
-		// Use number-based naming
-		leakWord := ""
-		if flags.Leak {
-			leakWord = "-leak"
+	// Move/link the video file(s) before writing any art, so that a failed move
+	// does not leave posters without a video in the output folder
+	if isMultiPart && fragmentGroup != nil {
+		// For fragment groups, move all fragment files to the same directory
+		logger.Info("Moving %d fragment files to output directory", totalParts)
+		
+		// Move the fragments as one group: either all parts land in the output
+		// folder or none do, even across a crash (see storage.MoveGroup)
+		moves := p.fragmentMoves(data, flags, outputPath, fragmentGroup)
+		if err := p.storage.MoveGroup(data.Number, moves); err != nil {
+			p.removeEmptyFolder(outputPath)
+			return fmt.Errorf("failed to move fragment files: %w", err)
 		}
-		cWord := ""
-		if flags.ChineseSubtitle && !flags.Hack && !flags.Leak {
-			cWord = "-C"
+	} else {
+		// Single file processing
+		destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+		destPath := filepath.Join(outputPath, destFileName)
+		err = p.storage.MoveFile(filePath, destPath)
+		if err != nil {
+			p.removeEmptyFolder(outputPath)
+			return fmt.Errorf("failed to move file: %w", err)
 		}
-		hackWord := ""
-		if flags.Hack {
-			hackWord = "-hack"
+	}
+
+	// Move subtitle files (for fragment groups, only move subtitles for the first part)
+	if !isMultiPart || (fragmentGroup != nil && len(fragmentGroup.Fragments) > 0) {
+		// Use the first fragment file to search for subtitles
+		sourceFile := filePath
+		if fragmentGroup != nil && len(fragmentGroup.Fragments) > 0 {
+			sourceFile = fragmentGroup.Fragments[0].FilePath
 		}
 		
-		prefix := data.Number + leakWord + cWord + hackWord
-		fanartPath = prefix + "-fanart" + ext
-		posterPath = prefix + "-poster" + ext
-		thumbPath = prefix + "-thumb" + ext
-	} else {
-		// Use simple naming
-		fanartPath = "fanart" + ext
-		posterPath = "poster" + ext
-		thumbPath = "thumb" + ext
+		subtitleFiles := p.storage.FindSubtitleFiles(sourceFile)
+		if len(subtitleFiles) > 0 {
+			logger.Info("Found %d subtitle file(s) for video", len(subtitleFiles))
+			// Use the destination file name for subtitle renaming
+			destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+			err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
+			if err != nil {
+				logger.Warn("Failed to move some subtitle files: %v", err)
+			}
+		}
+	}
+
+	// Make the organized mkv self-contained if enabled. Multi-part movies are
+	// left alone because their subtitles cannot be attributed to one part.
+	if !isMultiPart {
+		destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+		p.muxIntoMKV(filePath, filepath.Join(outputPath, destFileName))
+	}
+
+	// A dry run only plans the moves, no art or NFO is written
+	if p.config.Common.DryRun {
+		return nil
 	}
 
+	// Download images and generate file names
+	ext := utils.GetImageExtension(data.Cover)
+	fanartPath, posterPath, thumbPath := p.imageFileNames(data, flags.Leak, flags.ChineseSubtitle, flags.Hack, ext)
+
 	// Download cover image
 	fullThumbPath := filepath.Join(outputPath, thumbPath)
 	if data.Cover != "" {
-		err = p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.Headers)
+		err = p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.CoverRequestHeaders())
 		if err != nil {
 			logger.Warn("Failed to download cover: %v", err)
 		} else {
-			// Create fanart copy for non-Jellyfin
-			if p.config.Common.Jellyfin == 0 {
+			// Create fanart copy unless fanart is turned off (Jellyfin by default)
+			if p.config.GetFanartSource() == "cover" {
 				fullFanartPath := filepath.Join(outputPath, fanartPath)
 				// Copy thumb to fanart (simplified, in real implementation you'd copy the file)
-				p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.Headers)
+				p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.CoverRequestHeaders())
 			}
 		}
 	}
 
-	// Download small cover if needed
-	if data.ImageCut == 3 && data.CoverSmall != "" {
-		smallCoverPath := filepath.Join(outputPath, posterPath)
-		err = p.downloader.DownloadCover(ctx, data.CoverSmall, smallCoverPath, data.Headers)
-		if err != nil {
-			logger.Warn("Failed to download small cover: %v", err)
-		}
-	}
+	// Take the poster from the configured image instead of cutting the cover
+	posterDone := p.sourcedPoster(ctx, data, filepath.Join(outputPath, thumbPath), filepath.Join(outputPath, posterPath))
 
 	// Download extra fanart (only for main part or single file)
 	if (flags.Part == "" || strings.ToLower(flags.Part) == "-cd1") && p.config.Extrafanart.Switch && len(data.Extrafanart) > 0 {
@@ -460,12 +810,19 @@ func (p *Processor) processScrapingModeWithFragment(ctx context.Context, filePat
 		}
 	}
 
+	// Write person NFOs so media servers can show actor pages
+	if (flags.Part == "" || strings.ToLower(flags.Part) == "-cd1") && p.config.ActorPhoto.PersonNFO {
+		p.nfoGen.GeneratePersonNFOs(data, outputPath)
+	}
+
 	// Perform image cutting/cropping
 	logger.Debug("Image cutting check: ImageCut=%d, AlwaysImagecut=%v", data.ImageCut, p.config.Face.AlwaysImagecut)
 	
 	// Check if this is FC2 content - FC2 numbers don't need image cutting
 	isFC2 := strings.HasPrefix(strings.ToUpper(data.Number), "FC2")
-	if isFC2 {
+	if posterDone {
+		logger.Debug("Poster already in place, skipping image cutting: %s", data.Number)
+	} else if isFC2 {
 		logger.Debug("Skipping image cutting for FC2 content: %s", data.Number)
 		// For FC2, copy the same image to poster path (fanart, thumb, poster are the same)
 		if fullThumbPath != "" && posterPath != "" {
@@ -516,98 +873,6 @@ func (p *Processor) processScrapingModeWithFragment(ctx context.Context, filePat
 		}
 	}
 
-	// Move/link the video file(s)
-	if isMultiPart && fragmentGroup != nil {
-		// For fragment groups, move all fragment files to the same directory
-		logger.Info("Moving %d fragment files to output directory", totalParts)
-		
-		// Move all fragment files to the same directory with clean naming
-		for i, fragInfo := range fragmentGroup.Fragments {
-			// Skip if source file doesn't exist (already moved or missing)
-			if _, err := os.Stat(fragInfo.FilePath); os.IsNotExist(err) {
-				logger.Debug("Fragment file already moved or missing: %s", fragInfo.FilePath)
-				continue
-			}
-			
-			// Generate filename without fragment suffix for cleaner naming
-			baseNumber := data.Number
-			fragExt := filepath.Ext(fragInfo.FilePath)
-			
-			// Create Jellyfin-compatible naming for multi-part files
-			// Jellyfin recognizes: movie-part1.ext, movie-cd1.ext, movie-pt1.ext
-			// Use "part" format as it's most universally recognized
-			var destFileName string
-			
-			// Build suffix based on flags
-			suffix := ""
-			if flags.Leak {
-				suffix = "-leak"
-			}
-			if flags.ChineseSubtitle && !flags.Hack && !flags.Leak {
-				suffix = "-C"
-			}
-			if flags.Hack {
-				suffix = "-hack"
-			}
-			
-			// Jellyfin-compatible format: number + suffix + "-part" + index + ext
-			// Example: SSIS-001-part1.mp4, SSIS-001-C-part2.mp4
-			if p.config.Common.Jellyfin > 0 {
-				// Jellyfin模式：使用part命名（Jellyfin堆叠标准）
-				destFileName = fmt.Sprintf("%s%s-part%d%s", baseNumber, suffix, i+1, fragExt)
-			} else {
-				// Kodi模式：使用cd命名（传统格式）
-				destFileName = fmt.Sprintf("%s%s-cd%d%s", baseNumber, suffix, i+1, fragExt)
-			}
-			
-			destPath := filepath.Join(outputPath, destFileName)
-			
-			// Skip if destination file already exists
-			if _, err := os.Stat(destPath); err == nil {
-				logger.Debug("Fragment destination already exists, skipping: %s", destPath)
-				continue
-			}
-			
-			logger.Debug("Moving fragment %d: %s -> %s", i+1, fragInfo.FilePath, destPath)
-			
-			err = p.storage.MoveFile(fragInfo.FilePath, destPath)
-			if err != nil {
-				logger.Warn("Failed to move fragment file %s: %v", fragInfo.FilePath, err)
-				// Continue with other files even if one fails
-			} else {
-				logger.Info("Successfully moved fragment %d: %s", i+1, filepath.Base(destPath))
-			}
-		}
-	} else {
-		// Single file processing
-		destFileName := generateFileName(data.Number, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
-		destPath := filepath.Join(outputPath, destFileName)
-		err = p.storage.MoveFile(filePath, destPath)
-		if err != nil {
-			return fmt.Errorf("failed to move file: %w", err)
-		}
-	}
-
-	// Move subtitle files (for fragment groups, only move subtitles for the first part)
-	if !isMultiPart || (fragmentGroup != nil && len(fragmentGroup.Fragments) > 0) {
-		// Use the first fragment file to search for subtitles
-		sourceFile := filePath
-		if fragmentGroup != nil && len(fragmentGroup.Fragments) > 0 {
-			sourceFile = fragmentGroup.Fragments[0].FilePath
-		}
-		
-		subtitleFiles := p.storage.FindSubtitleFiles(sourceFile)
-		if len(subtitleFiles) > 0 {
-			logger.Info("Found %d subtitle file(s) for video", len(subtitleFiles))
-			// Use the destination file name for subtitle renaming
-			destFileName := generateFileName(data.Number, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
-			err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
-			if err != nil {
-				logger.Warn("Failed to move some subtitle files: %v", err)
-			}
-		}
-	}
-
 	// Generate NFO file with fragment information (do this last as completion marker)
 	err = p.nfoGen.GenerateNFO(data, outputPath, flags.Part, flags.ChineseSubtitle, flags.Leak, uncensored, flags.Hack, flags.FourK, flags.ISO, data.ActorList, posterPath, thumbPath, fanartPath, isMultiPart, totalParts, currentPart, fragmentFiles, totalFileSize)
 	if err != nil {
@@ -638,60 +903,56 @@ func (p *Processor) processScrapingMode(ctx context.Context, filePath string, da
 		return fmt.Errorf("failed to create folder: %w", err)
 	}
 
-	// Download images and generate file names
-	ext := utils.GetImageExtension(data.Cover)
-	var fanartPath, posterPath, thumbPath string
-	
-	if p.config.NameRule.ImageNamingWithNumber {
-		// Use number-based naming
-		leakWord := ""
-		if leak {
-			leakWord = "-leak"
-		}
-		cWord := ""
-		if chineseSubtitle && !hack && !leak {
-			cWord = "-C"
-		}
-		hackWord := ""
-		if hack {
-			hackWord = "-hack"
+	// Move/link the video file before writing any art
+	destFileName := p.videoFileName(data, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
+	destPath := filepath.Join(outputPath, destFileName)
+	err = p.storage.MoveFile(filePath, destPath)
+	if err != nil {
+		p.removeEmptyFolder(outputPath)
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+
+	// Move subtitle files
+	subtitleFiles := p.storage.FindSubtitleFiles(filePath)
+	if len(subtitleFiles) > 0 {
+		logger.Info("Found %d subtitle file(s) for video", len(subtitleFiles))
+		destFileName := p.videoFileName(data, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
+		err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
+		if err != nil {
+			logger.Warn("Failed to move some subtitle files: %v", err)
 		}
-		
-		prefix := data.Number + leakWord + cWord + hackWord
-		fanartPath = prefix + "-fanart" + ext
-		posterPath = prefix + "-poster" + ext
-		thumbPath = prefix + "-thumb" + ext
-	} else {
-		// Use simple naming
-		fanartPath = "fanart" + ext
-		posterPath = "poster" + ext
-		thumbPath = "thumb" + ext
 	}
 
+	// Make the organized mkv self-contained if enabled
+	p.muxIntoMKV(filePath, destPath)
+
+	// A dry run only plans the moves, no art or NFO is written
+	if p.config.Common.DryRun {
+		return nil
+	}
+
+	// Download images and generate file names
+	ext := utils.GetImageExtension(data.Cover)
+	fanartPath, posterPath, thumbPath := p.imageFileNames(data, leak, chineseSubtitle, hack, ext)
+
 	// Download cover image
 	fullThumbPath := filepath.Join(outputPath, thumbPath)
 	if data.Cover != "" {
-		err = p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.Headers)
+		err = p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.CoverRequestHeaders())
 		if err != nil {
 			logger.Warn("Failed to download cover: %v", err)
 		} else {
-			// Create fanart copy for non-Jellyfin
-			if p.config.Common.Jellyfin == 0 {
+			// Create fanart copy unless fanart is turned off (Jellyfin by default)
+			if p.config.GetFanartSource() == "cover" {
 				fullFanartPath := filepath.Join(outputPath, fanartPath)
 				// Copy thumb to fanart (simplified, in real implementation you'd copy the file)
-				p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.Headers)
+				p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.CoverRequestHeaders())
 			}
 		}
 	}
 
-	// Download small cover if needed
-	if data.ImageCut == 3 && data.CoverSmall != "" {
-		smallCoverPath := filepath.Join(outputPath, posterPath)
-		err = p.downloader.DownloadCover(ctx, data.CoverSmall, smallCoverPath, data.Headers)
-		if err != nil {
-			logger.Warn("Failed to download small cover: %v", err)
-		}
-	}
+	// Take the poster from the configured image instead of cutting the cover
+	posterDone := p.sourcedPoster(ctx, data, filepath.Join(outputPath, thumbPath), filepath.Join(outputPath, posterPath))
 
 	// Download extra fanart (only for main part or single file)
 	if (part == "" || strings.ToLower(part) == "-cd1") && p.config.Extrafanart.Switch && len(data.Extrafanart) > 0 {
@@ -719,12 +980,19 @@ func (p *Processor) processScrapingMode(ctx context.Context, filePath string, da
 		}
 	}
 
+	// Write person NFOs so media servers can show actor pages
+	if (part == "" || strings.ToLower(part) == "-cd1") && p.config.ActorPhoto.PersonNFO {
+		p.nfoGen.GeneratePersonNFOs(data, outputPath)
+	}
+
 	// Perform image cutting/cropping
 	logger.Debug("Image cutting check: ImageCut=%d, AlwaysImagecut=%v", data.ImageCut, p.config.Face.AlwaysImagecut)
 	
 	// Check if this is FC2 content - FC2 numbers don't need image cutting
 	isFC2 := strings.HasPrefix(strings.ToUpper(data.Number), "FC2")
-	if isFC2 {
+	if posterDone {
+		logger.Debug("Poster already in place, skipping image cutting: %s", data.Number)
+	} else if isFC2 {
 		logger.Debug("Skipping image cutting for FC2 content: %s", data.Number)
 		// For FC2, copy the same image to poster path (fanart, thumb, poster are the same)
 		if fullThumbPath != "" && posterPath != "" {
@@ -775,25 +1043,6 @@ func (p *Processor) processScrapingMode(ctx context.Context, filePath string, da
 		}
 	}
 
-	// Move/link the video file
-	destFileName := generateFileName(data.Number, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
-	destPath := filepath.Join(outputPath, destFileName)
-	err = p.storage.MoveFile(filePath, destPath)
-	if err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
-	}
-
-	// Move subtitle files
-	subtitleFiles := p.storage.FindSubtitleFiles(filePath)
-	if len(subtitleFiles) > 0 {
-		logger.Info("Found %d subtitle file(s) for video", len(subtitleFiles))
-		destFileName := generateFileName(data.Number, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
-		err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
-		if err != nil {
-			logger.Warn("Failed to move some subtitle files: %v", err)
-		}
-	}
-
 	// Generate NFO file (do this last as completion marker)
 	err = p.nfoGen.GenerateNFO(data, outputPath, part, chineseSubtitle, leak, uncensored, hack, fourK, iso, data.ActorList, posterPath, thumbPath, fanartPath, false, 0, 0, nil, 0)
 	if err != nil {
@@ -809,6 +1058,99 @@ func (p *Processor) processScrapingMode(ctx context.Context, filePath string, da
 	return nil
 }
 
+// lockMovie takes the per-file lock when Common.FileLock is enabled.
+// It returns false when the file is locked or was already moved by another instance.
+func (p *Processor) lockMovie(filePath string) (func(), bool) {
+	if !p.config.Common.FileLock || p.config.Common.DryRun {
+		return func() {}, true
+	}
+
+	unlock, err := storage.LockFile(filePath)
+	if errors.Is(err, storage.ErrLocked) {
+		logger.Info("Skipping %s: locked by another instance", filepath.Base(filePath))
+		return nil, false
+	}
+	if err != nil {
+		// e.g. a read-only source folder, process without the lock
+		logger.Warn("Processing %s without lock: %v", filepath.Base(filePath), err)
+		return func() {}, true
+	}
+
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		unlock()
+		logger.Info("Skipping %s: already handled by another instance", filepath.Base(filePath))
+		return nil, false
+	}
+	return unlock, true
+}
+
+// fileInUse reports whether any of files is still being written or held open
+// by another program when Common.SkipInUse is enabled
+func (p *Processor) fileInUse(files ...string) bool {
+	if !p.config.Common.SkipInUse {
+		return false
+	}
+
+	quietPeriod := time.Duration(p.config.Common.InUseQuietPeriod) * time.Second
+	for _, file := range files {
+		if storage.IsFileInUse(file, quietPeriod) {
+			logger.Warn("Skipping %s: file is in use, it will be retried on the next run", filepath.Base(file))
+			return true
+		}
+	}
+	return false
+}
+
+// skipTag returns the first tag of data that is listed in Content.SkipTags, or ""
+func (p *Processor) skipTag(data *scraper.MovieData) string {
+	if len(p.config.Content.SkipTags) == 0 {
+		return ""
+	}
+	for _, tag := range data.Tag {
+		for _, skip := range p.config.Content.SkipTags {
+			if strings.EqualFold(strings.TrimSpace(tag), strings.TrimSpace(skip)) {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// alreadyInLibrary reports whether Common.SkipExisting is set and the output folder
+// already holds an NFO or video for number. Mode 3 scrapes in place and is not checked.
+func (p *Processor) alreadyInLibrary(filePath, number string) bool {
+	if !p.config.Common.SkipExisting || p.config.Common.MainMode == 3 {
+		return false
+	}
+	root := p.config.OutputRoot(utils.IsUncensored(number, p.config))
+	if !p.storage.IsAlreadyProcessed(number, root) {
+		return false
+	}
+	logger.Info("Skipping %s: %s already exists in %s", filepath.Base(filePath), number, root)
+	return true
+}
+
+// handleSkippedFiles moves skipped files to Content.SkipFolder, or leaves them in place
+func (p *Processor) handleSkippedFiles(files []string) {
+	for _, file := range files {
+		if err := p.storage.MoveToSkippedFolder(file, p.config.Content.SkipFolder); err != nil {
+			logger.Warn("Failed to move skipped file %s: %v", file, err)
+		}
+	}
+}
+
+// removeEmptyFolder removes the output folder created for a movie whose video could not be moved.
+// Folders that already hold files (e.g. a previous run) are left untouched.
+func (p *Processor) removeEmptyFolder(outputPath string) {
+	entries, err := os.ReadDir(outputPath)
+	if err != nil || len(entries) > 0 {
+		return
+	}
+	if err := os.Remove(outputPath); err != nil {
+		logger.Debug("Failed to remove empty folder %s: %v", outputPath, err)
+	}
+}
+
 // processOrganizingModeWithFragment handles mode 2 (organizing without scraping) with fragment support
 func (p *Processor) processOrganizingModeWithFragment(filePath string, data *scraper.MovieData, flags utils.MovieFlags, isMultiPart bool, totalParts, currentPart int, fragmentFiles []string, totalFileSize int64, fragmentGroup *fragment.FragmentGroup) error {
 	// Create output folder
@@ -822,63 +1164,13 @@ func (p *Processor) processOrganizingModeWithFragment(filePath string, data *scr
 		// For fragment groups, move all fragment files to the same directory
 		logger.Info("Moving %d fragment files to output directory (organizing mode)", totalParts)
 		
-		// Move all fragment files to the same directory with clean naming
-		for i, fragInfo := range fragmentGroup.Fragments {
-			// Skip if source file doesn't exist (already moved or missing)
-			if _, err := os.Stat(fragInfo.FilePath); os.IsNotExist(err) {
-				logger.Debug("Fragment file already moved or missing: %s", fragInfo.FilePath)
-				continue
-			}
-			
-			// Generate filename without fragment suffix for cleaner naming
-			baseNumber := data.Number
-			fragExt := filepath.Ext(fragInfo.FilePath)
-			
-			// Create Jellyfin-compatible naming for multi-part files (same as scraping mode)
-			var destFileName string
-			
-			// Build suffix based on flags
-			suffix := ""
-			if flags.Leak {
-				suffix = "-leak"
-			}
-			if flags.ChineseSubtitle && !flags.Hack && !flags.Leak {
-				suffix = "-C"
-			}
-			if flags.Hack {
-				suffix = "-hack"
-			}
-			
-			// Jellyfin-compatible format
-			if p.config.Common.Jellyfin > 0 {
-				// Jellyfin模式：使用part命名（Jellyfin堆叠标准）
-				destFileName = fmt.Sprintf("%s%s-part%d%s", baseNumber, suffix, i+1, fragExt)
-			} else {
-				// Kodi模式：使用cd命名（传统格式）
-				destFileName = fmt.Sprintf("%s%s-cd%d%s", baseNumber, suffix, i+1, fragExt)
-			}
-			
-			destPath := filepath.Join(outputPath, destFileName)
-			
-			// Skip if destination file already exists
-			if _, err := os.Stat(destPath); err == nil {
-				logger.Debug("Fragment destination already exists, skipping: %s", destPath)
-				continue
-			}
-			
-			logger.Debug("Moving fragment %d: %s -> %s", i+1, fragInfo.FilePath, destPath)
-			
-			err = p.storage.MoveFile(fragInfo.FilePath, destPath)
-			if err != nil {
-				logger.Warn("Failed to move fragment file %s: %v", fragInfo.FilePath, err)
-				// Continue with other files even if one fails
-			} else {
-				logger.Info("Successfully moved fragment %d: %s", i+1, filepath.Base(destPath))
-			}
+		moves := p.fragmentMoves(data, flags, outputPath, fragmentGroup)
+		if err := p.storage.MoveGroup(data.Number, moves); err != nil {
+			return fmt.Errorf("failed to move fragment files: %w", err)
 		}
 	} else {
 		// Single file processing
-		destFileName := generateFileName(data.Number, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+		destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
 		destPath := filepath.Join(outputPath, destFileName)
 		err = p.storage.MoveFile(filePath, destPath)
 		if err != nil {
@@ -886,14 +1178,17 @@ func (p *Processor) processOrganizingModeWithFragment(filePath string, data *scr
 		}
 	}
 
-	// Move subtitle files (for fragment groups)
-	if isMultiPart && fragmentGroup != nil && len(fragmentGroup.Fragments) > 0 {
+	// Move subtitle files (for fragment groups, only move subtitles for the first part)
+	if !isMultiPart || (fragmentGroup != nil && len(fragmentGroup.Fragments) > 0) {
 		// Use the first fragment file to search for subtitles
-		sourceFile := fragmentGroup.Fragments[0].FilePath
+		sourceFile := filePath
+		if fragmentGroup != nil && len(fragmentGroup.Fragments) > 0 {
+			sourceFile = fragmentGroup.Fragments[0].FilePath
+		}
 		subtitleFiles := p.storage.FindSubtitleFiles(sourceFile)
 		if len(subtitleFiles) > 0 {
 			logger.Info("Found %d subtitle file(s) for video (organizing mode)", len(subtitleFiles))
-			destFileName := generateFileName(data.Number, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+			destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
 			err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
 			if err != nil {
 				logger.Warn("Failed to move some subtitle files: %v", err)
@@ -901,9 +1196,69 @@ func (p *Processor) processOrganizingModeWithFragment(filePath string, data *scr
 		}
 	}
 
+	// Make the organized mkv self-contained if enabled. Multi-part movies are
+	// left alone because their subtitles cannot be attributed to one part.
+	if !isMultiPart {
+		destFileName := p.videoFileName(data, flags.Part, flags.Leak, flags.ChineseSubtitle, flags.Hack, filepath.Ext(filePath))
+		p.muxIntoMKV(filePath, filepath.Join(outputPath, destFileName))
+	}
+
 	return nil
 }
 
+// fragmentMoves returns the moves that place the fragments of a group in outputPath
+// with stacking names (-cd1, or -part1 for Jellyfin). Fragments already moved or
+// whose destination already exists are left out.
+func (p *Processor) fragmentMoves(data *scraper.MovieData, flags utils.MovieFlags, outputPath string, fragmentGroup *fragment.FragmentGroup) []storage.GroupMove {
+	// Build suffix based on flags
+	suffix := ""
+	if flags.Leak {
+		suffix = "-leak"
+	}
+	if flags.ChineseSubtitle && !flags.Hack && !flags.Leak {
+		suffix = "-C"
+	}
+	if flags.Hack {
+		suffix = "-hack"
+	}
+	suffix += p.resolutionSuffix(data)
+
+	var moves []storage.GroupMove
+	for i, fragInfo := range fragmentGroup.Fragments {
+		// Skip if source file doesn't exist (already moved or missing)
+		if _, err := os.Stat(fragInfo.FilePath); os.IsNotExist(err) {
+			logger.Debug("Fragment file already moved or missing: %s", fragInfo.FilePath)
+			continue
+		}
+
+		// Jellyfin recognizes movie-part1.ext, Kodi movie-cd1.ext
+		// Example: SSIS-001-part1.mp4, SSIS-001-C-cd2.mp4
+		var destFileName string
+		if p.config.Common.Jellyfin > 0 {
+			destFileName = fmt.Sprintf("%s%s-part%d%s", data.Number, suffix, i+1, filepath.Ext(fragInfo.FilePath))
+		} else {
+			destFileName = fmt.Sprintf("%s%s-cd%d%s", data.Number, suffix, i+1, filepath.Ext(fragInfo.FilePath))
+		}
+		destPath := filepath.Join(outputPath, destFileName)
+
+		// Skip if destination file already exists
+		if _, err := os.Stat(destPath); err == nil {
+			logger.Debug("Fragment destination already exists, skipping: %s", destPath)
+			continue
+		}
+
+		logger.Debug("Planned fragment move %d: %s -> %s", i+1, fragInfo.FilePath, destPath)
+		moves = append(moves, storage.GroupMove{Source: fragInfo.FilePath, Destination: destPath})
+	}
+	return moves
+}
+
+// ResumeInterruptedMoves completes or rolls back fragment groups whose move was
+// interrupted by a crash in an earlier run, before sourceFolder is scanned
+func (p *Processor) ResumeInterruptedMoves(sourceFolder string) {
+	p.storage.ResumeMoveJournals(sourceFolder)
+}
+
 // processOrganizingMode handles mode 2 (organizing without scraping)
 func (p *Processor) processOrganizingMode(filePath string, data *scraper.MovieData, part string, leak, chineseSubtitle, hack, fourK, iso bool) error {
 	// Create output folder
@@ -913,7 +1268,7 @@ func (p *Processor) processOrganizingMode(filePath string, data *scraper.MovieDa
 	}
 
 	// Move the file
-	destFileName := generateFileName(data.Number, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
+	destFileName := p.videoFileName(data, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
 	destPath := filepath.Join(outputPath, destFileName)
 	err = p.storage.MoveFile(filePath, destPath)
 	if err != nil {
@@ -924,13 +1279,16 @@ func (p *Processor) processOrganizingMode(filePath string, data *scraper.MovieDa
 	subtitleFiles := p.storage.FindSubtitleFiles(filePath)
 	if len(subtitleFiles) > 0 {
 		logger.Info("Found %d subtitle file(s) for video (organizing mode)", len(subtitleFiles))
-		destFileName := generateFileName(data.Number, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
+		destFileName := p.videoFileName(data, part, leak, chineseSubtitle, hack, filepath.Ext(filePath))
 		err = p.storage.MoveSubtitleFiles(subtitleFiles, destFileName, outputPath)
 		if err != nil {
 			logger.Warn("Failed to move some subtitle files: %v", err)
 		}
 	}
 
+	// Make the organized mkv self-contained if enabled
+	p.muxIntoMKV(filePath, destPath)
+
 	return nil
 }
 
@@ -938,55 +1296,42 @@ func (p *Processor) processOrganizingMode(filePath string, data *scraper.MovieDa
 func (p *Processor) processAnalysisModeWithFragment(ctx context.Context, filePath string, data *scraper.MovieData, flags utils.MovieFlags, uncensored bool, isMultiPart bool, totalParts, currentPart int, fragmentFiles []string, totalFileSize int64, fragmentGroup *fragment.FragmentGroup) error {
 	outputPath := filepath.Dir(filePath)
 
+	// Nothing is moved in this mode, so a dry run has nothing to plan
+	if p.config.Common.DryRun {
+		logger.Info("[DRY RUN] Would scrape in place: %s", filePath)
+		return nil
+	}
+
 	// Generate file names (same logic as scraping mode)
 	ext := utils.GetImageExtension(data.Cover)
-	var fanartPath, posterPath, thumbPath string
-	
-	if p.config.NameRule.ImageNamingWithNumber {
-		leakWord := ""
-		if flags.Leak {
-			leakWord = "-leak"
-		}
-		cWord := ""
-		if flags.ChineseSubtitle && !flags.Hack && !flags.Leak {
-			cWord = "-C"
-		}
-		hackWord := ""
-		if flags.Hack {
-			hackWord = "-hack"
-		}
-		
-		prefix := data.Number + leakWord + cWord + hackWord
-		fanartPath = prefix + "-fanart" + ext
-		posterPath = prefix + "-poster" + ext
-		thumbPath = prefix + "-thumb" + ext
-	} else {
-		fanartPath = "fanart" + ext
-		posterPath = "poster" + ext
-		thumbPath = "thumb" + ext
-	}
+	fanartPath, posterPath, thumbPath := p.imageFileNames(data, flags.Leak, flags.ChineseSubtitle, flags.Hack, ext)
 
 	// Download images (same as scraping mode)
 	if data.Cover != "" {
 		fullThumbPath := filepath.Join(outputPath, thumbPath)
-		err := p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.Headers)
+		err := p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.CoverRequestHeaders())
 		if err != nil {
 			logger.Warn("Failed to download cover: %v", err)
 		}
 
-		if p.config.Common.Jellyfin == 0 {
+		if p.config.GetFanartSource() == "cover" {
 			fullFanartPath := filepath.Join(outputPath, fanartPath)
-			p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.Headers)
+			p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.CoverRequestHeaders())
 		}
 	}
 
+	// Take the poster from the configured image instead of cutting the cover
+	posterDone := p.sourcedPoster(ctx, data, filepath.Join(outputPath, thumbPath), filepath.Join(outputPath, posterPath))
+
 	// Perform image cutting/cropping (same logic as scraping mode)
 	fullThumbPath := filepath.Join(outputPath, thumbPath)
 	logger.Debug("Image cutting check: ImageCut=%d, AlwaysImagecut=%v", data.ImageCut, p.config.Face.AlwaysImagecut)
 	
 	// Check if this is FC2 content - FC2 numbers don't need image cutting
 	isFC2 := strings.HasPrefix(strings.ToUpper(data.Number), "FC2")
-	if isFC2 {
+	if posterDone {
+		logger.Debug("Poster already in place, skipping image cutting: %s", data.Number)
+	} else if isFC2 {
 		logger.Debug("Skipping image cutting for FC2 content: %s", data.Number)
 		// For FC2, copy the same image to poster path (fanart, thumb, poster are the same)
 		if fullThumbPath != "" && posterPath != "" {
@@ -1055,6 +1400,9 @@ func (p *Processor) processAnalysisModeWithFragment(ctx context.Context, filePat
 		if p.config.ActorPhoto.DownloadForKodi && len(data.ActorPhoto) > 0 {
 			p.downloader.DownloadActorPhotos(ctx, data.ActorPhoto, outputPath)
 		}
+		if p.config.ActorPhoto.PersonNFO {
+			p.nfoGen.GeneratePersonNFOs(data, outputPath)
+		}
 	}
 
 	// Generate NFO with fragment information (filename must match video file exactly in mode 3)
@@ -1070,55 +1418,42 @@ func (p *Processor) processAnalysisModeWithFragment(ctx context.Context, filePat
 func (p *Processor) processAnalysisMode(ctx context.Context, filePath string, data *scraper.MovieData, part string, leak, chineseSubtitle, hack, fourK, iso, uncensored bool) error {
 	outputPath := filepath.Dir(filePath)
 
+	// Nothing is moved in this mode, so a dry run has nothing to plan
+	if p.config.Common.DryRun {
+		logger.Info("[DRY RUN] Would scrape in place: %s", filePath)
+		return nil
+	}
+
 	// Generate file names (same logic as scraping mode)
 	ext := utils.GetImageExtension(data.Cover)
-	var fanartPath, posterPath, thumbPath string
-	
-	if p.config.NameRule.ImageNamingWithNumber {
-		leakWord := ""
-		if leak {
-			leakWord = "-leak"
-		}
-		cWord := ""
-		if chineseSubtitle && !hack && !leak {
-			cWord = "-C"
-		}
-		hackWord := ""
-		if hack {
-			hackWord = "-hack"
-		}
-		
-		prefix := data.Number + leakWord + cWord + hackWord
-		fanartPath = prefix + "-fanart" + ext
-		posterPath = prefix + "-poster" + ext
-		thumbPath = prefix + "-thumb" + ext
-	} else {
-		fanartPath = "fanart" + ext
-		posterPath = "poster" + ext
-		thumbPath = "thumb" + ext
-	}
+	fanartPath, posterPath, thumbPath := p.imageFileNames(data, leak, chineseSubtitle, hack, ext)
 
 	// Download images (same as scraping mode)
 	if data.Cover != "" {
 		fullThumbPath := filepath.Join(outputPath, thumbPath)
-		err := p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.Headers)
+		err := p.downloader.DownloadCover(ctx, data.Cover, fullThumbPath, data.CoverRequestHeaders())
 		if err != nil {
 			logger.Warn("Failed to download cover: %v", err)
 		}
 
-		if p.config.Common.Jellyfin == 0 {
+		if p.config.GetFanartSource() == "cover" {
 			fullFanartPath := filepath.Join(outputPath, fanartPath)
-			p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.Headers)
+			p.downloader.DownloadCover(ctx, data.Cover, fullFanartPath, data.CoverRequestHeaders())
 		}
 	}
 
+	// Take the poster from the configured image instead of cutting the cover
+	posterDone := p.sourcedPoster(ctx, data, filepath.Join(outputPath, thumbPath), filepath.Join(outputPath, posterPath))
+
 	// Perform image cutting/cropping (same logic as scraping mode)
 	fullThumbPath := filepath.Join(outputPath, thumbPath)
 	logger.Debug("Image cutting check: ImageCut=%d, AlwaysImagecut=%v", data.ImageCut, p.config.Face.AlwaysImagecut)
 	
 	// Check if this is FC2 content - FC2 numbers don't need image cutting
 	isFC2 := strings.HasPrefix(strings.ToUpper(data.Number), "FC2")
-	if isFC2 {
+	if posterDone {
+		logger.Debug("Poster already in place, skipping image cutting: %s", data.Number)
+	} else if isFC2 {
 		logger.Debug("Skipping image cutting for FC2 content: %s", data.Number)
 		// For FC2, copy the same image to poster path (fanart, thumb, poster are the same)
 		if fullThumbPath != "" && posterPath != "" {
@@ -1187,6 +1522,9 @@ func (p *Processor) processAnalysisMode(ctx context.Context, filePath string, da
 		if p.config.ActorPhoto.DownloadForKodi && len(data.ActorPhoto) > 0 {
 			p.downloader.DownloadActorPhotos(ctx, data.ActorPhoto, outputPath)
 		}
+		if p.config.ActorPhoto.PersonNFO {
+			p.nfoGen.GeneratePersonNFOs(data, outputPath)
+		}
 	}
 
 	// Generate NFO (filename must match video file exactly in mode 3)
@@ -1206,10 +1544,42 @@ func (p *Processor) handleFailedFile(filePath string) {
 	}
 }
 
+// recordFailureReasons writes the per-source failure breakdown of a failed scrape
+// to the failed folder when Common.FailureReasons is set
+func (p *Processor) recordFailureReasons(filePath, number string, err error) {
+	var scrapeErr *scraper.ScrapeError
+	if !p.config.Common.FailureReasons || !errors.As(err, &scrapeErr) || len(scrapeErr.Failures) == 0 {
+		return
+	}
+	if err := p.storage.AddFailureReasons(filePath, number, scrapeErr.Summary()); err != nil {
+		logger.Warn("Failed to record failure reasons for %s: %v", filePath, err)
+	}
+}
+
+// validateMovieData checks the required fields of a scrape. With Common.StrictValidation
+// an invalid scrape is an error and the reason is written to failed_reasons.txt,
+// otherwise it is only logged and processing continues
+func (p *Processor) validateMovieData(filePath, number string, data *scraper.MovieData) error {
+	err := data.Validate()
+	if err == nil {
+		return nil
+	}
+	if !p.config.Common.StrictValidation {
+		logger.Warn("Scraped data for %s looks incomplete: %v", number, err)
+		return nil
+	}
+
+	reason := fmt.Sprintf("%s: %s (%v)", data.Source, scraper.ReasonInvalidData, err)
+	if recordErr := p.storage.AddFailureReasons(filePath, number, reason); recordErr != nil {
+		logger.Warn("Failed to record failure reasons for %s: %v", filePath, recordErr)
+	}
+	return fmt.Errorf("rejected data from %s: %w", data.Source, err)
+}
+
 // cleanupEmptyFolders removes empty directories
 func (p *Processor) cleanupEmptyFolders() {
-	if p.config.Common.SuccessOutputFolder != "" {
-		err := p.storage.RemoveEmptyFolders(p.config.Common.SuccessOutputFolder)
+	for _, root := range p.config.OutputRoots() {
+		err := p.storage.RemoveEmptyFolders(root)
 		if err != nil {
 			logger.Warn("Failed to cleanup success folder: %v", err)
 		}
@@ -1230,6 +1600,252 @@ func (p *Processor) cleanupEmptyFolders() {
 	}
 }
 
+// probe returns ffprobe information for the file, or nil if ffprobe is unavailable or fails.
+// Results are cached so that several features can share a single ffprobe run.
+func (p *Processor) probe(filePath string) *mediainfo.Info {
+	p.proberOnce.Do(func() {
+		p.proberOK = p.prober.Available()
+		if !p.proberOK {
+			logger.Warn("ffprobe not found, media probing features are disabled")
+		}
+	})
+	if !p.proberOK {
+		return nil
+	}
+
+	p.probeMu.Lock()
+	info, ok := p.probeCache[filePath]
+	p.probeMu.Unlock()
+	if ok {
+		return info
+	}
+
+	info, err := p.prober.Probe(filePath)
+	if err != nil {
+		logger.Warn("Failed to probe %s: %v", filepath.Base(filePath), err)
+		info = nil
+	}
+
+	p.probeMu.Lock()
+	p.probeCache[filePath] = info
+	p.probeMu.Unlock()
+	return info
+}
+
+// detectChineseSubtitle sets the Chinese subtitle flag when the filename has no -C marker
+// but a Chinese external subtitle or embedded subtitle track is present
+func (p *Processor) detectChineseSubtitle(filePath string, flags *utils.MovieFlags) {
+	mode := p.config.Media.ChineseSubtitleDetect
+	if mode <= 0 || flags.ChineseSubtitle {
+		return
+	}
+
+	for _, subtitleFile := range p.storage.FindSubtitleFiles(filePath) {
+		if storage.IsChineseSubtitle(subtitleFile) {
+			logger.Info("Chinese subtitle found: %s", filepath.Base(subtitleFile))
+			flags.ChineseSubtitle = true
+			return
+		}
+	}
+
+	if mode >= 2 {
+		if info := p.probe(filePath); info != nil && info.HasChineseSubtitle() {
+			logger.Info("Embedded Chinese subtitle track found: %s", filepath.Base(filePath))
+			flags.ChineseSubtitle = true
+		}
+	}
+}
+
+// applyResolution probes the video file and records its resolution as a tag
+func (p *Processor) applyResolution(filePath string, data *scraper.MovieData) {
+	if p.config.Media.TagResolution <= 0 {
+		return
+	}
+
+	info := p.probe(filePath)
+	if info == nil {
+		return
+	}
+
+	resolution := info.Resolution()
+	if resolution == "" {
+		return
+	}
+	data.Resolution = resolution
+	for _, tag := range data.Tag {
+		if strings.EqualFold(tag, resolution) {
+			return
+		}
+	}
+	data.Tag = append(data.Tag, resolution)
+	logger.Debug("Detected resolution %s for %s", resolution, filepath.Base(filePath))
+}
+
+// applyLocationTokens fills the resolution, codec and source_flag values used by
+// name_rule.location_rule. The video is only probed when the rule needs the
+// resolution or codec; without ffprobe the filename markers are used instead.
+func (p *Processor) applyLocationTokens(filePath string, data *scraper.MovieData, flags utils.MovieFlags) {
+	data.SourceFlag = strings.TrimPrefix(getFileSuffix(flags.Leak, flags.ChineseSubtitle, flags.Hack), "-")
+
+	needResolution := data.Resolution == "" && p.storage.UsesLocationToken("resolution")
+	needCodec := p.storage.UsesLocationToken("codec")
+	if !needResolution && !needCodec {
+		return
+	}
+
+	if info := p.probe(filePath); info != nil {
+		if needResolution {
+			data.Resolution = info.Resolution()
+		}
+		if needCodec {
+			data.Codec = mediainfo.CodecLabel(info.Codec)
+		}
+		return
+	}
+
+	if needResolution && flags.FourK {
+		data.Resolution = "2160p"
+	}
+	if needCodec {
+		data.Codec = mediainfo.CodecFromName(filepath.Base(filePath))
+	}
+}
+
+// sourcedPoster places the poster according to Image.PosterSource and reports
+// whether it is done; otherwise the poster is cut from the cover as before.
+// Without a poster source the small cover is used when the source asks for it.
+func (p *Processor) sourcedPoster(ctx context.Context, data *scraper.MovieData, thumbPath, posterPath string) bool {
+	return placeSourcedPoster(ctx, p.config, p.downloader, p.imageProcessor, data, thumbPath, posterPath)
+}
+
+// placeSourcedPoster is the poster selection shared by processing and --refresh poster
+func placeSourcedPoster(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, ip *imageprocessor.ImageProcessor, data *scraper.MovieData, thumbPath, posterPath string) bool {
+	switch cfg.GetPosterSource() {
+	case "cut":
+		return false
+	case "cover":
+		if err := ip.CopyImage(thumbPath, posterPath); err != nil {
+			logger.Warn("Failed to use cover as poster: %v", err)
+			return false
+		}
+		return true
+	case "cover_small":
+		if data.CoverSmall == "" {
+			logger.Debug("No small cover for %s, cutting the cover instead", data.Number)
+			return false
+		}
+		if err := dl.DownloadCover(ctx, data.CoverSmall, posterPath, data.CoverRequestHeaders()); err != nil {
+			logger.Warn("Failed to download small cover: %v", err)
+			return false
+		}
+		return true
+	default:
+		return downloadSmallCoverPoster(ctx, cfg, dl, data, posterPath)
+	}
+}
+
+// downloadSmallCoverPoster downloads CoverSmall as the poster when the source marks it
+// with imagecut 3 or is listed in Face.SmallCoverPoster. It reports whether the
+// poster is in place, in which case image cutting must not overwrite it.
+func downloadSmallCoverPoster(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, data *scraper.MovieData, posterPath string) bool {
+	if data.CoverSmall == "" || (data.ImageCut != 3 && !cfg.UseSmallCoverPoster(data.Source)) {
+		return false
+	}
+
+	// Some sources (DMM) report the full cover as the small one, downloading it
+	// would just give an uncropped poster
+	if sameImageURL(data.CoverSmall, data.Cover) && cfg.Face.SameSmallCover != "download" {
+		logger.Debug("Small cover of %s is the full cover, cropping it instead", data.Number)
+		if data.ImageCut == 3 {
+			data.ImageCut = 1
+		}
+		return false
+	}
+
+	if err := dl.DownloadCover(ctx, data.CoverSmall, posterPath, data.CoverRequestHeaders()); err != nil {
+		logger.Warn("Failed to download small cover: %v", err)
+		return false
+	}
+	return true
+}
+
+// imageFileNames returns the fanart, poster and thumb file names of a movie.
+// Art types configured for number naming get the "<number><suffix>-" prefix,
+// the others use plain names such as poster.jpg.
+func (p *Processor) imageFileNames(data *scraper.MovieData, leak, chineseSubtitle, hack bool, ext string) (fanart, poster, thumb string) {
+	leakWord := ""
+	if leak {
+		leakWord = "-leak"
+	}
+	cWord := ""
+	if chineseSubtitle && !hack && !leak {
+		cWord = "-C"
+	}
+	hackWord := ""
+	if hack {
+		hackWord = "-hack"
+	}
+	prefix := data.Number + leakWord + cWord + hackWord + "-"
+
+	name := func(kind string) string {
+		if p.config.ImageNamedWithNumber(kind) {
+			return prefix + kind + ext
+		}
+		return kind + ext
+	}
+	return name("fanart"), name("poster"), name("thumb")
+}
+
+// sameImageURL reports whether two image URLs point to the same file, ignoring the scheme
+func sameImageURL(a, b string) bool {
+	trim := func(u string) string {
+		u = strings.TrimSpace(u)
+		if i := strings.Index(u, "//"); i >= 0 {
+			u = u[i+2:]
+		}
+		return u
+	}
+	return a != "" && strings.EqualFold(trim(a), trim(b))
+}
+
+// releaseYearRegex matches a release date starting with its year (2023-01-05)
+var releaseYearRegex = regexp.MustCompile(`^\d{4}(?:[-/.]|$)`)
+
+// applyYearSource reconciles the scraped year with a year found in the filename.
+// A disagreement is always logged; NameRule.YearSource decides which one is used.
+// The filename year replaces the year of the release date too, so the NFO stays consistent.
+func (p *Processor) applyYearSource(filePath string, data *scraper.MovieData) {
+	fileYear := utils.GetYearFromFilename(filePath, data.Number)
+	if fileYear == "" || fileYear == data.Year {
+		return
+	}
+
+	if data.Year == "" || strings.EqualFold(p.config.NameRule.YearSource, "filename") {
+		logger.Warn("Year mismatch for %s: filename says %s, %s says %q; using filename year", data.Number, fileYear, data.Source, data.Year)
+		data.Year = fileYear
+		if releaseYearRegex.MatchString(data.Release) {
+			data.Release = fileYear + data.Release[4:]
+		}
+		return
+	}
+	logger.Warn("Year mismatch for %s: filename says %s, %s says %s; using scraped year", data.Number, fileYear, data.Source, data.Year)
+}
+
+// resolutionSuffix returns the filename suffix for the detected resolution, if enabled
+func (p *Processor) resolutionSuffix(data *scraper.MovieData) string {
+	if p.config.Media.TagResolution < 2 || data.Resolution == "" {
+		return ""
+	}
+	return "-" + data.Resolution
+}
+
+// videoFileName generates the destination filename for a movie, including the
+// resolution suffix when enabled
+func (p *Processor) videoFileName(data *scraper.MovieData, part string, leak, chineseSubtitle, hack bool, ext string) string {
+	name := generateFileName(data.Number, part, leak, chineseSubtitle, hack, "")
+	return name + p.resolutionSuffix(data) + ext
+}
+
 // generateFileName generates the destination filename
 func generateFileName(number, part string, leak, chineseSubtitle, hack bool, ext string) string {
 	leakWord := ""
@@ -1270,10 +1886,49 @@ func getFileSuffix(leak, chineseSubtitle, hack bool) string {
 	return leakWord + cWord + hackWord
 }
 
+// outputFolder returns the folder a processed movie was placed in, or "" if it cannot be resolved
+func (p *Processor) outputFolder(filePath string, data *scraper.MovieData) string {
+	if p.config.Common.MainMode == 3 {
+		return filepath.Dir(filePath)
+	}
+	outputPath, err := p.storage.CreateFolder(data)
+	if err != nil {
+		logger.Warn("Failed to resolve output folder of %s: %v", data.Number, err)
+		return ""
+	}
+	return outputPath
+}
+
+// indexMovie records a successfully processed movie placed in folder in the library index
+func (p *Processor) indexMovie(folder string, data *scraper.MovieData) {
+	if p.library == nil || folder == "" || p.config.Common.DryRun {
+		return
+	}
+
+	entry := library.Entry{
+		Number:  data.Number,
+		Title:   data.Title,
+		Actors:  data.ActorList,
+		Studio:  data.Studio,
+		Release: data.Release,
+		Source:  data.Source,
+		Path:    folder,
+	}
+	if err := p.library.Record(entry); err != nil {
+		logger.Warn("Failed to update library index for %s: %v", data.Number, err)
+	}
+}
+
 // Close cleans up processor resources
 func (p *Processor) Close() error {
 	var errs []error
 
+	if p.library != nil {
+		if err := p.library.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if p.scraper != nil {
 		if err := p.scraper.Close(); err != nil {
 			errs = append(errs, err)
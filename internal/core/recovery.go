@@ -0,0 +1,136 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/recovery"
+)
+
+// DefaultRecoveryFile is the state file used by -resume when common.recovery_file is not set
+const DefaultRecoveryFile = "recovery_state.json"
+
+// Checkpoint steps recorded for each file of a folder run
+const (
+	checkpointDone   = "done"
+	checkpointFailed = "failed"
+)
+
+// runRecovery checkpoints every finished file of a folder run so that an
+// interrupted run can be resumed without redoing completed files
+type runRecovery struct {
+	manager   *recovery.RecoveryManager
+	processID string
+}
+
+// newRunRecovery opens the recovery state in stateFile for the run over sourceFolder
+func newRunRecovery(stateFile, sourceFolder string) (*runRecovery, error) {
+	cfg := recovery.DefaultRecoveryConfig()
+	cfg.StateFile = stateFile
+	cfg.BackupDir = stateFile + ".backups"
+	cfg.MaxBackups = 3
+	cfg.AutoRecovery = false
+
+	manager, err := recovery.NewRecoveryManager(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recovery state: %w", err)
+	}
+
+	abs, err := filepath.Abs(sourceFolder)
+	if err != nil {
+		abs = sourceFolder
+	}
+	return &runRecovery{manager: manager, processID: "folder:" + abs}, nil
+}
+
+// begin starts or resumes the run and returns the files that still need processing.
+// Files checkpointed as done by an unfinished previous run are left out.
+func (r *runRecovery) begin(movieList []string) []string {
+	state, err := r.manager.SnapshotProcess(r.processID)
+	if err != nil || state.Status == recovery.StatusCompleted {
+		r.manager.CreateProcess(r.processID, "folder processing", len(movieList))
+		r.manager.UpdateProcess(r.processID, func(s *recovery.ProcessState) {
+			s.Status = recovery.StatusRunning
+		})
+		return movieList
+	}
+
+	done := make(map[string]bool)
+	for _, checkpoint := range state.Checkpoints {
+		if file, ok := checkpoint.Data["file"].(string); ok && checkpoint.Step == checkpointDone {
+			done[file] = true
+		}
+	}
+
+	remaining := make([]string, 0, len(movieList))
+	for _, path := range movieList {
+		if !done[path] {
+			remaining = append(remaining, path)
+		}
+	}
+	logger.Info("Resuming previous run from %s: %d file(s) already done, %d remaining",
+		state.LastUpdate.Format("2006-01-02 15:04:05"), len(movieList)-len(remaining), len(remaining))
+
+	r.manager.UpdateProcess(r.processID, func(s *recovery.ProcessState) {
+		s.Status = recovery.StatusRunning
+		s.TotalSteps = s.CompletedSteps + len(remaining)
+	})
+	return remaining
+}
+
+// record checkpoints the outcome of one processed movie
+func (r *runRecovery) record(result ProcessResult) {
+	step := checkpointFailed
+	if result.Success || (result.Skipped && !result.Deferred) {
+		step = checkpointDone
+	}
+
+	data := map[string]interface{}{
+		"file":   result.FilePath,
+		"number": result.Number,
+		"time":   time.Now().Format(time.RFC3339),
+	}
+	if result.Destination != "" {
+		data["destination"] = result.Destination
+	}
+	if result.Error != nil {
+		data["error"] = result.Error.Error()
+	}
+
+	if err := r.manager.CreateCheckpoint(r.processID, step, data); err != nil {
+		logger.Warn("Failed to write recovery checkpoint: %v", err)
+		return
+	}
+	r.manager.UpdateProcess(r.processID, func(s *recovery.ProcessState) {
+		s.CurrentStep = result.FilePath
+		if step == checkpointDone {
+			s.CompletedSteps++
+		} else {
+			s.ErrorCount++
+		}
+		if s.TotalSteps > 0 {
+			s.Progress = float64(s.CompletedSteps) / float64(s.TotalSteps)
+		}
+	})
+}
+
+// finish marks the run as completed, or paused when files were left for a later run
+func (r *runRecovery) finish(interrupted bool) {
+	r.manager.UpdateProcess(r.processID, func(s *recovery.ProcessState) {
+		if interrupted {
+			s.Status = recovery.StatusPaused
+		} else {
+			s.Status = recovery.StatusCompleted
+		}
+	})
+	if err := r.manager.Close(); err != nil {
+		logger.Warn("Failed to save recovery state: %v", err)
+	}
+}
+
+// snapshot returns the current state of the run for the stats server
+func (r *runRecovery) snapshot() (*recovery.ProcessState, error) {
+	return r.manager.SnapshotProcess(r.processID)
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/downloader"
+	"movie-data-capture/pkg/imageprocessor"
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/nfo"
+)
+
+// Artwork kinds that can be regenerated with --refresh
+const (
+	ArtPoster      = "poster"
+	ArtFanart      = "fanart"
+	ArtThumb       = "thumb"
+	ArtExtrafanart = "extrafanart"
+)
+
+// refreshKinds lists the artwork kinds in the order they are regenerated;
+// thumb comes first because posters and fanart are derived from it
+var refreshKinds = []string{ArtThumb, ArtFanart, ArtPoster, ArtExtrafanart}
+
+// ParseRefreshKinds parses a comma separated list of artwork kinds; "all" selects every kind
+func ParseRefreshKinds(value string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		switch kind {
+		case "":
+			continue
+		case "all":
+			for _, k := range refreshKinds {
+				selected[k] = true
+			}
+		case ArtPoster, ArtFanart, ArtThumb, ArtExtrafanart:
+			selected[kind] = true
+		default:
+			return nil, fmt.Errorf("unknown artwork kind %q, must be one of: %v or all", kind, refreshKinds)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no artwork kind given")
+	}
+
+	kinds := make([]string, 0, len(selected))
+	for _, kind := range refreshKinds {
+		if selected[kind] {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// RefreshResult summarizes a refresh run
+type RefreshResult struct {
+	Movies    int
+	Refreshed int
+	Failed    int
+}
+
+// Refresher regenerates artwork of an organized library from its NFO files,
+// leaving artwork kinds that were not selected untouched
+type Refresher struct {
+	config         *config.Config
+	downloader     *downloader.Downloader
+	imageProcessor *imageprocessor.ImageProcessor
+	scraper        *scraper.Scraper
+}
+
+// NewRefresher creates a new refresher instance
+func NewRefresher(cfg *config.Config) *Refresher {
+	return &Refresher{
+		config:         cfg,
+		downloader:     downloader.New(cfg),
+		imageProcessor: imageprocessor.NewImageProcessor(cfg),
+	}
+}
+
+// Refresh regenerates the given artwork kinds for every NFO under root.
+// Posters follow Image.PosterSource like processing (cut from the local thumb by default); thumb and fanart are downloaded again from
+// the cover URL recorded in the NFO; extrafanart needs the movie to be scraped again.
+func (r *Refresher) Refresh(root string, kinds []string) (*RefreshResult, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+
+	paths, err := nfo.FindNFOFiles(root, r.config.Extrafanart.ExtrafanartFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	logger.Info("Refreshing %s for %d NFO file(s)", strings.Join(kinds, ", "), len(paths))
+
+	result := &RefreshResult{}
+	// Parts of a multi-part movie share their artwork, regenerate it once
+	done := make(map[string]bool)
+
+	failed := nfo.ParseEach(paths, r.config.Common.NFOParseWorkers, func(path string, movie *nfo.Movie) {
+		result.Movies++
+		dir := filepath.Dir(path)
+		for _, kind := range kinds {
+			key := kind + "\x00" + dir
+			if done[key] {
+				continue
+			}
+			done[key] = true
+
+			refreshed, err := r.refreshArt(kind, dir, movie)
+			if err != nil {
+				logger.Warn("Failed to refresh %s in %s: %v", kind, dir, err)
+				result.Failed++
+			} else if refreshed {
+				result.Refreshed++
+			}
+		}
+		if result.Movies%100 == 0 {
+			logger.Info("Refreshing [%d/%d]", result.Movies, len(paths))
+		}
+	})
+	result.Failed += failed
+
+	return result, nil
+}
+
+// refreshArt regenerates one artwork kind of a movie folder; it reports false when
+// there was nothing to do
+func (r *Refresher) refreshArt(kind, dir string, movie *nfo.Movie) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	switch kind {
+	case ArtThumb:
+		if movie.Thumb == "" || movie.Cover == "" || !r.needsRefresh(filepath.Join(dir, movie.Thumb)) {
+			return false, nil
+		}
+		return true, r.downloader.DownloadCover(ctx, movie.Cover, filepath.Join(dir, movie.Thumb), nil)
+
+	case ArtFanart:
+		if movie.Fanart == "" || !r.needsRefresh(filepath.Join(dir, movie.Fanart)) {
+			return false, nil
+		}
+		fanartPath := filepath.Join(dir, movie.Fanart)
+		if movie.Cover != "" {
+			if err := r.downloader.DownloadCover(ctx, movie.Cover, fanartPath, nil); err == nil {
+				return true, nil
+			}
+		}
+		// Fall back to the local thumb, which is the same image
+		if movie.Thumb == "" {
+			return false, fmt.Errorf("no cover URL or thumb to build fanart from")
+		}
+		return true, r.imageProcessor.CopyImage(filepath.Join(dir, movie.Thumb), fanartPath)
+
+	case ArtPoster:
+		if movie.Poster == "" || !r.needsRefresh(filepath.Join(dir, movie.Poster)) {
+			return false, nil
+		}
+		source := movie.Thumb
+		if source == "" {
+			source = movie.Fanart
+		}
+		posterPath := filepath.Join(dir, movie.Poster)
+		if r.sourcedPoster(ctx, movie, filepath.Join(dir, source), posterPath) {
+			return true, nil
+		}
+		if source == "" {
+			return false, fmt.Errorf("no thumb or fanart to cut the poster from")
+		}
+		skipFaceRec := r.config.Face.UncensoredOnly && !nfoIsUncensored(movie)
+		return true, r.imageProcessor.CutImage(1, filepath.Join(dir, source), posterPath, skipFaceRec)
+
+	case ArtExtrafanart:
+		data, err := r.fetchMovie(movie)
+		if err != nil {
+			return false, err
+		}
+		if data == nil || len(data.Extrafanart) == 0 {
+			return false, nil
+		}
+		return true, r.downloader.DownloadExtrafanart(ctx, data.Extrafanart, dir, data.Headers)
+	}
+	return false, fmt.Errorf("unknown artwork kind %q", kind)
+}
+
+// sourcedPoster places the poster like processing does (Image.PosterSource) and
+// reports whether it is done. The NFO has no small cover URL, so the movie is
+// scraped again only when the small cover may be used.
+func (r *Refresher) sourcedPoster(ctx context.Context, movie *nfo.Movie, thumbPath, posterPath string) bool {
+	data := &scraper.MovieData{Number: movie.Number, Cover: movie.Cover}
+	switch source := r.config.GetPosterSource(); {
+	case source == "cut":
+		return false
+	case source == "cover_small" || (source == "" && len(r.config.Face.SmallCoverPoster) > 0):
+		scraped, err := r.fetchMovie(movie)
+		if err != nil {
+			logger.Warn("No small cover for %s: %v", movie.Number, err)
+			return false
+		}
+		if scraped != nil {
+			data = scraped
+		}
+	}
+	return placeSourcedPoster(ctx, r.config, r.downloader, r.imageProcessor, data, thumbPath, posterPath)
+}
+
+// fetchMovie scrapes the movie of an NFO again by its number
+func (r *Refresher) fetchMovie(movie *nfo.Movie) (*scraper.MovieData, error) {
+	if movie.Number == "" {
+		return nil, fmt.Errorf("NFO has no number")
+	}
+	if r.scraper == nil {
+		r.scraper = scraper.New(r.config)
+	}
+	data, err := r.scraper.GetDataFromNumber(movie.Number, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape %s: %w", movie.Number, err)
+	}
+	return data, nil
+}
+
+// needsRefresh reports whether an artwork file should be regenerated. With
+// Common.DownloadOnlyMissingImages only missing or corrupt files are replaced.
+func (r *Refresher) needsRefresh(path string) bool {
+	if !r.config.Common.DownloadOnlyMissingImages {
+		return true
+	}
+	return r.imageProcessor.VerifyImage(path) != nil
+}
+
+// nfoIsUncensored reports whether the NFO was written for an uncensored movie
+func nfoIsUncensored(movie *nfo.Movie) bool {
+	for _, tag := range append(movie.Tags, movie.Genres...) {
+		if tag == "无码" || strings.EqualFold(tag, "uncensored") {
+			return true
+		}
+	}
+	return false
+}
+
+// Close releases the resources held by the refresher
+func (r *Refresher) Close() error {
+	if r.scraper != nil {
+		r.scraper.Close()
+	}
+	return r.downloader.Close()
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/nfo"
+	"movie-data-capture/pkg/utils"
+)
+
+// MainModeRefreshNFO is the main mode that regenerates the NFOs of an organized
+// library in place, without downloading images or moving any file
+const MainModeRefreshNFO = 4
+
+// RefreshNFOs regenerates every NFO under root from freshly scraped (or cached)
+// metadata. Each NFO is overwritten at its current path; the artwork paths and
+// multi-part details recorded in the old NFO are kept, and images, videos and
+// folders are left untouched. NFOs whose movie cannot be scraped are kept as is.
+func (p *Processor) RefreshNFOs(root string) (*RefreshResult, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+
+	paths, err := nfo.FindNFOFiles(root, p.config.Extrafanart.ExtrafanartFolder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	logger.Info("Regenerating %d NFO file(s) in %s", len(paths), root)
+
+	result := &RefreshResult{}
+	// Parts of a multi-part movie are scraped once
+	scraped := make(map[string]*scraper.MovieData)
+
+	failed := nfo.ParseEach(paths, p.config.Common.NFOParseWorkers, func(path string, movie *nfo.Movie) {
+		result.Movies++
+		if err := p.refreshNFO(path, movie, scraped); err != nil {
+			logger.Warn("Failed to regenerate %s: %v", path, err)
+			result.Failed++
+		} else {
+			result.Refreshed++
+		}
+		if result.Movies%100 == 0 {
+			logger.Info("Regenerating NFOs [%d/%d]", result.Movies, len(paths))
+		}
+	})
+	result.Failed += failed
+
+	return result, nil
+}
+
+// RefreshNFOForVideo regenerates the NFO next to an organized video (or the NFO
+// itself when videoPath is one), leaving the video and its artwork untouched
+func (p *Processor) RefreshNFOForVideo(videoPath string) error {
+	path := videoPath
+	if !strings.EqualFold(filepath.Ext(path), ".nfo") {
+		path = strings.TrimSuffix(path, filepath.Ext(path)) + ".nfo"
+	}
+
+	movie, err := nfo.Parse(path)
+	if err != nil {
+		return err
+	}
+	return p.refreshNFO(path, movie, make(map[string]*scraper.MovieData))
+}
+
+// refreshNFO regenerates a single NFO at path from the data of the movie it describes
+func (p *Processor) refreshNFO(path string, movie *nfo.Movie, scraped map[string]*scraper.MovieData) error {
+	number := strings.TrimSpace(movie.Number)
+	if number == "" {
+		number = utils.GetNumberFromFilenameWithConfig(filepath.Base(path), p.config)
+	}
+	if number == "" {
+		return fmt.Errorf("no number in the NFO or its file name")
+	}
+
+	data, ok := scraped[strings.ToUpper(number)]
+	if !ok {
+		fetched, err := p.scrapeMovie(context.Background(), number, "", "")
+		if err != nil {
+			return fmt.Errorf("failed to scrape %s: %w", number, err)
+		}
+		if fetched == nil {
+			return fmt.Errorf("no data found for %s", number)
+		}
+		data = p.translateAndCache(context.Background(), fetched)
+		scraped[strings.ToUpper(number)] = data
+	}
+
+	if p.config.Common.DryRun {
+		logger.Info("[DRY RUN] Would regenerate %s", path)
+		return nil
+	}
+
+	// The suffixes of the NFO name carry the flags the movie was organized with
+	flags := utils.ParseMovieFlags(path)
+	uncensored := data.Uncensored || utils.IsUncensored(number, p.config)
+
+	return p.nfoGen.GenerateNFO(data, path, flags.Part, flags.ChineseSubtitle, flags.Leak, uncensored, flags.Hack, flags.FourK, flags.ISO,
+		data.ActorList, movie.Poster, movie.Thumb, movie.Fanart, movie.IsMultiPart, movie.TotalParts, movie.CurrentPart, movie.FragmentFiles, movie.TotalFileSize)
+}
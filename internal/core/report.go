@@ -0,0 +1,212 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+)
+
+// DefaultLowConfidenceThreshold is used when Common.LowConfidenceThreshold is not set
+const DefaultLowConfidenceThreshold = 0.6
+
+// ReportEntry is the outcome of one processed movie
+type ReportEntry struct {
+	FilePath      string  `json:"file_path"`
+	Number        string  `json:"number"`
+	Source        string  `json:"source,omitempty"`
+	Success       bool    `json:"success"`
+	Skipped       bool    `json:"skipped,omitempty"`
+	Deferred      bool    `json:"deferred,omitempty"`
+	Existing      bool    `json:"existing,omitempty"`
+	Error         string  `json:"error,omitempty"`
+	Confidence    float64 `json:"confidence"`
+	LowConfidence bool    `json:"low_confidence,omitempty"`
+	TitleMismatch string  `json:"title_mismatch,omitempty"`
+	// SourceFailures lists why each source failed when no source had the movie, e.g. "dmm: region-blocked"
+	SourceFailures []string `json:"source_failures,omitempty"`
+}
+
+// RunReport collects per-movie results of a processing run. It is safe for concurrent use.
+type RunReport struct {
+	mu        sync.Mutex
+	config    *config.Config
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Entries   []ReportEntry `json:"entries"`
+	// Unprocessed counts movies not started because the run hit Common.MaxDuration
+	Unprocessed int `json:"unprocessed,omitempty"`
+}
+
+// NewRunReport creates an empty report
+func NewRunReport(cfg *config.Config) *RunReport {
+	return &RunReport{
+		config:    cfg,
+		StartTime: time.Now(),
+	}
+}
+
+// lowConfidenceThreshold returns the configured threshold for flagging results
+func (r *RunReport) lowConfidenceThreshold() float64 {
+	if r.config.Common.LowConfidenceThreshold > 0 {
+		return r.config.Common.LowConfidenceThreshold
+	}
+	return DefaultLowConfidenceThreshold
+}
+
+// Add records a process result
+func (r *RunReport) Add(result ProcessResult) {
+	entry := ReportEntry{
+		FilePath:      result.FilePath,
+		Number:        result.Number,
+		Source:        result.Source,
+		Success:       result.Success,
+		Skipped:       result.Skipped,
+		Deferred:      result.Deferred,
+		Existing:      result.Existing,
+		Confidence:    result.Confidence,
+		TitleMismatch: result.TitleMismatch,
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+		for _, failure := range scraper.SourceFailures(result.Error) {
+			entry.SourceFailures = append(entry.SourceFailures, failure.String())
+		}
+	}
+	if result.Success && result.Confidence < r.lowConfidenceThreshold() {
+		entry.LowConfidence = true
+	}
+
+	r.mu.Lock()
+	r.Entries = append(r.Entries, entry)
+	r.mu.Unlock()
+}
+
+// SetUnprocessed records how many queued movies were left for a later run
+func (r *RunReport) SetUnprocessed(n int) {
+	r.mu.Lock()
+	r.Unprocessed = n
+	r.mu.Unlock()
+}
+
+// LowConfidence returns successful entries whose confidence is below the threshold,
+// lowest first
+func (r *RunReport) LowConfidence() []ReportEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []ReportEntry
+	for _, entry := range r.Entries {
+		if entry.LowConfidence {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Confidence < entries[j].Confidence
+	})
+	return entries
+}
+
+// Finish marks the end of the run, logs a summary and writes the report file if configured
+func (r *RunReport) Finish() {
+	r.mu.Lock()
+	r.EndTime = time.Now()
+	r.mu.Unlock()
+
+	r.Log()
+
+	if path := r.config.Common.ReportFile; path != "" {
+		if err := r.WriteFile(path); err != nil {
+			logger.Warn("Failed to write run report: %v", err)
+		} else {
+			logger.Info("Run report written to %s", path)
+		}
+	}
+}
+
+// Log prints the report summary
+func (r *RunReport) Log() {
+	lowConfidence := r.LowConfidence()
+	if len(lowConfidence) > 0 {
+		lines := make([]string, 0, len(lowConfidence))
+		for _, entry := range lowConfidence {
+			lines = append(lines, fmt.Sprintf("%-14s %.2f  %-12s %s", entry.Number, entry.Confidence, entry.Source, filepath.Base(entry.FilePath)))
+		}
+		logger.MultiLineLog(logger.WARN, fmt.Sprintf("Low confidence matches (< %.2f), please verify manually", r.lowConfidenceThreshold()), lines)
+	}
+
+	mismatches := r.TitleMismatches()
+	if len(mismatches) > 0 {
+		lines := make([]string, 0, len(mismatches))
+		for _, entry := range mismatches {
+			lines = append(lines, fmt.Sprintf("%-14s %-12s %s", entry.Number, entry.Source, entry.TitleMismatch))
+		}
+		logger.MultiLineLog(logger.WARN, "Sources disagree on the title, possible mismatch", lines)
+	}
+
+	unresolved := r.Unresolved()
+	if len(unresolved) > 0 {
+		lines := make([]string, 0, len(unresolved))
+		for _, entry := range unresolved {
+			lines = append(lines, fmt.Sprintf("%-14s %s", entry.Number, strings.Join(entry.SourceFailures, "; ")))
+		}
+		logger.MultiLineLog(logger.WARN, "Numbers no source could resolve", lines)
+	}
+}
+
+// Unresolved returns failed entries with the per-source failure breakdown
+func (r *RunReport) Unresolved() []ReportEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []ReportEntry
+	for _, entry := range r.Entries {
+		if len(entry.SourceFailures) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// TitleMismatches returns entries whose title differs significantly from another source
+func (r *RunReport) TitleMismatches() []ReportEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []ReportEntry
+	for _, entry := range r.Entries {
+		if entry.TitleMismatch != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// WriteFile writes the report as JSON
+func (r *RunReport) WriteFile(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
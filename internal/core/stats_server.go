@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+)
+
+// StatsServer exposes the progress of a running processor over HTTP
+//
+//	GET /stats     processed/failed/skipped/existing counters
+//	GET /recovery  per-file checkpoints of the current run (needs common.recovery_file)
+//	GET /metrics   the counters, per-source scrape latency and image cache hits in Prometheus format
+type StatsServer struct {
+	processor *Processor
+	server    *http.Server
+}
+
+// NewStatsServer creates a stats server for processor listening on addr
+func NewStatsServer(addr string, processor *Processor) *StatsServer {
+	s := &StatsServer{processor: processor}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", s.handleStats)
+	mux.HandleFunc("/recovery", s.handleRecovery)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Start begins listening in the background
+func (s *StatsServer) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	logger.Info("Stats server listening on http://%s", listener.Addr())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("Stats server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Close shuts the server down, waiting briefly for in-flight requests
+func (s *StatsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// statsResponse is the body of /stats
+type statsResponse struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	Existing  int `json:"existing"`
+}
+
+// counters returns a snapshot of the processor counters
+func (s *StatsServer) counters() statsResponse {
+	p := s.processor
+	p.processMux.Lock()
+	defer p.processMux.Unlock()
+	return statsResponse{
+		Total:     p.total,
+		Processed: p.processed,
+		Failed:    p.failed,
+		Skipped:   p.skipped,
+		Existing:  p.existing,
+	}
+}
+
+func (s *StatsServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.counters())
+}
+
+func (s *StatsServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	counters := s.counters()
+	metric("mdc_movies_total", "gauge", "Movies queued in the current run.")
+	fmt.Fprintf(&b, "mdc_movies_total %d\n", counters.Total)
+	metric("mdc_movies_processed_total", "counter", "Movies processed successfully.")
+	fmt.Fprintf(&b, "mdc_movies_processed_total %d\n", counters.Processed)
+	metric("mdc_movies_failed_total", "counter", "Movies that failed.")
+	fmt.Fprintf(&b, "mdc_movies_failed_total %d\n", counters.Failed)
+	metric("mdc_movies_skipped_total", "counter", "Movies skipped.")
+	fmt.Fprintf(&b, "mdc_movies_skipped_total %d\n", counters.Skipped)
+	metric("mdc_movies_existing_total", "counter", "Movies skipped because they are already in the library.")
+	fmt.Fprintf(&b, "mdc_movies_existing_total %d\n", counters.Existing)
+
+	sources := s.processor.scraper.SourceStats()
+	metric("mdc_scrape_requests_total", "counter", "Scrape attempts per source and result.")
+	for _, stats := range sources {
+		fmt.Fprintf(&b, "mdc_scrape_requests_total{source=%q,result=\"success\"} %d\n", stats.Source, stats.Success)
+		fmt.Fprintf(&b, "mdc_scrape_requests_total{source=%q,result=\"failure\"} %d\n", stats.Source, stats.Failure)
+	}
+	metric("mdc_scrape_duration_seconds", "histogram", "Scrape latency per source.")
+	for _, stats := range sources {
+		for i, bound := range scraper.ScrapeLatencyBuckets {
+			fmt.Fprintf(&b, "mdc_scrape_duration_seconds_bucket{source=%q,le=\"%g\"} %d\n", stats.Source, bound, stats.Buckets[i])
+		}
+		fmt.Fprintf(&b, "mdc_scrape_duration_seconds_bucket{source=%q,le=\"+Inf\"} %d\n", stats.Source, stats.Count)
+		fmt.Fprintf(&b, "mdc_scrape_duration_seconds_sum{source=%q} %g\n", stats.Source, stats.Sum)
+		fmt.Fprintf(&b, "mdc_scrape_duration_seconds_count{source=%q} %d\n", stats.Source, stats.Count)
+	}
+
+	hits, misses := s.processor.downloader.CacheStats()
+	metric("mdc_image_cache_hits_total", "counter", "Images served from the image cache.")
+	fmt.Fprintf(&b, "mdc_image_cache_hits_total %d\n", hits)
+	metric("mdc_image_cache_misses_total", "counter", "Images downloaded because they were not cached or had changed.")
+	fmt.Fprintf(&b, "mdc_image_cache_misses_total %d\n", misses)
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	metric("mdc_image_cache_hit_ratio", "gauge", "Share of image downloads served from the cache.")
+	fmt.Fprintf(&b, "mdc_image_cache_hit_ratio %g\n", ratio)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	io.WriteString(w, b.String())
+}
+
+func (s *StatsServer) handleRecovery(w http.ResponseWriter, r *http.Request) {
+	p := s.processor
+	p.processMux.Lock()
+	rec := p.recovery
+	p.processMux.Unlock()
+
+	if rec == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "recovery is not enabled for this run"})
+		return
+	}
+
+	state, err := rec.snapshot()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"process_id": rec.processID,
+		"status":     state.Status.String(),
+		"state":      state,
+	})
+}
+
+// writeJSON writes v as an indented JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		logger.Debug("Failed to write stats response: %v", err)
+	}
+}
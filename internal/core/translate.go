@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/translator"
+)
+
+// newTranslator creates the configured translator, falling back to a no-op one
+func newTranslator(p *Processor) translator.Translator {
+	t, err := translator.New(p.config)
+	if err != nil {
+		logger.Warn("Translation disabled: %v", err)
+		return translator.NopTranslator{}
+	}
+	return t
+}
+
+// translateMovie translates the fields listed in translate.values into translate.target_language.
+// The scraped title stays in OriginalTitle; a failed field keeps its scraped text.
+// Actor names are left alone since they key actor photos and aliases.
+func (p *Processor) translateMovie(ctx context.Context, data *scraper.MovieData) {
+	if !p.config.Translate.Switch {
+		return
+	}
+
+	fields := translator.Fields(p.config)
+	translate := func(field string, text *string) {
+		if !fields[field] || *text == "" {
+			return
+		}
+		translated, err := p.translator.Translate(ctx, *text, "", p.config.Translate.TargetLang)
+		if err != nil {
+			logger.Warn("Failed to translate %s of %s: %v", field, data.Number, err)
+			return
+		}
+		*text = translated
+	}
+
+	if data.OriginalTitle == "" {
+		data.OriginalTitle = data.Title
+	}
+	translate("title", &data.Title)
+	translate("outline", &data.Outline)
+	translate("series", &data.Series)
+	translate("studio", &data.Studio)
+	translate("director", &data.Director)
+	for i := range data.Tag {
+		translate("tag", &data.Tag[i])
+	}
+
+	p.scraper.RefreshNaming(data)
+}
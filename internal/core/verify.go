@@ -0,0 +1,260 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/imageprocessor"
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/nfo"
+)
+
+// Verify issue kinds
+const (
+	IssueMissing  = "missing"
+	IssueCorrupt  = "corrupt"
+	IssueNFO      = "nfo"
+	IssueRepaired = "repaired"
+)
+
+// artKinds are the artwork files expected next to every NFO
+var artKinds = []string{"poster", "fanart", "thumb"}
+
+// imageExtensions are the extensions considered when looking for artwork
+var imageExtensions = []string{".jpg", ".jpeg", ".png"}
+
+// VerifyIssue describes a problem found in an organized movie folder
+type VerifyIssue struct {
+	Folder string
+	Path   string
+	Kind   string
+	Detail string
+}
+
+// VerifyResult summarizes a verify run
+type VerifyResult struct {
+	Checked int
+	Issues  []VerifyIssue
+}
+
+// Verifier checks organized movie folders for missing or corrupt files
+type Verifier struct {
+	config         *config.Config
+	imageProcessor *imageprocessor.ImageProcessor
+
+	// Repair fixes trivial NFO problems (control characters, missing title) in place
+	Repair bool
+}
+
+// NewVerifier creates a new verifier instance
+func NewVerifier(cfg *config.Config) *Verifier {
+	return &Verifier{
+		config:         cfg,
+		imageProcessor: imageprocessor.NewImageProcessor(cfg),
+	}
+}
+
+// Verify walks root and checks every folder containing an NFO.
+// Artwork is decoded so that files which exist but are corrupt are reported too.
+func (v *Verifier) Verify(root string) (*VerifyResult, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to access %s: %w", root, err)
+	}
+
+	folders := make(map[string]bool)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			logger.Debug("Skipping %s: %v", path, err)
+			return nil
+		}
+		if info.IsDir() {
+			// Actor photos and extrafanart are not checked
+			if info.Name() == ".actors" || info.Name() == v.config.Extrafanart.ExtrafanartFolder {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".nfo") {
+			folders[filepath.Dir(path)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	dirs := make([]string, 0, len(folders))
+	for dir := range folders {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	return v.verifyFolders(dirs), nil
+}
+
+// verifyWorkers returns the number of folders checked concurrently
+func (v *Verifier) verifyWorkers() int {
+	if v.config.Common.VerifyWorkers > 0 {
+		return v.config.Common.VerifyWorkers
+	}
+	return runtime.NumCPU()
+}
+
+// verifyFolders checks dirs with a bounded pool of workers, logging progress as
+// folders complete. Issues are reported in the order of dirs.
+func (v *Verifier) verifyFolders(dirs []string) *VerifyResult {
+	workers := v.verifyWorkers()
+	if workers > len(dirs) {
+		workers = len(dirs)
+	}
+
+	issues := make([][]VerifyIssue, len(dirs))
+	jobs := make(chan int)
+	done := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				issues[index] = v.verifyFolder(dirs[index])
+				done <- struct{}{}
+			}
+		}()
+	}
+
+	go func() {
+		for index := range dirs {
+			jobs <- index
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	// Report progress roughly every 5% and at least every 10 seconds
+	step := len(dirs) / 20
+	if step < 1 {
+		step = 1
+	}
+	lastLog := time.Now()
+	checked := 0
+	for range done {
+		checked++
+		if checked%step == 0 || checked == len(dirs) || time.Since(lastLog) >= 10*time.Second {
+			logger.Info("Verifying [%.1f%% %d/%d]", float64(checked)/float64(len(dirs))*100, checked, len(dirs))
+			lastLog = time.Now()
+		}
+	}
+
+	result := &VerifyResult{Checked: len(dirs)}
+	for _, folderIssues := range issues {
+		result.Issues = append(result.Issues, folderIssues...)
+	}
+	return result
+}
+
+// verifyFolder checks the artwork of a single movie folder
+func (v *Verifier) verifyFolder(dir string) []VerifyIssue {
+	var issues []VerifyIssue
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []VerifyIssue{{Folder: dir, Path: dir, Kind: IssueMissing, Detail: err.Error()}}
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".nfo") {
+			issues = append(issues, v.verifyNFO(dir, filepath.Join(dir, entry.Name()))...)
+		}
+	}
+
+	for _, kind := range v.expectedArtKinds() {
+		var found []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			name := strings.ToLower(entry.Name())
+			ext := filepath.Ext(name)
+			if !isImageExtension(ext) {
+				continue
+			}
+			base := strings.TrimSuffix(name, ext)
+			if base == kind || strings.HasSuffix(base, "-"+kind) {
+				found = append(found, filepath.Join(dir, entry.Name()))
+			}
+		}
+
+		if len(found) == 0 {
+			issues = append(issues, VerifyIssue{Folder: dir, Path: filepath.Join(dir, kind+".jpg"), Kind: IssueMissing, Detail: kind + " not found"})
+			continue
+		}
+
+		for _, path := range found {
+			if err := v.imageProcessor.VerifyImage(path); err != nil {
+				issues = append(issues, VerifyIssue{Folder: dir, Path: path, Kind: IssueCorrupt, Detail: err.Error()})
+			}
+		}
+	}
+
+	return issues
+}
+
+// verifyNFO checks an NFO against the schema, repairing it when enabled
+func (v *Verifier) verifyNFO(dir, path string) []VerifyIssue {
+	problems, err := nfo.ValidateFile(path)
+	if err != nil {
+		return []VerifyIssue{{Folder: dir, Path: path, Kind: IssueNFO, Detail: err.Error()}}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if v.Repair {
+		repaired, err := nfo.Repair(path)
+		if err == nil && repaired {
+			details := make([]string, len(problems))
+			for i, problem := range problems {
+				details[i] = problem.String()
+			}
+			return []VerifyIssue{{Folder: dir, Path: path, Kind: IssueRepaired, Detail: strings.Join(details, "; ")}}
+		}
+		if err != nil {
+			logger.Debug("NFO not repaired: %v", err)
+		}
+	}
+
+	issues := make([]VerifyIssue, len(problems))
+	for i, problem := range problems {
+		issues[i] = VerifyIssue{Folder: dir, Path: path, Kind: IssueNFO, Detail: problem.String()}
+	}
+	return issues
+}
+
+// expectedArtKinds returns the artwork kinds the processor writes for the current config
+func (v *Verifier) expectedArtKinds() []string {
+	if v.config.Common.Jellyfin != 0 {
+		// Jellyfin mode does not write a separate fanart file
+		return []string{"poster", "thumb"}
+	}
+	return artKinds
+}
+
+// isImageExtension reports whether ext is a supported artwork extension
+func isImageExtension(ext string) bool {
+	for _, e := range imageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
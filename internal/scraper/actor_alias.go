@@ -0,0 +1,156 @@
+package scraper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"movie-data-capture/pkg/logger"
+)
+
+// ActorAlias 演员别名文件中的一条记录
+//
+// 示例：
+//
+//	# actor_alias.yaml
+//	- name: "Yui"               # 统一使用的演员名
+//	  aliases: ["ゆい", "由衣"]  # 视为同一人的其他名字
+//	- name: "Aoi"
+//	  id: "1024"                # 同名演员的区分后缀，输出为 "Aoi (1024)"
+//	  photo_match: "aoi_1024"   # 演员照片URL中包含该字符串时匹配此记录
+//	- name: "Aoi"
+//	  id: "2048"
+//	  studios: ["S1"]           # 片商匹配时使用此记录
+type ActorAlias struct {
+	Name       string   `yaml:"name"`
+	Aliases    []string `yaml:"aliases"`
+	ID         string   `yaml:"id"`
+	PhotoMatch string   `yaml:"photo_match"`
+	Studios    []string `yaml:"studios"`
+}
+
+// DisplayName 返回带区分后缀的演员名
+func (a *ActorAlias) DisplayName() string {
+	if a.ID == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, a.ID)
+}
+
+// matches 判断该记录是否适用于给定的照片URL和片商
+func (a *ActorAlias) matches(photoURL, studio string) bool {
+	if a.PhotoMatch != "" && !strings.Contains(photoURL, a.PhotoMatch) {
+		return false
+	}
+	if len(a.Studios) > 0 {
+		found := false
+		for _, s := range a.Studios {
+			if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(studio)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ActorAliases 按名字（含别名）索引的演员别名表
+type ActorAliases struct {
+	byName map[string][]*ActorAlias
+}
+
+// LoadActorAliases 从YAML文件加载演员别名表
+func LoadActorAliases(path string) (*ActorAliases, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actor alias file: %w", err)
+	}
+
+	var entries []*ActorAlias
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse actor alias file: %w", err)
+	}
+
+	aliases := &ActorAliases{byName: make(map[string][]*ActorAlias)}
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.Name) == "" {
+			continue
+		}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			key := actorAliasKey(name)
+			if key != "" {
+				aliases.byName[key] = append(aliases.byName[key], entry)
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// actorAliasKey 规范化演员名用于查找
+func actorAliasKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Resolve 返回演员应使用的名字；未匹配时返回原名
+// 同名的多条记录中，优先选择带有匹配条件且条件满足的记录
+func (a *ActorAliases) Resolve(name, photoURL, studio string) string {
+	if a == nil {
+		return name
+	}
+
+	candidates := a.byName[actorAliasKey(name)]
+	var fallback *ActorAlias
+	for _, entry := range candidates {
+		if entry.PhotoMatch == "" && len(entry.Studios) == 0 {
+			if fallback == nil {
+				fallback = entry
+			}
+			continue
+		}
+		if entry.matches(photoURL, studio) {
+			return entry.DisplayName()
+		}
+	}
+
+	if fallback != nil {
+		return fallback.DisplayName()
+	}
+	return name
+}
+
+// applyActorAliases 根据别名表统一演员名，并为同名演员加上区分后缀
+// 演员列表、演员字符串和演员照片的键会同时更新，保证文件夹和 .actors 照片不冲突
+func (s *Scraper) applyActorAliases(data *MovieData) {
+	if s.actorAliases == nil || len(data.ActorList) == 0 {
+		return
+	}
+
+	photos := make(map[string]string, len(data.ActorPhoto))
+	seen := make(map[string]bool, len(data.ActorList))
+	actors := make([]string, 0, len(data.ActorList))
+	for _, actor := range data.ActorList {
+		photoURL := data.ActorPhoto[actor]
+		resolved := s.actorAliases.Resolve(actor, photoURL, data.Studio)
+		if resolved != actor {
+			logger.Debug("Actor alias applied: %s -> %s", actor, resolved)
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		actors = append(actors, resolved)
+		if photoURL != "" {
+			photos[resolved] = photoURL
+		}
+	}
+
+	data.ActorList = actors
+	data.Actor = strings.Join(actors, ",")
+	if data.ActorPhoto != nil {
+		data.ActorPhoto = photos
+	}
+}
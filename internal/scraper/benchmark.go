@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"movie-data-capture/pkg/httpclient"
+	"movie-data-capture/pkg/logger"
+)
+
+// DefaultBenchmarkNumbers 未指定番号时 BenchmarkSources 使用的固定番号，覆盖常见有码、素人和无码作品
+var DefaultBenchmarkNumbers = []string{
+	"SSIS-001",
+	"IPX-177",
+	"ABP-984",
+	"MIDE-800",
+	"STARS-080",
+	"FC2-1292936",
+	"HEYZO-0783",
+	"010120-001",
+}
+
+// BenchmarkResult 一个数据源的测试结果
+type BenchmarkResult struct {
+	Source    string
+	Attempts  int
+	Successes int
+	// Latency 所有请求（含失败）的总耗时
+	Latency time.Duration
+	// Completeness 成功结果的字段完整度之和，除以 Successes 得到平均值
+	Completeness float64
+}
+
+// SuccessRate 返回成功率（0-1）
+func (r BenchmarkResult) SuccessRate() float64 {
+	if r.Attempts == 0 {
+		return 0
+	}
+	return float64(r.Successes) / float64(r.Attempts)
+}
+
+// AvgLatency 返回平均每个番号的耗时
+func (r BenchmarkResult) AvgLatency() time.Duration {
+	if r.Attempts == 0 {
+		return 0
+	}
+	return r.Latency / time.Duration(r.Attempts)
+}
+
+// AvgCompleteness 返回成功结果的平均字段完整度（0-1）
+func (r BenchmarkResult) AvgCompleteness() float64 {
+	if r.Successes == 0 {
+		return 0
+	}
+	return r.Completeness / float64(r.Successes)
+}
+
+// BenchmarkSources 依次用每个数据源抓取 numbers，统计耗时、成功率和字段完整度
+// 结果按成功率、完整度从高到低，耗时从低到高排序
+func (s *Scraper) BenchmarkSources(numbers, sources []string) []BenchmarkResult {
+	results := make([]BenchmarkResult, 0, len(sources))
+	for _, source := range sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" {
+			continue
+		}
+
+		result := BenchmarkResult{Source: source}
+		for _, number := range numbers {
+			ctx, cancel := context.WithTimeout(context.Background(), s.movieTimeBudget())
+			ctx = httpclient.WithRetryBudget(ctx, s.config.Scraper.MovieRetryBudget)
+
+			start := time.Now()
+			data, err := s.scrapeFromSource(ctx, source, number, "")
+			elapsed := time.Since(start)
+			cancel()
+
+			result.Attempts++
+			result.Latency += elapsed
+			if err != nil || data == nil || data.Title == "" {
+				logger.Debug("Benchmark %s %s: failed after %v (%s)", source, number, elapsed.Round(time.Millisecond), FailureReason(err))
+				continue
+			}
+			result.Successes++
+			result.Completeness += fieldCompleteness(data)
+			logger.Debug("Benchmark %s %s: ok in %v", source, number, elapsed.Round(time.Millisecond))
+		}
+		logger.Info("Benchmarked %s: %d/%d found", source, result.Successes, result.Attempts)
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.SuccessRate() != b.SuccessRate() {
+			return a.SuccessRate() > b.SuccessRate()
+		}
+		if a.AvgCompleteness() != b.AvgCompleteness() {
+			return a.AvgCompleteness() > b.AvgCompleteness()
+		}
+		return a.AvgLatency() < b.AvgLatency()
+	})
+	return results
+}
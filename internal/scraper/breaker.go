@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/retry"
+)
+
+// DefaultBreakerReset 未配置 Scraper.BreakerReset 时熔断后跳过数据源的时长
+const DefaultBreakerReset = 5 * time.Minute
+
+// sourceBreakers 为每个数据源维护一个熔断器，连续失败达到阈值后在恢复窗口内直接跳过该数据源
+type sourceBreakers struct {
+	failures int
+	reset    time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*retry.CircuitBreaker
+}
+
+// newSourceBreakers 创建数据源熔断器，failures<=0 时不启用熔断（返回 nil）
+func newSourceBreakers(failures int, reset time.Duration) *sourceBreakers {
+	if failures <= 0 {
+		return nil
+	}
+	if reset <= 0 {
+		reset = DefaultBreakerReset
+	}
+	return &sourceBreakers{
+		failures: failures,
+		reset:    reset,
+		breakers: make(map[string]*retry.CircuitBreaker),
+	}
+}
+
+// get 返回数据源的熔断器，首次使用时创建并记录状态变化
+func (b *sourceBreakers) get(source string) *retry.CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if breaker, ok := b.breakers[source]; ok {
+		return breaker
+	}
+	breaker := retry.NewCircuitBreaker(b.failures, b.reset)
+	breaker.OnStateChange(func(from, to retry.CircuitState) {
+		switch to {
+		case retry.Open:
+			logger.Warn("Circuit breaker for %s: %s -> %s, skipping the source for %v", source, from, to, b.reset)
+		default:
+			logger.Info("Circuit breaker for %s: %s -> %s", source, from, to)
+		}
+	})
+	b.breakers[source] = breaker
+	return breaker
+}
+
+// execute 通过数据源的熔断器执行抓取；熔断器打开时立即返回 retry.ErrCircuitOpen
+// 只有表明站点不可用的错误（网络、超时、屏蔽等）计入连续失败，未找到影片不计入
+func (b *sourceBreakers) execute(ctx context.Context, source string, scrape func() (*MovieData, error)) (*MovieData, error) {
+	if b == nil {
+		return scrape()
+	}
+
+	var data *MovieData
+	var scrapeErr error
+	err := b.get(strings.ToLower(source)).Execute(func() error {
+		data, scrapeErr = scrape()
+		if countsAsSourceFailure(ctx, scrapeErr) {
+			return scrapeErr
+		}
+		return nil
+	})
+	if errors.Is(err, retry.ErrCircuitOpen) {
+		return nil, err
+	}
+	return data, scrapeErr
+}
+
+// countsAsSourceFailure 判断抓取错误是否说明数据源本身不可用（网络、超时、5xx、429、被拦截）
+// 没有该影片等普通失败和影片时间预算用完导致的取消不归咎于数据源
+func countsAsSourceFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	reason := FailureReason(err)
+	return outageReasons[reason] || reason == ReasonBlocked
+}
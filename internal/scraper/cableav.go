@@ -30,7 +30,7 @@ func (s *Scraper) scrapeCableAV(ctx context.Context, number string) (*MovieData,
 		}
 		defer resp.Body.Close()
 
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		doc, err := parseHTML(resp.Body)
 		if err != nil {
 			logger.Debug("Failed to parse search page for %s: %v", searchNumber, err)
 			continue
@@ -74,7 +74,7 @@ func (s *Scraper) scrapeCableAVDetail(ctx context.Context, detailURL, number str
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse detail page: %w", err)
 	}
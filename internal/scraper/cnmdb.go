@@ -28,7 +28,7 @@ func (s *Scraper) scrapeCNMDB(ctx context.Context, number string) (*MovieData, e
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search page: %w", err)
 	}
@@ -82,7 +82,7 @@ func (s *Scraper) scrapeCNMDBDetail(ctx context.Context, detailURL, number strin
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse detail page: %w", err)
 	}
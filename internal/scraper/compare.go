@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+
+	"movie-data-capture/pkg/httpclient"
+	"movie-data-capture/pkg/logger"
+)
+
+// GetDataFromNumberAll 并发用所有启用的数据源抓取番号，按数据源名返回各自的结果，用于对比不同站点的元数据
+// 同时查询的数据源数量由 Scraper.SearchAllConcurrency 限制
+// 未取得数据的数据源对应的值为 nil；所有数据源都失败时返回 *ScrapeError
+func (s *Scraper) GetDataFromNumberAll(number string) (map[string]*MovieData, error) {
+	logger.Info("Searching for movie data on all sources: %s", number)
+
+	sources := s.Sources()
+	found := make([]*MovieData, len(sources))
+	failures := make([]SourceFailure, len(sources))
+
+	concurrency := s.config.Scraper.SearchAllConcurrency
+	if concurrency <= 0 || concurrency > len(sources) {
+		concurrency = len(sources)
+	}
+	slots := make(chan struct{}, max(concurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// 每个数据源单独计算时间和重试预算，慢的站点不影响其他站点
+			ctx, cancel := context.WithTimeout(context.Background(), s.movieTimeBudget())
+			defer cancel()
+			ctx = httpclient.WithRetryBudget(ctx, s.config.Scraper.MovieRetryBudget)
+
+			data, err := s.scrapeFromSource(ctx, source, number, "")
+			switch {
+			case err != nil || data == nil:
+				failures[i] = SourceFailure{Source: source, Reason: FailureReason(err), Err: err}
+			case data.Number == "" || data.Title == "":
+				failures[i] = SourceFailure{Source: source, Reason: ReasonInvalidData, Err: ErrInvalidData}
+			default:
+				s.processMovieData(data)
+				data.Confidence = ComputeConfidence(data, number)
+				found[i] = data
+				return
+			}
+			logger.Debug("No data from %s: %s", source, failures[i].Reason)
+		}(i, source)
+	}
+	wg.Wait()
+
+	results := make(map[string]*MovieData, len(sources))
+	scrapeErr := &ScrapeError{Number: number}
+	for i, source := range sources {
+		results[source] = found[i]
+		if found[i] == nil {
+			scrapeErr.Failures = append(scrapeErr.Failures, failures[i])
+		}
+	}
+	if len(scrapeErr.Failures) == len(sources) {
+		return results, scrapeErr
+	}
+	return results, nil
+}
+
+// SearchCandidate 合并后的一条搜索结果，记录返回了相同番号和标题的所有数据源
+type SearchCandidate struct {
+	Number  string
+	Title   string
+	Sources []string
+	Data    *MovieData // 第一个（按数据源优先级）返回该结果的数据
+}
+
+// MergeCandidates 按番号和标题合并各数据源的结果，标题只有空白、标点或全半角差异时视为相同
+// 候选按 sources 的优先级排列，Sources 也保持该顺序
+func MergeCandidates(sources []string, results map[string]*MovieData) []SearchCandidate {
+	var candidates []SearchCandidate
+	index := make(map[string]int)
+	for _, source := range sources {
+		data := results[source]
+		if data == nil {
+			continue
+		}
+
+		key := numberKey(data.Number) + "\x00" + normalizeTitleForCompare(data.Title, data.Number)
+		if i, ok := index[key]; ok {
+			candidates[i].Sources = append(candidates[i].Sources, source)
+			continue
+		}
+		index[key] = len(candidates)
+		candidates = append(candidates, SearchCandidate{
+			Number:  data.Number,
+			Title:   data.Title,
+			Sources: []string{source},
+			Data:    data,
+		})
+	}
+	return candidates
+}
@@ -0,0 +1,85 @@
+package scraper
+
+import "strings"
+
+// sourceReliability 各数据源的可靠度（0-1），未列出的来源使用 defaultSourceReliability
+var sourceReliability = map[string]float64{
+	"dmm":        1.0,
+	"fanza":      1.0,
+	"mgstage":    0.95,
+	"javbus":     0.9,
+	"javdb":      0.9,
+	"javlibrary": 0.9,
+	"metatube":   0.9,
+	"xcity":      0.85,
+	"fc2":        0.85,
+	"fc2club":    0.8,
+	"jav321":     0.8,
+	"carib":      0.85,
+	"caribpr":    0.85,
+	"javmenu":    0.7,
+	"javday":     0.7,
+	"freejavbt":  0.6,
+}
+
+const defaultSourceReliability = 0.6
+
+// 置信度各部分的权重
+const (
+	confidenceWeightFields = 0.5
+	confidenceWeightNumber = 0.3
+	confidenceWeightSource = 0.2
+)
+
+// fieldCompleteness 返回主要字段（标题、演员、发行日期、封面、片商、时长、简介、标签）的填充比例（0-1）
+func fieldCompleteness(data *MovieData) float64 {
+	fields := []bool{
+		data.Title != "",
+		len(data.ActorList) > 0 || data.Actor != "",
+		data.Release != "",
+		data.Cover != "",
+		data.Studio != "",
+		data.Runtime != "",
+		data.Outline != "",
+		len(data.Tag) > 0,
+	}
+	filled := 0
+	for _, ok := range fields {
+		if ok {
+			filled++
+		}
+	}
+	return float64(filled) / float64(len(fields))
+}
+
+// ComputeConfidence 根据字段完整度、番号匹配程度和数据源可靠度计算置信度（0-1）
+func ComputeConfidence(data *MovieData, requestedNumber string) float64 {
+	if data == nil {
+		return 0
+	}
+
+	completeness := fieldCompleteness(data)
+
+	// 番号匹配程度：完全一致 > 规范化后一致 > 不一致
+	numberMatch := 0.0
+	switch {
+	case requestedNumber == "":
+		numberMatch = 0.5
+	case strings.EqualFold(data.Number, requestedNumber):
+		numberMatch = 1.0
+	case numberKey(data.Number) == numberKey(requestedNumber):
+		numberMatch = 0.7
+	}
+
+	// 数据源可靠度
+	source := strings.ToLower(data.Source)
+	if strings.HasPrefix(source, "metatube") {
+		source = "metatube"
+	}
+	reliability, ok := sourceReliability[source]
+	if !ok {
+		reliability = defaultSourceReliability
+	}
+
+	return completeness*confidenceWeightFields + numberMatch*confidenceWeightNumber + reliability*confidenceWeightSource
+}
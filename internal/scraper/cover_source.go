@@ -0,0 +1,43 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// applyCoverSources 按 Scraper.CoverSources 的顺序尝试从其他数据源获取封面
+// 遇到元数据来源本身时保留现有封面；全部失败时同样保留
+func (s *Scraper) applyCoverSources(ctx context.Context, data *MovieData, number string) {
+	for _, source := range s.config.Scraper.CoverSources {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		if strings.EqualFold(source, data.Source) {
+			return
+		}
+
+		other, err := s.scrapeFromSource(ctx, source, number, "")
+		if err != nil || other == nil || other.Cover == "" {
+			logger.Debug("No cover from %s for %s: %v", source, number, err)
+			continue
+		}
+		if numberKey(other.Number) != numberKey(number) {
+			logger.Debug("Cover source %s returned %s for %s, ignored", source, other.Number, number)
+			continue
+		}
+
+		// 小封面一并替换（可能为空，此时海报从新封面裁剪），保证封面和海报来自同一来源
+		data.Cover = s.transformURL(other.Source, other.Cover)
+		data.CoverSmall = s.transformURL(other.Source, other.CoverSmall)
+		// 部分站点的图片需要特定的 Referer，封面按其来源的请求头下载，剧照等仍使用元数据来源的请求头
+		data.CoverHeaders = map[string]string{}
+		for name, value := range other.Headers {
+			data.CoverHeaders[name] = value
+		}
+		logger.Info("Using cover from %s for %s (metadata from %s)", other.Source, number, data.Source)
+		return
+	}
+}
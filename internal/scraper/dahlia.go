@@ -25,7 +25,7 @@ func (s *Scraper) scrapeDahlia(ctx context.Context, number string) (*MovieData,
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search page: %w", err)
 	}
@@ -65,7 +65,7 @@ func (s *Scraper) scrapeDahliaDetail(ctx context.Context, detailURL, number stri
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse detail page: %w", err)
 	}
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
-	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
 )
 
@@ -30,12 +29,21 @@ func (s *Scraper) scrapeDMM(ctx context.Context, number string) (*MovieData, err
 		fmt.Sprintf("https://www.dmm.co.jp/digital/nikkatsu/-/detail/=/cid=%s/", searchNumber),
 		fmt.Sprintf("https://www.dmm.co.jp/rental/-/detail/=/cid=%s/", searchNumber),
 	}
+	urlFormats = orderDMMURLsByEdition(urlFormats, s.config.Scraper.EditionPreference)
 	
+	var lastErr error
 	for i, url := range urlFormats {
+		// The first request was already throttled by scrapeFromSource
+		if i > 0 {
+			if err := s.waitForSource(ctx, "dmm"); err != nil {
+				return nil, err
+			}
+		}
 		logger.Debug("Trying URL %d/%d: %s", i+1, len(urlFormats), url)
 		movieInfo, err := s.scrapeDMMPage(ctx, url, number)
 		if err != nil {
 			logger.Debug("URL %d failed: %v", i+1, err)
+			lastErr = err
 		} else if movieInfo.Title != "" {
 			logger.Debug("URL %d succeeded, found title: %s", i+1, movieInfo.Title)
 			return movieInfo, nil
@@ -44,20 +52,73 @@ func (s *Scraper) scrapeDMM(ctx context.Context, number string) (*MovieData, err
 		}
 	}
 	
+	if lastErr != nil {
+		return nil, fmt.Errorf("failed to scrape DMM data for number: %s: %w", number, lastErr)
+	}
 	return nil, fmt.Errorf("failed to scrape DMM data for number: %s", number)
 }
 
+// dmmEdition 根据详情页URL判断版本：mono 为 DVD，digital 为配信，rental 为租赁
+func dmmEdition(url string) string {
+	switch {
+	case strings.Contains(url, "/mono/"):
+		return "dvd"
+	case strings.Contains(url, "/digital/"):
+		return "digital"
+	case strings.Contains(url, "/rental/"):
+		return "rental"
+	}
+	return ""
+}
+
+// dmmCIDRegex 匹配详情页URL中的cid
+var dmmCIDRegex = regexp.MustCompile(`cid=([^/&?]+)`)
+
+// dmmCID 返回详情页URL中的cid，没有时返回空
+func dmmCID(url string) string {
+	if m := dmmCIDRegex.FindStringSubmatch(url); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// dmmFloorRegex 匹配详情页URL中的フロア（如 mono/dvd、digital/videoa）
+var dmmFloorRegex = regexp.MustCompile(`dmm\.co\.jp/((?:mono|digital)/[a-z]+|rental)/`)
+
+// dmmFloor 返回详情页所属的フロア，例如 "digital/videoa"
+func dmmFloor(url string) string {
+	if match := dmmFloorRegex.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// orderDMMURLsByEdition 把偏好版本的URL排到前面，其余保持原有顺序
+func orderDMMURLsByEdition(urls []string, preference string) []string {
+	preference = strings.ToLower(strings.TrimSpace(preference))
+	if preference == "" {
+		return urls
+	}
+
+	ordered := make([]string, 0, len(urls))
+	var rest []string
+	for _, url := range urls {
+		if dmmEdition(url) == preference {
+			ordered = append(ordered, url)
+		} else {
+			rest = append(rest, url)
+		}
+	}
+	return append(ordered, rest...)
+}
+
 // scrapeDMMPage scrapes a specific DMM page using scraper's HTTP client
 func (s *Scraper) scrapeDMMPage(ctx context.Context, url, originalNumber string) (*MovieData, error) {
-	// Set age verification cookies for DMM
-	cookies := map[string]string{
-		"age_check_done": "1",
-		"ckcy":          "1", 
-		"cklg":          "ja",
-	}
+	// Set age verification cookies for DMM (scraper.cookies.dmm)
+	cookies := s.config.GetSourceCookies("dmm")
 	
-	// Use the scraper's HTTP client (which has proper proxy configuration)
-	client := httpclient.NewImprovedClient(&s.config.Proxy)
+	// Share one client per host so connections and session cookies are reused across URL formats and movies
+	client := s.clientPool.GetClient(url)
 	
 	err := client.SetCookies(url, cookies)
 	if err != nil {
@@ -92,7 +153,7 @@ func (s *Scraper) scrapeDMMPage(ctx context.Context, url, originalNumber string)
 		reader = gzReader
 	}
 
-	doc, err := goquery.NewDocumentFromReader(reader)
+	doc, err := parseHTML(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -111,28 +172,16 @@ func (s *Scraper) scrapeDMMPage(ctx context.Context, url, originalNumber string)
 	if strings.Contains(body, "このページはお住まいの地域からご利用になれません") ||
 	   strings.Contains(body, "Sorry! This content is not available in your region") ||
 	   strings.Contains(body, "not-available-in-your-region") {
-		return nil, fmt.Errorf("region restriction detected - DMM/FANZA blocks access from your location")
+		return nil, fmt.Errorf("DMM/FANZA blocks access from your location: %w", ErrRegionBlocked)
 	}
 	
 	// Check for age verification
 	if strings.Contains(body, "年齢認証") || strings.Contains(body, "Age Verification") {
-		return nil, fmt.Errorf("age verification required")
+		return nil, ErrAgeVerification
 	}
 	
-	// Check if page has valid content - use meta tag approach like Python version
-	hasValidContent := false
-	doc.Find("meta[property='og:title']").Each(func(i int, s *goquery.Selection) {
-		if content, exists := s.Attr("content"); exists && content != "" {
-			hasValidContent = true
-		}
-	})
-	
-	if !hasValidContent {
-		// Try other indicators
-		if doc.Find("h1#title").Length() > 0 || doc.Find(".product-title").Length() > 0 {
-			hasValidContent = true
-		}
-	}
+	// Check if page has valid content (og:title, product title or configured markers)
+	hasValidContent := dmmHasContent(doc, s.config.Scraper.DMMContentSelectors)
 	
 	if !hasValidContent {
 		return nil, fmt.Errorf("no valid content found")
@@ -144,9 +193,11 @@ func (s *Scraper) scrapeDMMPage(ctx context.Context, url, originalNumber string)
 		ImageCut: 0, // Default image cut setting
 		Uncensored: false, // DMM is censored content
 	}
+	movieInfo.SetExtra("dmm_edition", dmmEdition(url))
+	movieInfo.SetExtra("dmm_floor", dmmFloor(url))
 	
 	// Extract title
-	movieInfo.Title = extractDMMTitle(doc)
+	movieInfo.Title = extractDMMTitle(doc, originalNumber, s.config.GetTitlePrefixStrip("dmm"))
 	if movieInfo.Title == "" {
 		return nil, fmt.Errorf("title not found")
 	}
@@ -175,7 +226,7 @@ func (s *Scraper) scrapeDMMPage(ctx context.Context, url, originalNumber string)
 	movieInfo.Tag = extractDMMTag(doc)
 	movieInfo.Outline = extractDMMOutline(doc)
 	movieInfo.Extrafanart = extractDMMExtraFanart(doc)
-	movieInfo.Trailer = extractDMMTrailer(doc)
+	movieInfo.Trailer = extractDMMTrailer(doc, s.config.Trailer.Quality, dmmCID(url), originalNumber)
 	
 	logger.Info("Successfully scraped DMM data for: %s", movieInfo.Number)
 	return movieInfo, nil
@@ -194,13 +245,50 @@ func cleanNumberForDMMSearch(number string) string {
 	return searchNumber
 }
 
-// extractDMMTitle extracts title from DMM page
-func extractDMMTitle(doc *goquery.Document) string {
+// dmmContentSelectors mark a DMM product page with real content. Some valid
+// pages have no og:title, so the product title and spec table count as well.
+var dmmContentSelectors = []string{
+	"meta[property='og:title']",
+	"h1#title",
+	".product-title",
+	"h1.item.fn",
+	"[itemprop='name']",
+	"table.mg-b20",
+}
+
+// dmmHasContent reports whether doc looks like a product page: any of the
+// built-in or extra selectors matches an element with text or a content attribute
+func dmmHasContent(doc *goquery.Document, extra []string) bool {
+	for _, selector := range append(dmmContentSelectors, extra...) {
+		selector = strings.TrimSpace(selector)
+		if selector == "" {
+			continue
+		}
+		found := false
+		doc.Find(selector).EachWithBreak(func(i int, sel *goquery.Selection) bool {
+			if content, exists := sel.Attr("content"); exists && strings.TrimSpace(content) != "" {
+				found = true
+			} else if strings.TrimSpace(sel.Text()) != "" {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			logger.Debug("DMM page content detected by %s", selector)
+			return true
+		}
+	}
+	return false
+}
+
+// extractDMMTitle extracts title from DMM page.
+// A leading number is removed according to stripMode (see stripTitleNumberPrefix).
+func extractDMMTitle(doc *goquery.Document, number, stripMode string) string {
 	// First try to get from meta og:title (like Python version)
 	if content, exists := doc.Find("meta[property='og:title']").First().Attr("content"); exists && content != "" {
 		title := strings.TrimSpace(content)
 		// Clean title like Python version
-		title = regexp.MustCompile(`^[A-Z0-9-]+\s*`).ReplaceAllString(title, "")
+		title = stripTitleNumberPrefix(title, number, stripMode)
 		title = regexp.MustCompile(`(?i)\s*-\s*FANZA.*$`).ReplaceAllString(title, "")
 		title = strings.ReplaceAll(title, "\n", " ")
 		title = regexp.MustCompile(`\s+`).ReplaceAllString(title, " ")
@@ -211,6 +299,7 @@ func extractDMMTitle(doc *goquery.Document) string {
 	selectors := []string{
 		"h1#title",
 		"h1.product-title",
+		"[itemprop='name']",
 		"h1",
 	}
 	
@@ -218,7 +307,7 @@ func extractDMMTitle(doc *goquery.Document) string {
 		title := strings.TrimSpace(doc.Find(selector).First().Text())
 		if title != "" {
 			// Clean title like Python version
-			title = regexp.MustCompile(`^[A-Z0-9-]+\s*`).ReplaceAllString(title, "")
+			title = stripTitleNumberPrefix(title, number, stripMode)
 			title = regexp.MustCompile(`(?i)\s*-\s*FANZA.*$`).ReplaceAllString(title, "")
 			title = strings.ReplaceAll(title, "\n", " ")
 			title = regexp.MustCompile(`\s+`).ReplaceAllString(title, " ")
@@ -282,7 +371,7 @@ func extractDMMActorPhoto(actors []string) map[string]string {
 func extractDMMCover(doc *goquery.Document) string {
 	// First try meta og:image (like Python version)
 	if content, exists := doc.Find("meta[property='og:image']").First().Attr("content"); exists && content != "" {
-		return normalizeImageURL(content)
+		return content
 	}
 	
 	// Try other selectors as fallback
@@ -296,22 +385,12 @@ func extractDMMCover(doc *goquery.Document) string {
 	
 	for _, selector := range selectors {
 		if img, exists := doc.Find(selector).First().Attr("src"); exists && img != "" {
-			return normalizeImageURL(img)
+			return img
 		}
 	}
 	return ""
 }
 
-// normalizeImageURL normalizes image URL
-func normalizeImageURL(img string) string {
-	if strings.HasPrefix(img, "//") {
-		return "https:" + img
-	} else if strings.HasPrefix(img, "/") {
-		return "https://www.dmm.co.jp" + img
-	}
-	return img
-}
-
 // extractDMMRelease extracts release date from DMM page
 func extractDMMRelease(doc *goquery.Document) string {
 	selectors := []string{
@@ -336,10 +415,7 @@ func convertDMMDate(dateStr string) string {
 	re := regexp.MustCompile(`(\d{4})[年/](\d{1,2})[月/](\d{1,2})`)
 	matches := re.FindStringSubmatch(dateStr)
 	if len(matches) == 4 {
-		year := matches[1]
-		month := fmt.Sprintf("%02s", matches[2])
-		day := fmt.Sprintf("%02s", matches[3])
-		return fmt.Sprintf("%s-%s-%s", year, month, day)
+		return formatDate(matches[1], matches[2], matches[3])
 	}
 	return dateStr
 }
@@ -503,6 +579,7 @@ func extractDMMOutline(doc *goquery.Document) string {
 }
 
 // extractDMMExtraFanart extracts extra fanart from DMM page
+// Relative URLs and the large image variant are handled by the dmm url_transforms rules
 func extractDMMExtraFanart(doc *goquery.Document) []string {
 	var fanart []string
 	selectors := []string{
@@ -514,9 +591,6 @@ func extractDMMExtraFanart(doc *goquery.Document) []string {
 		doc.Find(selector).Each(func(i int, s *goquery.Selection) {
 			img, exists := s.Attr("src")
 			if exists && img != "" {
-				img = normalizeImageURL(img)
-				// Get large image like Python version - replace '-' with 'jp-'
-				img = strings.Replace(img, "-", "jp-", 1)
 				fanart = append(fanart, img)
 			}
 		})
@@ -528,23 +602,35 @@ func extractDMMExtraFanart(doc *goquery.Document) []string {
 }
 
 // extractDMMTrailer extracts trailer from DMM page
-func extractDMMTrailer(doc *goquery.Document) string {
-	selectors := []string{
-		"#sample-video a",
-		"video",
-	}
-	
-	for _, selector := range selectors {
-		var trailer string
-		if selector == "#sample-video a" {
-			trailer, _ = doc.Find(selector).First().Attr("href")
-		} else {
-			trailer, _ = doc.Find(selector).First().Attr("src")
-		}
-		
+// The sample player may list several qualities, the one matching the preference is returned
+func extractDMMTrailer(doc *goquery.Document, quality, cid, number string) string {
+	var candidates []string
+	add := func(trailer string) {
+		trailer = strings.TrimSpace(trailer)
 		if trailer != "" {
-			return normalizeImageURL(trailer)
+			candidates = append(candidates, trailer)
 		}
 	}
-	return ""
+
+	doc.Find("#sample-video a").Each(func(i int, sel *goquery.Selection) {
+		href, _ := sel.Attr("href")
+		add(href)
+	})
+	doc.Find("video, video source").Each(func(i int, sel *goquery.Selection) {
+		src, _ := sel.Attr("src")
+		add(src)
+	})
+
+	// The player also embeds its bitrate list as JSON in a script; other scripts carry
+	// related movies and ads, so only URLs naming this movie are kept
+	doc.Find("script").Each(func(i int, sel *goquery.Selection) {
+		for _, match := range trailerURLRegex.FindAllString(sel.Text(), -1) {
+			match = strings.ReplaceAll(match, `\/`, "/")
+			if trailerMatchesMovie(match, cid, number) {
+				add(match)
+			}
+		}
+	})
+
+	return selectTrailer(candidates, quality)
 }
\ No newline at end of file
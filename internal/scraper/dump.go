@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/httpclient"
+)
+
+// siteSources 按域名关键字匹配数据源，用于附加该数据源的Cookie（scraper.cookies）
+var siteSources = []struct {
+	hostKeyword string
+	source      string
+}{
+	{"javbus", "javbus"},
+	{"javdb", "javdb"},
+	{"javlibrary", "javlibrary"},
+	{"dmm.co.jp", "dmm"},
+	{"fc2.com", "fc2"},
+	{"mgstage", "mgstage"},
+}
+
+// siteForURL 返回URL对应的数据源名称
+func siteForURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, site := range siteSources {
+		if strings.Contains(host, site.hostKeyword) {
+			return site.source
+		}
+	}
+	return ""
+}
+
+// FetchPage 按抓取器的方式（代理、Cookie、请求头）获取页面，返回解压后的内容和状态码
+// 用于开发调试时查看抓取器实际收到的HTML
+func FetchPage(ctx context.Context, cfg *config.Config, rawURL string) ([]byte, int, error) {
+	client := httpclient.NewClient(&cfg.Proxy)
+	defer client.Close()
+
+	headers := map[string]string{
+		"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		"Accept-Language": "zh-CN,zh;q=0.9,en;q=0.8,ja;q=0.7",
+	}
+	if source := siteForURL(rawURL); source != "" {
+		ctx = httpclient.WithCookies(ctx, cfg.GetSourceCookies(source))
+	}
+
+	// 未设置 Accept-Encoding 时，标准库会自动处理 gzip 解压
+	resp, err := client.Get(ctx, rawURL, headers)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readPage(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
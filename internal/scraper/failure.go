@@ -0,0 +1,166 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"movie-data-capture/pkg/httpclient"
+	"movie-data-capture/pkg/retry"
+)
+
+// 数据源抓取失败的原因分类，用于失败报告中按来源展示
+const (
+	ReasonNotFound        = "not-found"
+	ReasonRegionBlocked   = "region-blocked"
+	ReasonAgeVerification = "age-verification"
+	ReasonBlocked         = "blocked"
+	ReasonRateLimited     = "rate-limited"
+	ReasonTimeout         = "timeout"
+	ReasonBudgetExhausted = "budget-exhausted"
+	ReasonCircuitOpen     = "circuit-open"
+	ReasonNetwork         = "network"
+	ReasonServerError     = "server-error"
+	ReasonInvalidData     = "invalid-data"
+	ReasonError           = "error"
+)
+
+// ErrRegionBlocked 数据源拒绝当前地区访问
+var ErrRegionBlocked = errors.New("region restriction detected")
+
+// ErrAgeVerification 数据源返回了年龄验证页面
+var ErrAgeVerification = errors.New("age verification required")
+
+// ErrInvalidData 数据源返回的数据缺少番号、标题等必填字段或字段无效
+var ErrInvalidData = errors.New("invalid movie data")
+
+// serverErrorRegex 匹配错误信息中的5xx状态码，如 "status code: 503"、"HTTP 502"
+var serverErrorRegex = regexp.MustCompile(`(?:status|http)(?: code| error)?:?\s*5\d\d\b`)
+
+// outageReasons 表示数据源不可用（而不是没有该影片）的失败原因
+var outageReasons = map[string]bool{
+	ReasonNetwork:     true,
+	ReasonTimeout:     true,
+	ReasonRateLimited: true,
+	ReasonServerError: true,
+	ReasonCircuitOpen: true,
+}
+
+// IsOutage 判断抓取失败是否因为数据源不可用（网络错误、5xx、429、已熔断）
+// 只要有数据源明确返回未找到或无效数据，就不算不可用
+func IsOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	failures := SourceFailures(err)
+	if len(failures) == 0 {
+		return outageReasons[FailureReason(err)]
+	}
+
+	outage := false
+	for _, failure := range failures {
+		switch {
+		case failure.Reason == ReasonNotFound || failure.Reason == ReasonInvalidData:
+			return false
+		case outageReasons[failure.Reason]:
+			outage = true
+		}
+	}
+	return outage
+}
+
+// SourceFailure 一个数据源的失败记录
+type SourceFailure struct {
+	Source string
+	Reason string
+	Err    error
+}
+
+// String 返回 "来源: 原因" 形式的简短描述
+func (f SourceFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.Source, f.Reason)
+}
+
+// ScrapeError 所有数据源均未取得数据时返回，记录每个数据源的失败原因
+type ScrapeError struct {
+	Number   string
+	Failures []SourceFailure
+}
+
+// Error 实现 error 接口，附带按来源的失败原因
+func (e *ScrapeError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("no data found for number: %s", e.Number)
+	}
+	return fmt.Sprintf("no data found for number: %s (%s)", e.Number, e.Summary())
+}
+
+// Summary 返回 "dmm: region-blocked; javbus: not-found" 形式的失败明细
+func (e *ScrapeError) Summary() string {
+	parts := make([]string, len(e.Failures))
+	for i, failure := range e.Failures {
+		parts[i] = failure.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SourceFailures 从错误链中取出按来源的失败记录；不是抓取失败时返回 nil
+func SourceFailures(err error) []SourceFailure {
+	var scrapeErr *ScrapeError
+	if errors.As(err, &scrapeErr) {
+		return scrapeErr.Failures
+	}
+	return nil
+}
+
+// FailureReason 将数据源返回的错误归类为失败原因
+func FailureReason(err error) string {
+	if err == nil {
+		return ReasonNotFound
+	}
+
+	switch {
+	case errors.Is(err, ErrRegionBlocked):
+		return ReasonRegionBlocked
+	case errors.Is(err, ErrAgeVerification):
+		return ReasonAgeVerification
+	case errors.Is(err, ErrInvalidData):
+		return ReasonInvalidData
+	case errors.Is(err, retry.ErrCircuitOpen):
+		return ReasonCircuitOpen
+	case errors.Is(err, httpclient.ErrRetryBudgetExhausted):
+		return ReasonBudgetExhausted
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return ReasonTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ReasonTimeout
+		}
+		return ReasonNetwork
+	}
+
+	// 各数据源的错误多为格式化字符串，按常见关键字归类
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "429") || strings.Contains(msg, "too many requests"):
+		return ReasonRateLimited
+	case serverErrorRegex.MatchString(msg):
+		return ReasonServerError
+	case strings.Contains(msg, "403") || strings.Contains(msg, "cloudflare") || strings.Contains(msg, "forbidden"):
+		return ReasonBlocked
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline"):
+		return ReasonTimeout
+	case strings.Contains(msg, "404") || strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "no data") || strings.Contains(msg, "no results") || strings.Contains(msg, "no valid"):
+		return ReasonNotFound
+	case strings.Contains(msg, "connection") || strings.Contains(msg, "no such host"):
+		return ReasonNetwork
+	}
+	return ReasonError
+}
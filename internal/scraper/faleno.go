@@ -33,7 +33,7 @@ func (s *Scraper) scrapeFaleno(ctx context.Context, number string) (*MovieData,
 		}
 		defer resp.Body.Close()
 
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		doc, err := parseHTML(resp.Body)
 		if err != nil {
 			logger.Debug("Failed to parse search page from %s: %v", site, err)
 			continue
@@ -75,7 +75,7 @@ func (s *Scraper) scrapeFalenoDetail(ctx context.Context, detailURL, number stri
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse detail page: %w", err)
 	}
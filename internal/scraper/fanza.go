@@ -3,7 +3,6 @@ package scraper
 import (
 	"context"
 	"fmt"
-	"io"
 	"regexp"
 	"strings"
 
@@ -36,7 +35,13 @@ func (s *Scraper) scrapeFanza(ctx context.Context, number string) (*MovieData, e
 		fmt.Sprintf("https://www.dmm.co.jp/rental/-/detail/=/cid=%s/", fanzaSearchNumber),
 	}
 
-	for _, detailURL := range urlsToTry {
+	for i, detailURL := range urlsToTry {
+		// The first request was already throttled by scrapeFromSource
+		if i > 0 {
+			if err := s.waitForSource(ctx, "fanza"); err != nil {
+				return nil, err
+			}
+		}
 		logger.Debug("Trying Fanza URL: %s", detailURL)
 		movieData, err := s.scrapeFanzaPage(ctx, detailURL, detailURL)
 		if err == nil {
@@ -74,7 +79,7 @@ func (s *Scraper) scrapeFanzaPage(ctx context.Context, ageCheckURL, originalURL
 	}
 	
 	// Read the response body to check content
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPage(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -95,7 +100,7 @@ func (s *Scraper) scrapeFanzaPage(ctx context.Context, ageCheckURL, originalURL
 		return nil, fmt.Errorf("still on age verification page or region blocked")
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(bodyStr))
+	doc, err := parseHTML(strings.NewReader(bodyStr))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -114,8 +119,7 @@ func (s *Scraper) scrapeFanzaPage(ctx context.Context, ageCheckURL, originalURL
 	if title, exists := doc.Find("meta[property='og:title']").Attr("content"); exists {
 		title = strings.TrimSpace(title)
 		// Clean title by removing number and FANZA suffix
-		numberRegex := regexp.MustCompile(`^[A-Z0-9-]+\s*`)
-		title = numberRegex.ReplaceAllString(title, "")
+		title = stripTitleNumberPrefix(title, number, s.config.GetTitlePrefixStrip("fanza"))
 		fanzaRegex := regexp.MustCompile(`\s*-\s*FANZA.*$`)
 		title = fanzaRegex.ReplaceAllString(title, "")
 		movieData.Title = title
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"regexp"
 	"strings"
 
@@ -64,22 +63,8 @@ func (s *Scraper) scrapeFC2(ctx context.Context, number string) (*MovieData, err
 		contentURL = fmt.Sprintf(pattern, fc2Number)
 		logger.Debug("Trying FC2 URL: %s", contentURL)
 		
-		// Set FC2-specific cookies
-		parsedURL, _ := url.Parse(contentURL)
-		if parsedURL != nil {
-			// Add FC2-specific cookies for adult content
-			adultCookie := &http.Cookie{
-				Name:   "adult",
-				Value:  "1",
-				Domain: parsedURL.Hostname(),
-				Path:   "/",
-			}
-			cloudClient.SetCookie(parsedURL, adultCookie)
-		}
-		
-		resp, err = cloudClient.Get(ctx, contentURL, map[string]string{
-			"Cookie": "adult=1; fc2_lang=ja",
-		})
+		// The adult content cookies (scraper.cookies.fc2) are attached through ctx
+		resp, err = cloudClient.Get(ctx, contentURL, nil)
 		if err != nil {
 			logger.Debug("Failed to fetch %s: %v", contentURL, err)
 			// Check if it's a connection error (likely due to FC2 service shutdown)
@@ -107,7 +92,7 @@ func (s *Scraper) scrapeFC2(ctx context.Context, number string) (*MovieData, err
 	defer resp.Body.Close()
 	
 	// Debug: log first 500 characters of HTML content
-     bodyBytes, _ := io.ReadAll(resp.Body)
+     bodyBytes, _ := readPage(resp.Body)
      bodyStr := string(bodyBytes)
      if len(bodyStr) > 500 {
          logger.Debug("FC2 HTML content (first 500 chars): %s...", bodyStr[:500])
@@ -239,7 +224,7 @@ func (s *Scraper) scrapeFC2(ctx context.Context, number string) (*MovieData, err
 			// Extract date in various formats
 			re := regexp.MustCompile(`(\d{4})/(\d{1,2})/(\d{1,2})`)
 			if matches := re.FindStringSubmatch(text); len(matches) > 0 {
-				movieData.Release = fmt.Sprintf("%s-%02s-%02s", matches[1], matches[2], matches[3])
+				movieData.Release = formatDate(matches[1], matches[2], matches[3])
 				movieData.Year = matches[1]
 			}
 		}
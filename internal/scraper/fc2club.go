@@ -26,7 +26,7 @@ func (s *Scraper) scrapeFC2Club(ctx context.Context, number string) (*MovieData,
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search page: %w", err)
 	}
@@ -67,7 +67,7 @@ func (s *Scraper) scrapeFC2ClubDetail(ctx context.Context, detailURL, number str
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse detail page: %w", err)
 	}
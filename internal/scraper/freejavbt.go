@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"movie-data-capture/pkg/httpclient"
 )
 
 // scrapeFreeJavBT scrapes movie data from FreeJavBT website
@@ -19,7 +20,8 @@ func scrapeFreeJavBT(number string) (*MovieData, error) {
 // scrapeFreeJavBTPage scrapes a specific FreeJavBT page
 func scrapeFreeJavBTPage(url, originalNumber string) (*MovieData, error) {
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: httpclient.WrapTransport(nil),
 	}
 	
 	req, err := http.NewRequest("GET", url, nil)
@@ -42,7 +44,7 @@ func scrapeFreeJavBTPage(url, originalNumber string) (*MovieData, error) {
 		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, err
 	}
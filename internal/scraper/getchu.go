@@ -46,7 +46,7 @@ func (g *GetchuScraper) ScrapeByNumber(ctx context.Context, number string) (*Mov
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -81,7 +81,7 @@ func (g *GetchuScraper) ScrapeByURL(ctx context.Context, url string) (*MovieData
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse movie page: %w", err)
 	}
@@ -165,10 +165,7 @@ func (g *GetchuScraper) extractReleaseDate(doc *goquery.Document, data *MovieDat
 			// Extract date from the text
 			dateRegex := regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日`)
 			if matches := dateRegex.FindStringSubmatch(text); len(matches) == 4 {
-				year := matches[1]
-				month := fmt.Sprintf("%02s", matches[2])
-				day := fmt.Sprintf("%02s", matches[3])
-				data.Release = fmt.Sprintf("%s-%s-%s", year, month, day)
+				data.Release = formatDate(matches[1], matches[2], matches[3])
 			}
 		}
 	})
@@ -0,0 +1,93 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 未配置时的页面大小上限和HTML解析超时
+const (
+	DefaultMaxPageSize  = 10 // MB
+	DefaultParseTimeout = 20 * time.Second
+)
+
+// ErrPageTooLarge 页面超过 Scraper.MaxPageSize，拒绝解析
+var ErrPageTooLarge = errors.New("page exceeds size limit")
+
+// 所有数据源共用的页面限制，由 SetPageLimits 设置
+var (
+	maxPageBytes     atomic.Int64
+	pageParseTimeout atomic.Int64
+)
+
+func init() {
+	SetPageLimits(0, 0)
+}
+
+// SetPageLimits 设置单个页面的大小上限（MB）和解析超时，0 表示使用默认值
+func SetPageLimits(maxSizeMB int, timeout time.Duration) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = DefaultMaxPageSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultParseTimeout
+	}
+	maxPageBytes.Store(int64(maxSizeMB) * 1024 * 1024)
+	pageParseTimeout.Store(int64(timeout))
+}
+
+// pageTooLarge 返回带大小上限说明的 ErrPageTooLarge
+func pageTooLarge(limit int64) error {
+	return fmt.Errorf("%w (%d MB)", ErrPageTooLarge, limit/(1024*1024))
+}
+
+// readPage 读取页面内容，超过大小上限时返回 ErrPageTooLarge，防止超大页面耗尽内存
+func readPage(r io.Reader) ([]byte, error) {
+	limit := maxPageBytes.Load()
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, pageTooLarge(limit)
+	}
+	return body, nil
+}
+
+// parseHTML 在大小上限和解析超时的保护下解析HTML页面
+func parseHTML(r io.Reader) (*goquery.Document, error) {
+	limit := maxPageBytes.Load()
+	timeout := time.Duration(pageParseTimeout.Load())
+	limited := &io.LimitedReader{R: r, N: limit + 1}
+
+	type parsed struct {
+		doc *goquery.Document
+		err error
+	}
+	done := make(chan parsed, 1)
+	go func() {
+		doc, err := goquery.NewDocumentFromReader(limited)
+		done <- parsed{doc: doc, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		if result.err != nil {
+			return nil, result.err
+		}
+		if limited.N <= 0 {
+			return nil, pageTooLarge(limit)
+		}
+		return result.doc, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("HTML parsing timed out after %v", timeout)
+	}
+}
@@ -3,7 +3,6 @@ package scraper
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"regexp"
 	"strings"
 	"time"
@@ -12,6 +11,7 @@ import (
 	"movie-data-capture/internal/config"
 	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/random"
 )
 
 // ImprovedJavDBScraper 表示改进的JavDB抓取器
@@ -31,7 +31,7 @@ func (s *Scraper) NewImprovedJavDBScraper() *ImprovedJavDBScraper {
 // ScrapeImprovedJavDB 使用改进方法从JavDB抓取电影数据
 func (s *Scraper) ScrapeImprovedJavDB(ctx context.Context, number string) (*MovieData, error) {
 	// 添加随机延迟以避免被检测为机器人
-	delay := time.Duration(500+rand.Intn(1500)) * time.Millisecond
+	delay := time.Duration(500+random.Intn(1500)) * time.Millisecond
 	time.Sleep(delay)
 	
 	scraper := s.NewImprovedJavDBScraper()
@@ -40,7 +40,7 @@ func (s *Scraper) ScrapeImprovedJavDB(ctx context.Context, number string) (*Movi
 	sites := strings.Split(s.config.Javdb.Sites, ",")
 	
 	// 随机化站点顺序以分散负载
-	rand.Shuffle(len(sites), func(i, j int) {
+	random.Shuffle(len(sites), func(i, j int) {
 		sites[i], sites[j] = sites[j], sites[i]
 	})
 	
@@ -64,7 +64,7 @@ func (s *Scraper) ScrapeImprovedJavDB(ctx context.Context, number string) (*Movi
 			logger.Debug("Failed to scrape JavDB site %s: %v", baseURL, err)
 			
 			// 在尝试下一个站点之前添加延迟
-			time.Sleep(time.Duration(1000+rand.Intn(2000)) * time.Millisecond)
+			time.Sleep(time.Duration(1000+random.Intn(2000)) * time.Millisecond)
 			continue
 		}
 		
@@ -77,6 +77,19 @@ func (s *Scraper) ScrapeImprovedJavDB(ctx context.Context, number string) (*Movi
 	return nil, fmt.Errorf("failed to scrape from any JavDB site")
 }
 
+// scrapeImprovedJavDBURL 直接抓取指定的JavDB影片详情页，跳过搜索
+func (s *Scraper) scrapeImprovedJavDBURL(ctx context.Context, movieURL, number string) (*MovieData, error) {
+	scraper := s.NewImprovedJavDBScraper()
+
+	baseURL := siteBaseURL(movieURL)
+	if err := scraper.initializeSession(ctx, baseURL); err != nil {
+		logger.Debug("Failed to initialize JavDB session: %v", err)
+	}
+	scraper.client.SetCookies(baseURL, s.config.GetSourceCookies("javdb"))
+
+	return scraper.scrapeMoviePageImproved(ctx, movieURL, number)
+}
+
 // scrapeJavDBSiteImproved 使用改进方法从特定JavDB站点抓取
 func (ijs *ImprovedJavDBScraper) scrapeJavDBSiteImproved(ctx context.Context, baseURL, number string) (*MovieData, error) {
 	// 步骤1：首先访问主页初始化会话
@@ -89,13 +102,8 @@ func (ijs *ImprovedJavDBScraper) scrapeJavDBSiteImproved(ctx context.Context, ba
 	searchURL := fmt.Sprintf("%s/search?q=%s&f=all", baseURL, number)
 	logger.Debug("JavDB search URL: %s", searchURL)
 	
-	// 设置通常需要的cookies
-	cookies := map[string]string{
-		"over18": "1",
-		"locale": "zh",
-		"theme":  "auto",
-	}
-	ijs.client.SetCookies(baseURL, cookies)
+	// 设置通常需要的cookies（scraper.cookies.javdb）
+	ijs.client.SetCookies(baseURL, ijs.config.GetSourceCookies("javdb"))
 	
 	// 搜索请求的自定义请求头
 	headers := map[string]string{
@@ -116,7 +124,7 @@ func (ijs *ImprovedJavDBScraper) scrapeJavDBSiteImproved(ctx context.Context, ba
 		return nil, fmt.Errorf("search returned status %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -345,7 +353,7 @@ func (ijs *ImprovedJavDBScraper) scrapeMoviePageImproved(ctx context.Context, mo
 		return nil, fmt.Errorf("movie page returned status %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse movie page: %w", err)
 	}
@@ -3,7 +3,6 @@ package scraper
 import (
 	"context"
 	"fmt"
-	"io"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -57,9 +56,8 @@ func (s *Scraper) scrapeJavBus(ctx context.Context, number string) (*MovieData,
 
 // scrapeJavBusPage 抓取特定的JavBus页面
 func (s *Scraper) scrapeJavBusPage(ctx context.Context, url string, uncensored bool) (*MovieData, error) {
-	// 设置包含年龄验证cookie的请求头（基于Python版本增强）
+	// 年龄验证Cookie由 scraper.cookies.javbus 通过上下文附加
 	headers := map[string]string{
-		"Cookie": "existmag=all; over18=18",
 		"Accept": "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
 		"Accept-Language": "zh-CN,zh;q=0.9,en;q=0.8,ja;q=0.7",
 		"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
@@ -82,7 +80,7 @@ func (s *Scraper) scrapeJavBusPage(ctx context.Context, url string, uncensored b
 	}
 
 	// 读取响应体以检查内容
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPage(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -97,7 +95,7 @@ func (s *Scraper) scrapeJavBusPage(ctx context.Context, url string, uncensored b
 		logger.Debug("JavBus response preview: %s", preview)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	doc, err := parseHTML(strings.NewReader(string(body)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -113,7 +111,7 @@ func (s *Scraper) scrapeJavBusPage(ctx context.Context, url string, uncensored b
 	
 	// 检查年龄验证或其他阻止页面
 	if strings.Contains(pageTitle, "Age Verification") || strings.Contains(pageTitle, "Verification") {
-		return nil, fmt.Errorf("age verification required")
+		return nil, ErrAgeVerification
 	}
 
 	// 提取电影数据
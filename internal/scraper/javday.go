@@ -46,7 +46,7 @@ func (s *Scraper) scrapeJavDayPage(ctx context.Context, url, baseURL string) (*M
 		return nil, fmt.Errorf("JavDay returned status code: %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -3,7 +3,6 @@ package scraper
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/random"
 )
 
 
@@ -18,7 +18,7 @@ import (
 // scrapeJavDB 从JavDB抓取电影数据
 func (s *Scraper) scrapeJavDB(ctx context.Context, number string) (*MovieData, error) {
 	// 添加小延迟以模拟人类行为
-	time.Sleep(time.Duration(500+rand.Intn(1000)) * time.Millisecond)
+	time.Sleep(time.Duration(500+random.Intn(1000)) * time.Millisecond)
 	
 	// 从配置中获取JavDB站点
 	sites := strings.Split(s.config.Javdb.Sites, ",")
@@ -54,7 +54,6 @@ func (s *Scraper) scrapeJavDBSite(ctx context.Context, baseURL, number string) (
 	// 为JavDB设置请求头以模拟真实浏览器并绕过反机器人保护
 	headers := map[string]string{
 		"User-Agent":       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Cookie":          "over18=1; locale=zh",
 		"Accept":           "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
 		"Accept-Language":  "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7",
 		// 不要手动设置Accept-Encoding - 让Go自动处理压缩
@@ -81,7 +80,7 @@ func (s *Scraper) scrapeJavDBSite(ctx context.Context, baseURL, number string) (
 		return nil, fmt.Errorf("search returned status %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -207,7 +206,6 @@ func (s *Scraper) scrapeJavDBMoviePage(ctx context.Context, movieURL, number str
 	// 使用与搜索相同的请求头以保持会话一致性
 	headers := map[string]string{
 		"User-Agent":       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"Cookie":          "over18=1; theme=auto; locale=zh",
 		"Accept":           "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
 		"Accept-Language":  "zh-CN,zh;q=0.9,en;q=0.8",
 		// 不要手动设置Accept-Encoding - 让Go自动处理压缩
@@ -234,7 +232,7 @@ func (s *Scraper) scrapeJavDBMoviePage(ctx context.Context, movieURL, number str
 		return nil, fmt.Errorf("movie page returned status %d", resp.StatusCode)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse movie page: %w", err)
 	}
@@ -55,7 +55,7 @@ func (j *JavMenuScraper) ScrapeByNumber(ctx context.Context, number string) (*Mo
 		return nil, fmt.Errorf("search returned status %d", resp.StatusCode)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -105,7 +105,7 @@ func (j *JavMenuScraper) ScrapeByURL(ctx context.Context, url string) (*MovieDat
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse movie page: %w", err)
 	}
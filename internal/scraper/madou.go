@@ -53,7 +53,7 @@ func (m *MadouScraper) ScrapeByNumber(ctx context.Context, number string) (*Movi
 		}
 		defer resp.Body.Close()
 
-		doc, err := goquery.NewDocumentFromReader(resp.Body)
+		doc, err := parseHTML(resp.Body)
 		if err != nil {
 			logger.Debug("Failed to parse %s: %v", searchURL, err)
 			continue
@@ -99,7 +99,7 @@ func (m *MadouScraper) ScrapeByURL(ctx context.Context, url string) (*MovieData,
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse movie page: %w", err)
 	}
@@ -239,10 +239,7 @@ func (m *MadouScraper) extractReleaseDate(doc *goquery.Document, data *MovieData
 			// Extract date from the text
 			dateRegex := regexp.MustCompile(`(\d{4})[-/年](\d{1,2})[-/月](\d{1,2})`)
 			if matches := dateRegex.FindStringSubmatch(text); len(matches) == 4 {
-				year := matches[1]
-				month := fmt.Sprintf("%02s", matches[2])
-				day := fmt.Sprintf("%02s", matches[3])
-				data.Release = fmt.Sprintf("%s-%s-%s", year, month, day)
+				data.Release = formatDate(matches[1], matches[2], matches[3])
 			}
 		}
 	})
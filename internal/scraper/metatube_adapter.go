@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
 )
 
@@ -80,7 +81,8 @@ func NewMetaTubeAdapter(cfg *config.Config) *MetaTubeAdapter {
 		baseURL: cfg.Scraper.MetaTubeURL,
 		token:   cfg.Scraper.MetaTubeToken,
 		httpClient: &http.Client{
-			Timeout: time.Duration(cfg.Proxy.Timeout) * time.Second,
+			Timeout:   time.Duration(cfg.Proxy.Timeout) * time.Second,
+			Transport: httpclient.WrapTransport(nil),
 		},
 	}
 }
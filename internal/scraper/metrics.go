@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScrapeLatencyBuckets 各数据源抓取耗时直方图的上界（秒）
+var ScrapeLatencyBuckets = []float64{0.5, 1, 2, 5, 10, 30, 60}
+
+// SourceStats 一个数据源的抓取统计
+type SourceStats struct {
+	Source  string
+	Success int
+	Failure int
+	// Buckets[i] 为耗时不超过 ScrapeLatencyBuckets[i] 的抓取次数（累计值）
+	Buckets []int
+	Sum     float64 // 总耗时（秒）
+	Count   int
+}
+
+// sourceMetrics 按数据源记录抓取次数和耗时
+type sourceMetrics struct {
+	mu      sync.Mutex
+	sources map[string]*SourceStats
+}
+
+func newSourceMetrics() *sourceMetrics {
+	return &sourceMetrics{sources: make(map[string]*SourceStats)}
+}
+
+// observe 记录一次抓取的结果和耗时
+func (m *sourceMetrics) observe(source string, elapsed time.Duration, success bool) {
+	source = strings.ToLower(source)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.sources[source]
+	if !ok {
+		stats = &SourceStats{Source: source, Buckets: make([]int, len(ScrapeLatencyBuckets))}
+		m.sources[source] = stats
+	}
+
+	if success {
+		stats.Success++
+	} else {
+		stats.Failure++
+	}
+	seconds := elapsed.Seconds()
+	for i, bound := range ScrapeLatencyBuckets {
+		if seconds <= bound {
+			stats.Buckets[i]++
+		}
+	}
+	stats.Sum += seconds
+	stats.Count++
+}
+
+// SourceStats 返回各数据源的抓取统计快照，按数据源名排序
+func (s *Scraper) SourceStats() []SourceStats {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+
+	result := make([]SourceStats, 0, len(s.metrics.sources))
+	for _, stats := range s.metrics.sources {
+		snapshot := *stats
+		snapshot.Buckets = append([]int(nil), stats.Buckets...)
+		result = append(result, snapshot)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Source < result[j].Source })
+	return result
+}
@@ -16,7 +16,11 @@ func (s *Scraper) scrapeMGStage(ctx context.Context, number string) (*MovieData,
 	
 	// MGStage使用直接产品URL
 	productURL := fmt.Sprintf("https://www.mgstage.com/product/product_detail/%s/", number)
-	
+	return s.scrapeMGStagePage(ctx, productURL, number)
+}
+
+// scrapeMGStagePage 抓取MGStage产品详情页
+func (s *Scraper) scrapeMGStagePage(ctx context.Context, productURL, number string) (*MovieData, error) {
 	resp, err := s.httpClient.Get(ctx, productURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch page: %w", err)
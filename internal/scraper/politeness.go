@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/pkg/random"
+)
+
+// sourceThrottle 为每个数据源维护最小请求间隔（独立于 Common.Sleep）
+// 所有 Scraper 实例共享同一个节流器，保证并发处理时同一站点的请求也不会过快
+type sourceThrottle struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+var politeness = &sourceThrottle{next: make(map[string]time.Time)}
+
+// reserve 为数据源预约下一个请求时间槽，返回需要等待的时长
+func (t *sourceThrottle) reserve(source string, delay, jitter time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	slot := t.next[source]
+	if slot.Before(now) {
+		slot = now
+	}
+
+	interval := delay
+	if jitter > 0 {
+		interval += time.Duration(random.Int63n(int64(2*jitter)+1)) - jitter
+	}
+	t.next[source] = slot.Add(interval)
+
+	return slot.Sub(now)
+}
+
+// waitForSource 按照配置的数据源最小间隔等待，ctx 取消时立即返回
+func (s *Scraper) waitForSource(ctx context.Context, source string) error {
+	source = strings.ToLower(strings.TrimSpace(source))
+	seconds, ok := s.sourceDelays[source]
+	if !ok {
+		return nil
+	}
+
+	delay := time.Duration(seconds * float64(time.Second))
+	jitter := time.Duration(s.sourceDelayJitter * float64(time.Second))
+	if jitter > delay {
+		jitter = delay
+	}
+
+	wait := politeness.reserve(source, delay, jitter)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
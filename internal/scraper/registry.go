@@ -0,0 +1,223 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// sourceFunc 一个数据源的抓取函数
+type sourceFunc func(s *Scraper, ctx context.Context, number string) (*MovieData, error)
+
+// sourceRegistry 按名称（含别名，小写）登记所有支持的数据源
+var sourceRegistry = map[string]sourceFunc{
+	"javdb":          (*Scraper).ScrapeImprovedJavDB,
+	"javbus":         (*Scraper).scrapeJavBus,
+	"fanza":          (*Scraper).scrapeFanza,
+	"dmm":            (*Scraper).scrapeDMM,
+	"xcity":          (*Scraper).scrapeXCity,
+	"mgstage":        (*Scraper).scrapeMGStage,
+	"fc2":            (*Scraper).scrapeFC2Club,
+	"fc2club":        (*Scraper).scrapeFC2Club,
+	"jav321":         (*Scraper).scrapeJAV321,
+	"javlibrary":     (*Scraper).scrapeJavLibrary,
+	"cableav":        (*Scraper).scrapeCableAV,
+	"cnmdb":          (*Scraper).scrapeCNMDB,
+	"dahlia":         (*Scraper).scrapeDahlia,
+	"faleno":         (*Scraper).scrapeFaleno,
+	"fantastica":     (*Scraper).scrapeFantastica,
+	"carib":          (*Scraper).scrapeCarib,
+	"caribbeancom":   (*Scraper).scrapeCarib,
+	"caribpr":        (*Scraper).scrapeCaribPR,
+	"caribbeancompr": (*Scraper).scrapeCaribPR,
+	"dlsite":         (*Scraper).scrapeDLSite,
+	"gcolle":         (*Scraper).scrapeGColle,
+	"getchu":         (*Scraper).scrapeGetchu,
+	"javmenu":        (*Scraper).scrapeJavMenu,
+	"javday":         (*Scraper).scrapeJavDay,
+	"freejavbt": func(s *Scraper, ctx context.Context, number string) (*MovieData, error) {
+		return scrapeFreeJavBT(number)
+	},
+	"madou": (*Scraper).scrapeMadou,
+	"md":    (*Scraper).scrapeMadou,
+}
+
+// detailFunc 直接抓取数据源详情页的函数，用于指定URL时跳过搜索
+type detailFunc func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error)
+
+// detailRegistry 按名称（含别名，小写）登记支持按详情页URL抓取的数据源
+var detailRegistry = map[string]detailFunc{
+	"javdb": (*Scraper).scrapeImprovedJavDBURL,
+	"javbus": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return s.scrapeJavBusPage(ctx, detailURL, strings.Contains(detailURL, "javbus.red") || strings.Contains(detailURL, "/uncensored"))
+	},
+	"fanza": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return s.scrapeFanzaPage(ctx, detailURL, detailURL)
+	},
+	"dmm": (*Scraper).scrapeDMMPage,
+	"xcity": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return s.scrapeXCityPage(ctx, detailURL)
+	},
+	"mgstage": (*Scraper).scrapeMGStagePage,
+	"fc2":     (*Scraper).scrapeFC2ClubDetail,
+	"fc2club": (*Scraper).scrapeFC2ClubDetail,
+	"jav321": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return s.scrapeJAV321Page(ctx, detailURL)
+	},
+	"javlibrary": (*Scraper).scrapeJavLibraryURL,
+	"cableav":    (*Scraper).scrapeCableAVDetail,
+	"cnmdb":      (*Scraper).scrapeCNMDBDetail,
+	"dahlia":     (*Scraper).scrapeDahliaDetail,
+	"faleno":     (*Scraper).scrapeFalenoDetail,
+	"fantastica": (*Scraper).scrapeFantasticaDetail,
+	"carib": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewCaribScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"caribbeancom": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewCaribScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"caribpr": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewCaribPRScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"caribbeancompr": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewCaribPRScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"dlsite": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewDLSiteScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"gcolle": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewGColleScraper(s.httpClient).GetMovieDataByURL(detailURL)
+	},
+	"getchu": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewGetchuScraper(s.httpClient).ScrapeByURL(ctx, detailURL)
+	},
+	"javmenu": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewJavMenuScraper(s.httpClient).ScrapeByURL(ctx, detailURL)
+	},
+	"javday": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return s.scrapeJavDayPage(ctx, detailURL, siteBaseURL(detailURL))
+	},
+	"freejavbt": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return scrapeFreeJavBTPage(detailURL, number)
+	},
+	"madou": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewMadouScraper(s.httpClient).ScrapeByURL(ctx, detailURL)
+	},
+	"md": func(s *Scraper, ctx context.Context, detailURL, number string) (*MovieData, error) {
+		return NewMadouScraper(s.httpClient).ScrapeByURL(ctx, detailURL)
+	},
+}
+
+// detailHosts 按域名关键字识别详情页URL所属的数据源（先匹配的优先）
+var detailHosts = []struct {
+	hostKeyword string
+	source      string
+}{
+	{"javdb", "javdb"},
+	{"javbus", "javbus"},
+	{"buscdn", "javbus"},
+	{"dmm.co.jp", "dmm"},
+	{"dmm.com", "dmm"},
+	{"xcity", "xcity"},
+	{"mgstage", "mgstage"},
+	{"fc2club", "fc2club"},
+	{"jav321", "jav321"},
+	{"javlibrary", "javlibrary"},
+	{"cableav", "cableav"},
+	{"cnmdb", "cnmdb"},
+	{"dahlia-av", "dahlia"},
+	{"faleno", "faleno"},
+	{"fantastica", "fantastica"},
+	{"caribbeancompr", "caribpr"},
+	{"caribbeancom", "carib"},
+	{"dlsite", "dlsite"},
+	{"gcolle", "gcolle"},
+	{"getchu", "getchu"},
+	{"javmenu", "javmenu"},
+	{"javday", "javday"},
+	{"freejavbt", "freejavbt"},
+	{"madou", "madou"},
+}
+
+// SourceForURL 根据详情页URL的域名判断所属数据源，无法识别时返回空字符串
+func SourceForURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, site := range detailHosts {
+		if strings.Contains(host, site.hostKeyword) {
+			return site.source
+		}
+	}
+	return ""
+}
+
+// siteBaseURL 返回URL的协议和主机部分，例如 https://javdb.com
+func siteBaseURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// scrapeDetailURL 直接抓取指定数据源的详情页，不经过搜索
+func (s *Scraper) scrapeDetailURL(ctx context.Context, source, detailURL, number string) (*MovieData, error) {
+	scrape, ok := detailRegistry[strings.ToLower(source)]
+	if !ok {
+		return nil, fmt.Errorf("source %s does not support scraping by URL", source)
+	}
+	logger.Info("Scraping %s detail page directly: %s", source, detailURL)
+	return scrape(s, ctx, detailURL, number)
+}
+
+// IsKnownSource 判断数据源名称是否受支持（不区分大小写）
+func IsKnownSource(name string) bool {
+	_, ok := sourceRegistry[strings.ToLower(strings.TrimSpace(name))]
+	return ok
+}
+
+// KnownSources 返回所有受支持的数据源名称（含别名），按字母排序
+func KnownSources() []string {
+	names := make([]string, 0, len(sourceRegistry))
+	for name := range sourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetSourceOrder 设置 GetDataFromNumber 依次尝试的数据源及顺序
+// 未知的数据源名记录警告后跳过，重复的只保留第一次出现
+func (s *Scraper) SetSourceOrder(sources []string) {
+	ordered := make([]string, 0, len(sources))
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source == "" || seen[source] {
+			continue
+		}
+		if !IsKnownSource(source) {
+			logger.Warn("Unknown source %q skipped (known sources: %s)", source, strings.Join(KnownSources(), ", "))
+			continue
+		}
+		seen[source] = true
+		ordered = append(ordered, source)
+	}
+
+	if len(ordered) == 0 {
+		logger.Warn("No usable sources configured, nothing will be scraped")
+	}
+	s.sources = ordered
+}
+
+// Sources 返回当前使用的数据源顺序
+func (s *Scraper) Sources() []string {
+	return append([]string(nil), s.sources...)
+}
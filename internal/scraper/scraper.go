@@ -10,6 +10,7 @@ import (
 	"movie-data-capture/internal/config"
 	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/parser"
 )
 
 // MovieData 表示抓取的电影信息
@@ -27,6 +28,7 @@ type MovieData struct {
 	Studio          string            `json:"studio"`
 	Label           string            `json:"label"`
 	Series          string            `json:"series"`
+	SeriesIndex     int               `json:"series_index,omitempty"` // 系列中的序号（如标题中的 Vol.3），0=未知
 	Tag             []string          `json:"tag"`
 	Outline         string            `json:"outline"`
 	Cover           string            `json:"cover"`
@@ -42,14 +44,58 @@ type MovieData struct {
 	NamingRule      string            `json:"naming_rule"`
 	OriginalNaming  string            `json:"original_naming_rule"`
 	Headers         map[string]string `json:"headers,omitempty"`
+	CoverHeaders    map[string]string `json:"cover_headers,omitempty"` // 封面取自其他数据源（cover_sources）时下载封面用的请求头
+	Confidence      float64           `json:"confidence"`
+	Resolution      string            `json:"resolution,omitempty"`
+	Codec           string            `json:"codec,omitempty"`       // 视频编码（如 HEVC），仅在位置规则引用 codec 时检测
+	SourceFlag      string            `json:"source_flag,omitempty"` // 文件名标记（leak、C、hack），用于位置规则中的 source_flag
+	TitleMismatch   string            `json:"title_mismatch,omitempty"`
+	Extra           map[string]string `json:"extra,omitempty"` // 数据源特有字段，如 dmm_floor，可在命名规则中以 extra.<名称> 引用
+}
+
+// SetExtra 记录一个数据源特有字段，空值忽略
+func (d *MovieData) SetExtra(key, value string) {
+	if key == "" || value == "" {
+		return
+	}
+	if d.Extra == nil {
+		d.Extra = make(map[string]string)
+	}
+	d.Extra[key] = value
+}
+
+// CoverRequestHeaders 返回下载封面时使用的请求头，封面取自其他数据源时使用该数据源的请求头
+func (d *MovieData) CoverRequestHeaders() map[string]string {
+	if d.CoverHeaders != nil {
+		return d.CoverHeaders
+	}
+	return d.Headers
+}
+
+// ExtraPlaceholders 返回命名规则中可用的 extra.<名称> 占位符及其值
+func (d *MovieData) ExtraPlaceholders() map[string]string {
+	placeholders := make(map[string]string, len(d.Extra))
+	for key, value := range d.Extra {
+		placeholders["extra."+key] = value
+	}
+	return placeholders
 }
 
 // Scraper 处理从各种来源抓取电影数据
 type Scraper struct {
 	config          *config.Config
 	httpClient      *httpclient.Client
+	clientPool      *httpclient.ClientPool
 	sources         []string
 	metatubeAdapter *MetaTubeAdapter
+
+	sourceDelays      map[string]float64
+	sourceDelayJitter float64
+	actorAliases      *ActorAliases
+	studioAliases     *StudioAliases
+	urlTransforms     map[string][]urlTransform
+	metrics           *sourceMetrics
+	breakers          *sourceBreakers
 }
 
 // New 创建新的抓取器实例
@@ -57,7 +103,40 @@ func New(cfg *config.Config) *Scraper {
 	s := &Scraper{
 		config:     cfg,
 		httpClient: httpclient.NewClient(&cfg.Proxy),
-		sources:    cfg.GetSources(),
+		clientPool: httpclient.NewClientPool(&cfg.Proxy),
+
+		sourceDelays:      cfg.GetSourceDelays(),
+		sourceDelayJitter: cfg.GetSourceDelayJitter(),
+		urlTransforms:     compileURLTransforms(cfg.GetURLTransforms()),
+		metrics:           newSourceMetrics(),
+		breakers:          newSourceBreakers(cfg.Scraper.BreakerFailures, time.Duration(cfg.Scraper.BreakerReset)*time.Second),
+	}
+
+	// 按配置的优先级设置数据源，未知的数据源名会被跳过
+	s.SetSourceOrder(cfg.GetSources())
+	s.logSourceProxies()
+
+	// 限制单个页面的大小和解析时间
+	SetPageLimits(cfg.Scraper.MaxPageSize, time.Duration(cfg.Scraper.ParseTimeout)*time.Second)
+
+	// 加载演员别名文件
+	if cfg.NameRule.ActorAliasFile != "" {
+		aliases, err := LoadActorAliases(cfg.NameRule.ActorAliasFile)
+		if err != nil {
+			logger.Warn("Failed to load actor alias file: %v", err)
+		} else {
+			s.actorAliases = aliases
+		}
+	}
+
+	// 加载片商别名文件
+	if cfg.NameRule.StudioAliasFile != "" {
+		aliases, err := LoadStudioAliases(cfg.NameRule.StudioAliasFile)
+		if err != nil {
+			logger.Warn("Failed to load studio alias file: %v", err)
+		} else {
+			s.studioAliases = aliases
+		}
 	}
 
 	// 如果配置为MetaTube模式，初始化适配器
@@ -82,16 +161,34 @@ func New(cfg *config.Config) *Scraper {
 	return s
 }
 
+// logSourceProxies 在调试日志中列出每个数据源实际使用的代理
+func (s *Scraper) logSourceProxies() {
+	for _, source := range s.sources {
+		proxy := s.config.GetSourceProxy(source)
+		if proxy == nil {
+			proxy = &s.config.Proxy
+		}
+		logger.Debug("Source %s uses proxy: %s", source, httpclient.DescribeProxy(proxy))
+	}
+	for source := range s.config.Proxy.PerSource {
+		if _, ok := sourceRegistry[strings.ToLower(strings.TrimSpace(source))]; !ok {
+			logger.Warn("proxy.per_source: unknown source %q", source)
+		}
+	}
+}
+
 // GetDataFromNumber 根据番号抓取电影数据
 // Source: AURA-X Protocol - 支持双模式数据抓取
 func (s *Scraper) GetDataFromNumber(number, specifiedSource, specifiedURL string) (*MovieData, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	// 所有数据源的抓取、重试和回退共用一份时间和重试预算
+	ctx, cancel := context.WithTimeout(context.Background(), s.movieTimeBudget())
 	defer cancel()
+	ctx = httpclient.WithRetryBudget(ctx, s.config.Scraper.MovieRetryBudget)
 
 	logger.Info("Searching for movie data: %s", number)
 
 	// 检查是否使用MetaTube模式
-	if s.config.Scraper.Mode == "metatube" && s.metatubeAdapter != nil {
+	if s.config.Scraper.Mode == "metatube" && s.metatubeAdapter != nil && specifiedURL == "" {
 		logger.Info("Using MetaTube API mode")
 		data, err := s.metatubeAdapter.ScrapeByNumber(ctx, number)
 		if err != nil {
@@ -107,6 +204,8 @@ func (s *Scraper) GetDataFromNumber(number, specifiedSource, specifiedURL string
 		} else {
 			// 处理数据
 			s.processMovieData(data)
+			data.Confidence = ComputeConfidence(data, number)
+			s.applyCoverSources(ctx, data, number)
 			logger.Info("Successfully found data from MetaTube API")
 			return data, nil
 		}
@@ -119,26 +218,46 @@ func (s *Scraper) GetDataFromNumber(number, specifiedSource, specifiedURL string
 	sources := s.sources
 	if specifiedSource != "" {
 		sources = []string{specifiedSource}
+	} else if specifiedURL != "" {
+		// 指定URL但未指定来源时，按URL域名判断数据源
+		source := SourceForURL(specifiedURL)
+		if source == "" {
+			return nil, fmt.Errorf("cannot tell the source of URL %s, specify it with -source", specifiedURL)
+		}
+		sources = []string{source}
 	}
 
-	for _, source := range sources {
+	// 记录每个数据源的失败原因，全部失败时随错误一起返回
+	scrapeErr := &ScrapeError{Number: number}
+	for i, source := range sources {
 		source = strings.TrimSpace(source)
 		if source == "" {
 			continue
 		}
 
+		if ctx.Err() != nil {
+			logger.Debug("Time budget of %v used up for %s before trying %s", s.movieTimeBudget(), number, source)
+			scrapeErr.Failures = append(scrapeErr.Failures, SourceFailure{Source: source, Reason: ReasonBudgetExhausted, Err: ctx.Err()})
+			break
+		}
+
 		logger.Debug("Trying source: %s", source)
 
 		data, err := s.scrapeFromSource(ctx, source, number, specifiedURL)
 		if err != nil {
 			logger.Debug("Failed to scrape from %s: %v", source, err)
+			scrapeErr.Failures = append(scrapeErr.Failures, SourceFailure{Source: source, Reason: FailureReason(err), Err: err})
 			continue
 		}
+		if data == nil {
+			scrapeErr.Failures = append(scrapeErr.Failures, SourceFailure{Source: source, Reason: ReasonNotFound})
+		}
 
 		if data != nil {
 			// 验证数据
 			if data.Number == "" || data.Title == "" {
 				logger.Debug("Invalid data from %s: missing number or title", source)
+				scrapeErr.Failures = append(scrapeErr.Failures, SourceFailure{Source: source, Reason: ReasonInvalidData, Err: ErrInvalidData})
 				continue
 			}
 
@@ -150,69 +269,70 @@ func (s *Scraper) GetDataFromNumber(number, specifiedSource, specifiedURL string
 
 			// 处理数据
 			s.processMovieData(data)
+			data.Confidence = ComputeConfidence(data, number)
 			
-			logger.Info("Successfully found data from source: %s", source)
+			logger.Info("Successfully found data from source: %s (confidence %.2f)", source, data.Confidence)
+
+			// 与下一个数据源对比标题，发现可能的错误匹配
+			if s.config.Scraper.TitleCrossCheck && specifiedURL == "" {
+				s.crossCheckTitle(ctx, data, number, sources[i+1:])
+			}
+
+			// 从指定的封面数据源获取封面
+			s.applyCoverSources(ctx, data, number)
 			return data, nil
 		}
 	}
 
-	return nil, fmt.Errorf("no data found for number: %s", number)
+	return nil, scrapeErr
 }
 
-// scrapeFromSource 从特定来源抓取数据
+// DefaultMovieTimeBudget 未配置 Scraper.MovieTimeBudget 时一部影片的抓取时间上限
+const DefaultMovieTimeBudget = 60 * time.Second
+
+// movieTimeBudget 返回一部影片在所有数据源上抓取的总时间上限
+func (s *Scraper) movieTimeBudget() time.Duration {
+	if s.config.Scraper.MovieTimeBudget > 0 {
+		return time.Duration(s.config.Scraper.MovieTimeBudget) * time.Second
+	}
+	return DefaultMovieTimeBudget
+}
+
+// scrapeFromSource 从特定来源抓取数据，并记录该数据源的抓取次数和耗时
+// 数据源已被熔断时立即返回 retry.ErrCircuitOpen，不发出请求
 func (s *Scraper) scrapeFromSource(ctx context.Context, source, number, specifiedURL string) (*MovieData, error) {
-	switch strings.ToLower(source) {
-	case "javdb":
-		// Use improved JavDB scraper
-		return s.ScrapeImprovedJavDB(ctx, number)
-	case "javbus":
-		return s.scrapeJavBus(ctx, number)
-	case "fanza":
-		return s.scrapeFanza(ctx, number)
-	case "dmm":
-		return s.scrapeDMM(ctx, number)
-	case "xcity":
-		return s.scrapeXCity(ctx, number)
-	case "mgstage":
-		return s.scrapeMGStage(ctx, number)
-	case "fc2", "fc2club":
-		return s.scrapeFC2Club(ctx, number)
-	case "jav321":
-		return s.scrapeJAV321(ctx, number)
-	case "javlibrary":
-		return s.scrapeJavLibrary(ctx, number)
-
-	case "cableav":
-		return s.scrapeCableAV(ctx, number)
-	case "cnmdb":
-		return s.scrapeCNMDB(ctx, number)
-	case "dahlia":
-		return s.scrapeDahlia(ctx, number)
-	case "faleno":
-		return s.scrapeFaleno(ctx, number)
-	case "fantastica":
-		return s.scrapeFantastica(ctx, number)
-	case "carib", "caribbeancom":
-		return s.scrapeCarib(ctx, number)
-	case "caribpr", "caribbeancompr":
-		return s.scrapeCaribPR(ctx, number)
-	case "dlsite":
-		return s.scrapeDLSite(ctx, number)
-	case "gcolle":
-		return s.scrapeGColle(ctx, number)
-	case "getchu":
-		return s.scrapeGetchu(ctx, number)
-	case "javmenu":
-		return s.scrapeJavMenu(ctx, number)
-	case "javday":
-		return s.scrapeJavDay(ctx, number)
-	case "freejavbt":
-		return scrapeFreeJavBT(number)
-	case "madou", "md":
-		return s.scrapeMadou(ctx, number)
-	default:
+	return s.breakers.execute(ctx, source, func() (*MovieData, error) {
+		// 遵守数据源的最小请求间隔（等待时间不计入耗时）
+		if err := s.waitForSource(ctx, source); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		data, err := s.scrapeSource(ctx, source, number, specifiedURL)
+		s.metrics.observe(source, time.Since(start), err == nil && data != nil)
+		return data, err
+	})
+}
+
+// scrapeSource 调用数据源对应的抓取函数
+func (s *Scraper) scrapeSource(ctx context.Context, source, number, specifiedURL string) (*MovieData, error) {
+	// 附带该数据源配置的Cookie（年龄验证、地区等）
+	ctx = httpclient.WithCookies(ctx, s.config.GetSourceCookies(source))
+	// 使用该数据源单独配置的代理（proxy.per_source），未配置时使用全局代理
+	ctx = httpclient.WithProxy(ctx, s.config.GetSourceProxy(source))
+	// 网络错误、5xx和429按 common.scrape_retries 重试
+	ctx = httpclient.WithScrapeRetry(ctx, s.config.Common.ScrapeRetries, time.Duration(s.config.Common.ScrapeRetryDelay*float64(time.Second)))
+
+	// 指定了详情页URL时直接抓取该页面，跳过搜索
+	if specifiedURL != "" {
+		return s.scrapeDetailURL(ctx, source, specifiedURL, number)
+	}
+
+	scrape, ok := sourceRegistry[strings.ToLower(source)]
+	if !ok {
 		return nil, fmt.Errorf("unsupported source: %s", source)
 	}
+	return scrape(s, ctx, number)
 }
 
 // processMovieData 处理和规范化抓取的数据
@@ -225,11 +345,27 @@ func (s *Scraper) processMovieData(data *MovieData) {
 	data.Label = s.cleanSpecialCharacters(data.Label)
 	data.Series = s.cleanSpecialCharacters(data.Series)
 
-	// 处理演员列表
+	// 处理演员列表（照片映射的键同步更新）
 	for i, actor := range data.ActorList {
-		data.ActorList[i] = s.cleanSpecialCharacters(actor)
+		cleaned := s.cleanSpecialCharacters(actor)
+		if cleaned != actor && data.ActorPhoto != nil {
+			if photo, ok := data.ActorPhoto[actor]; ok {
+				delete(data.ActorPhoto, actor)
+				data.ActorPhoto[cleaned] = photo
+			}
+		}
+		data.ActorList[i] = cleaned
 	}
 
+	// 统一片商名（需在演员别名之前，演员别名可按片商匹配）
+	data.Studio = s.studioAliases.Resolve(data.Studio)
+
+	// 应用演员别名，区分同名演员
+	s.applyActorAliases(data)
+
+	// 应用图片URL替换规则
+	s.applyURLTransforms(data)
+
 	// 处理标签
 	for i, tag := range data.Tag {
 		data.Tag[i] = s.cleanSpecialCharacters(tag)
@@ -241,6 +377,11 @@ func (s *Scraper) processMovieData(data *MovieData) {
 	// 规范化发布日期
 	data.Release = s.normalizeDate(data.Release)
 
+	// 提取系列序号，用于系列内排序
+	if s.config.NameRule.SeriesIndex && data.SeriesIndex == 0 && data.Series != "" {
+		data.SeriesIndex = extractSeriesIndex(data.Title)
+	}
+
 	// 如果未设置则设置原始标题
 	if data.OriginalTitle == "" {
 		data.OriginalTitle = data.Title
@@ -250,12 +391,26 @@ func (s *Scraper) processMovieData(data *MovieData) {
 	data.NamingRule = s.generateNamingRule(data)
 	data.OriginalNaming = s.generateOriginalNamingRule(data)
 
+	// 部分数据源返回全角番号，统一为半角
+	data.Number = parser.NormalizeWidth(data.Number)
+
 	// 处理番号大写设置
 	if s.config.NameRule.NumberUppercase {
 		data.Number = strings.ToUpper(data.Number)
 	}
 }
 
+// RefreshNaming 在抓取后修改了标题等字段（如翻译）时重新清理这些字段并生成命名规则
+// OriginalNaming 基于 OriginalTitle，不受影响
+func (s *Scraper) RefreshNaming(data *MovieData) {
+	data.Title = s.cleanSpecialCharacters(data.Title)
+	data.Outline = s.cleanSpecialCharacters(data.Outline)
+	data.Studio = s.cleanSpecialCharacters(data.Studio)
+	data.Director = s.cleanSpecialCharacters(data.Director)
+	data.Series = s.cleanSpecialCharacters(data.Series)
+	data.NamingRule = s.generateNamingRule(data)
+}
+
 // cleanSpecialCharacters 移除或替换在文件系统中引起问题的特殊字符
 func (s *Scraper) cleanSpecialCharacters(text string) string {
 	if text == "" {
@@ -364,6 +519,11 @@ func (s *Scraper) applyNamingRule(rule string, data *MovieData, useOriginal bool
 		fields["actor"] = actorStr
 	}
 
+	// 先替换数据源特有字段，避免 extra.<名称> 中的字段名被误替换
+	for placeholder, value := range data.ExtraPlaceholders() {
+		result = strings.ReplaceAll(result, placeholder, value)
+	}
+
 	// 替换占位符
 	for field, value := range fields {
 		placeholder := field
@@ -387,6 +547,7 @@ func (s *Scraper) Close() error {
 	}
 
 	// 关闭HTTP客户端
+	s.clientPool.Close()
 	if s.httpClient != nil {
 		return s.httpClient.Close()
 	}
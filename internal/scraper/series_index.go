@@ -0,0 +1,30 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+
+	"movie-data-capture/pkg/parser"
+)
+
+// seriesIndexPatterns 标题中表示系列序号的写法，按顺序匹配
+var seriesIndexPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bvol\.?\s*(\d{1,3})\b`),
+	regexp.MustCompile(`(?i)\bpart\.?\s*(\d{1,3})\b`),
+	regexp.MustCompile(`第\s*(\d{1,3})\s*[弾話章集部巻作回]`),
+	regexp.MustCompile(`その\s*(\d{1,3})`),
+	regexp.MustCompile(`#\s*(\d{1,3})\b`),
+}
+
+// extractSeriesIndex 从标题中提取系列序号（如 "Vol.3"、"第3弾"），未找到时返回0
+func extractSeriesIndex(title string) int {
+	title = parser.NormalizeWidth(title)
+	for _, re := range seriesIndexPatterns {
+		if matches := re.FindStringSubmatch(title); len(matches) == 2 {
+			if index, err := strconv.Atoi(matches[1]); err == nil && index > 0 {
+				return index
+			}
+		}
+	}
+	return 0
+}
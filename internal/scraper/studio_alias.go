@@ -0,0 +1,72 @@
+package scraper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"movie-data-capture/pkg/parser"
+)
+
+// StudioAlias 片商别名文件中的一条记录
+//
+// 示例：
+//
+//	# studio_alias.yaml
+//	- name: "S1"                                # 统一使用的片商名
+//	  aliases: ["エスワン", "S1 NO.1 STYLE", "エスワン ナンバーワンスタイル"]
+//	- name: "MOODYZ"
+//	  aliases: ["ムーディーズ"]
+type StudioAlias struct {
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases"`
+}
+
+// StudioAliases 按片商名（含别名）索引的统一名称表
+type StudioAliases struct {
+	byName map[string]string
+}
+
+// LoadStudioAliases 从YAML文件加载片商别名表
+func LoadStudioAliases(path string) (*StudioAliases, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read studio alias file: %w", err)
+	}
+
+	var entries []StudioAlias
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse studio alias file: %w", err)
+	}
+
+	aliases := &StudioAliases{byName: make(map[string]string)}
+	for _, entry := range entries {
+		name := strings.TrimSpace(entry.Name)
+		if name == "" {
+			continue
+		}
+		for _, alias := range append([]string{name}, entry.Aliases...) {
+			if key := studioAliasKey(alias); key != "" {
+				aliases.byName[key] = name
+			}
+		}
+	}
+	return aliases, nil
+}
+
+// studioAliasKey 规范化片商名用于查找（全角转半角、忽略大小写和多余空白）
+func studioAliasKey(name string) string {
+	return actorAliasKey(parser.NormalizeWidth(name))
+}
+
+// Resolve 返回片商的统一名称；未匹配时返回原名
+func (a *StudioAliases) Resolve(studio string) string {
+	if a == nil || studio == "" {
+		return studio
+	}
+	if name, ok := a.byName[studioAliasKey(studio)]; ok {
+		return name
+	}
+	return studio
+}
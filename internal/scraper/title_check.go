@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/parser"
+)
+
+// DefaultTitleMismatchThreshold 未配置 Scraper.TitleMismatchThreshold 时使用的相似度阈值
+const DefaultTitleMismatchThreshold = 0.5
+
+// titleMismatchThreshold 返回判定标题不一致的相似度阈值
+func (s *Scraper) titleMismatchThreshold() float64 {
+	if s.config.Scraper.TitleMismatchThreshold > 0 {
+		return s.config.Scraper.TitleMismatchThreshold
+	}
+	return DefaultTitleMismatchThreshold
+}
+
+// crossCheckTitle 从后续数据源再抓取一次，标题差异过大时记录到 data.TitleMismatch
+// 只使用第一个成功返回数据的后续来源，失败不影响主结果
+func (s *Scraper) crossCheckTitle(ctx context.Context, data *MovieData, number string, sources []string) {
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" || strings.EqualFold(source, data.Source) {
+			continue
+		}
+
+		other, err := s.scrapeFromSource(ctx, source, number, "")
+		if err != nil || other == nil || other.Title == "" {
+			continue
+		}
+		if numberKey(other.Number) != numberKey(number) {
+			continue
+		}
+
+		similarity := titleSimilarity(data.Title, other.Title, number)
+		logger.Debug("Title cross-check %s vs %s: similarity %.2f", data.Source, other.Source, similarity)
+		if similarity < s.titleMismatchThreshold() {
+			data.TitleMismatch = fmt.Sprintf("%s: %s", other.Source, other.Title)
+			logger.Warn("Possible mismatch for %s: %s title %q differs from %s title %q (similarity %.2f)",
+				number, data.Source, data.Title, other.Source, other.Title, similarity)
+		}
+		return
+	}
+}
+
+// titleSimilarity 计算两个标题的相似度（0-1），基于去除番号、空白和标点后的编辑距离
+func titleSimilarity(a, b, number string) float64 {
+	ra := []rune(normalizeTitleForCompare(a, number))
+	rb := []rune(normalizeTitleForCompare(b, number))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// normalizeTitleForCompare 统一全半角和大小写，并去掉番号、空白和标点
+func normalizeTitleForCompare(title, number string) string {
+	title = strings.ToLower(parser.NormalizeWidth(title))
+	if number != "" {
+		title = strings.ReplaceAll(title, strings.ToLower(number), "")
+	}
+
+	var b strings.Builder
+	for _, r := range title {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// levenshtein 计算两个字符序列的编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// trailerURLRegex finds mp4 URLs in scripts, including JSON-escaped ones (https:\/\/...)
+var trailerURLRegex = regexp.MustCompile(`(?:https?:)?(?:\\?/){2}[^"'\s<>]+?\.mp4`)
+
+// trailerIDRegex splits a compacted cid or number into its label and digits, so zero padding can be ignored
+var trailerIDRegex = regexp.MustCompile(`^([a-z0-9_]*?[a-z_])0*(\d+)$`)
+
+// nonAlnumRegex matches everything a cid or number is compacted without
+var nonAlnumRegex = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// trailerMatchesMovie reports whether a trailer URL names one of the movie's ids (cid or number).
+// Case, separators and zero padding are ignored, so ssis00123mhb.mp4 matches SSIS-123 but not SSIS-1234.
+func trailerMatchesMovie(trailer string, ids ...string) bool {
+	name := nonAlnumRegex.ReplaceAllString(strings.ToLower(trailer), "")
+	for _, id := range ids {
+		id = nonAlnumRegex.ReplaceAllString(strings.ToLower(id), "")
+		if id == "" {
+			continue
+		}
+		pattern := regexp.QuoteMeta(id) + `(?:\D|$)`
+		if m := trailerIDRegex.FindStringSubmatch(id); m != nil {
+			pattern = regexp.QuoteMeta(m[1]) + `0*` + m[2] + `(?:\D|$)`
+		}
+		if regexp.MustCompile(pattern).MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// trailerHeightRegex matches an explicit resolution such as 720p or _1080p
+var trailerHeightRegex = regexp.MustCompile(`(\d{3,4})p`)
+
+// dmmTrailerQualities maps the DMM sample video suffixes to their approximate height
+var dmmTrailerQualities = []struct {
+	suffix string
+	height int
+}{
+	{"_4k", 2160},
+	{"hhb", 1080},
+	{"mhb", 720},
+	{"hmb", 720},
+	{"dmb", 480},
+	{"mmb", 480},
+	{"_dm", 360},
+	{"_sm", 240},
+}
+
+// trailerHeight estimates the vertical resolution of a trailer from its URL, 0 if unknown
+func trailerHeight(url string) int {
+	name := strings.ToLower(url[strings.LastIndex(url, "/")+1:])
+	if matches := trailerHeightRegex.FindStringSubmatch(name); matches != nil {
+		if height, err := strconv.Atoi(matches[1]); err == nil {
+			return height
+		}
+	}
+	for _, quality := range dmmTrailerQualities {
+		if strings.Contains(name, quality.suffix) {
+			return quality.height
+		}
+	}
+	return 0
+}
+
+// selectTrailer picks one trailer URL out of the candidates according to Trailer.Quality:
+// "highest" (default), "lowest", "first", or a cap such as "720p" (highest not above it)
+func selectTrailer(candidates []string, quality string) string {
+	seen := make(map[string]bool, len(candidates))
+	var urls []string
+	for _, url := range candidates {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return ""
+	}
+
+	quality = strings.ToLower(strings.TrimSpace(quality))
+	if quality == "first" || len(urls) == 1 {
+		return urls[0]
+	}
+
+	limit := 0
+	if matches := trailerHeightRegex.FindStringSubmatch(quality); matches != nil {
+		limit, _ = strconv.Atoi(matches[1])
+	}
+
+	best := ""
+	bestHeight := -1
+	for _, url := range urls {
+		height := trailerHeight(url)
+		switch {
+		case quality == "lowest":
+			if best == "" || height < bestHeight {
+				best, bestHeight = url, height
+			}
+		case limit > 0 && height > limit:
+			continue
+		default:
+			if height > bestHeight {
+				best, bestHeight = url, height
+			}
+		}
+	}
+
+	// Every candidate is above the cap, fall back to the smallest one
+	if best == "" {
+		return selectTrailer(urls, "lowest")
+	}
+	return best
+}
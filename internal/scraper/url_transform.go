@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/logger"
+)
+
+// urlTransformAllSources 适用于所有数据源的规则键
+const urlTransformAllSources = "*"
+
+// urlTransform 编译后的图片URL替换规则
+type urlTransform struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// compileURLTransforms 编译替换规则（配置中的规则和内置规则），数据源名称统一为小写
+// 配置加载时已校验正则，这里无法编译的规则会被忽略并记录警告
+func compileURLTransforms(rules map[string][]config.URLTransform) map[string][]urlTransform {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	compiled := make(map[string][]urlTransform)
+	for source, list := range rules {
+		key := strings.ToLower(strings.TrimSpace(source))
+		for _, rule := range list {
+			if rule.Pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				logger.Warn("Invalid URL transform pattern for %s: %q: %v", source, rule.Pattern, err)
+				continue
+			}
+			compiled[key] = append(compiled[key], urlTransform{re: re, replace: rule.Replace})
+		}
+	}
+	return compiled
+}
+
+// transformURL 依次应用数据源专用规则和通用规则
+func (s *Scraper) transformURL(source, rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+
+	result := rawURL
+	for _, key := range []string{strings.ToLower(source), urlTransformAllSources} {
+		for _, rule := range s.urlTransforms[key] {
+			result = rule.re.ReplaceAllString(result, rule.replace)
+		}
+	}
+	if result != rawURL {
+		logger.Debug("URL transformed: %s -> %s", rawURL, result)
+	}
+	return result
+}
+
+// applyURLTransforms 对封面、小封面、剧照和预告片URL应用替换规则
+func (s *Scraper) applyURLTransforms(data *MovieData) {
+	if len(s.urlTransforms) == 0 {
+		return
+	}
+
+	data.Cover = s.transformURL(data.Source, data.Cover)
+	data.CoverSmall = s.transformURL(data.Source, data.CoverSmall)
+	for i, img := range data.Extrafanart {
+		data.Extrafanart[i] = s.transformURL(data.Source, img)
+	}
+	data.Trailer = s.transformURL(data.Source, data.Trailer)
+}
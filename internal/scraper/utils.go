@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"movie-data-capture/pkg/httpclient"
+	"movie-data-capture/pkg/parser"
 )
 
 // extractYear 从日期字符串中提取年份
@@ -28,6 +30,17 @@ func extractYear(dateStr string) string {
 	return ""
 }
 
+// formatDate 将年、月、日格式化为 YYYY-MM-DD，单位数的月和日补零
+// 月或日不是数字时原样拼接
+func formatDate(year, month, day string) string {
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errM != nil || errD != nil {
+		return fmt.Sprintf("%s-%s-%s", year, month, day)
+	}
+	return fmt.Sprintf("%s-%02d-%02d", year, m, d)
+}
+
 // fetchDocument 从URL获取并解析HTML文档
 func fetchDocument(ctx context.Context, client *httpclient.Client, url string) (*goquery.Document, error) {
 	resp, err := client.Get(ctx, url, nil)
@@ -36,7 +49,7 @@ func fetchDocument(ctx context.Context, client *httpclient.Client, url string) (
 	}
 	defer resp.Body.Close()
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := parseHTML(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -55,4 +68,45 @@ func joinActors(actors []string) string {
 		return ""
 	}
 	return strings.Join(actors, ", ")
-}
\ No newline at end of file
+}
+
+var (
+	titlePrefixRegex    = regexp.MustCompile(`^[A-Z0-9-]+\s*`)
+	titleLeadTokenRegex = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s+`)
+	numberKeyRegex      = regexp.MustCompile(`^\d*([a-z]+)0*(\d+)$`)
+)
+
+// stripTitleNumberPrefix 移除标题开头的番号
+// mode: number=仅当开头的词与番号一致时移除, always=总是移除大写前缀（旧行为）, off=不处理
+func stripTitleNumberPrefix(title, number, mode string) string {
+	switch mode {
+	case "off":
+		return title
+	case "always":
+		return titlePrefixRegex.ReplaceAllString(title, "")
+	}
+
+	matches := titleLeadTokenRegex.FindStringSubmatch(title)
+	if len(matches) < 2 || number == "" {
+		return title
+	}
+	if numberKey(matches[1]) != numberKey(number) {
+		return title
+	}
+	return strings.TrimPrefix(title, matches[0])
+}
+
+// numberKey 将番号规范化为可比较的形式，如 SSIS-001 与 ssis00001 得到相同结果
+func numberKey(number string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(parser.NormalizeWidth(number)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	key := b.String()
+	if matches := numberKeyRegex.FindStringSubmatch(key); len(matches) == 3 {
+		return matches[1] + matches[2]
+	}
+	return key
+}
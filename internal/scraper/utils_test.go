@@ -0,0 +1,269 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/retry"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestConvertDMMDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2023/1/5", "2023-01-05"},
+		{"2023/12/25", "2023-12-25"},
+		{"2023年3月9日", "2023-03-09"},
+		{"2023年10月1日", "2023-10-01"},
+		{"----", "----"},
+	}
+
+	for _, tt := range tests {
+		if got := convertDMMDate(tt.input); got != tt.expected {
+			t.Errorf("convertDMMDate(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{fmt.Errorf("failed to scrape DMM data for number: ABC-123: %w", ErrRegionBlocked), ReasonRegionBlocked},
+		{ErrAgeVerification, ReasonAgeVerification},
+		{errors.New("movie not found (404)"), ReasonNotFound},
+		{errors.New("HTTP 403: blocked by cloudflare"), ReasonBlocked},
+		{errors.New("HTTP 429 Too Many Requests"), ReasonRateLimited},
+		{errors.New("search returned status 503"), ReasonServerError},
+		{errors.New("unexpected status code: 502"), ReasonServerError},
+		{errors.New("something unexpected"), ReasonError},
+	}
+
+	for _, tt := range tests {
+		if got := FailureReason(tt.err); got != tt.expected {
+			t.Errorf("FailureReason(%v) = %q, want %q", tt.err, got, tt.expected)
+		}
+	}
+
+	err := fmt.Errorf("failed to scrape data: %w", &ScrapeError{Number: "ABC-123", Failures: []SourceFailure{
+		{Source: "dmm", Reason: ReasonRegionBlocked},
+		{Source: "javbus", Reason: ReasonNotFound},
+	}})
+	if got := len(SourceFailures(err)); got != 2 {
+		t.Fatalf("SourceFailures returned %d entries, want 2", got)
+	}
+	if want := "failed to scrape data: no data found for number: ABC-123 (dmm: region-blocked; javbus: not-found)"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestMovieDataValidate(t *testing.T) {
+	valid := MovieData{Number: "ABC-123", Title: "Title", Cover: "https://example.com/abc123pl.jpg"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() on valid data = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(d *MovieData)
+	}{
+		{"empty number", func(d *MovieData) { d.Number = "" }},
+		{"empty title", func(d *MovieData) { d.Title = " " }},
+		{"empty cover", func(d *MovieData) { d.Cover = "" }},
+		{"relative cover", func(d *MovieData) { d.Cover = "/images/abc123pl.jpg" }},
+		{"html cover", func(d *MovieData) { d.Cover = "https://example.com/error.html" }},
+	}
+	for _, tt := range tests {
+		d := valid
+		tt.modify(&d)
+		err := d.Validate()
+		if !errors.Is(err, ErrInvalidData) {
+			t.Errorf("%s: Validate() = %v, want ErrInvalidData", tt.name, err)
+		}
+	}
+}
+
+func TestDefaultDMMURLTransforms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001-1.jpg", "https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001jp-1.jpg"},
+		{"https://pics-cdn.dmm-img.co.jp/digital/video/ssis00001/ssis00001-12.jpg", "https://pics-cdn.dmm-img.co.jp/digital/video/ssis00001/ssis00001jp-12.jpg"},
+		{"https://pics.dmm.co.jp/mono-movie/adult/1abc-123/1abc-123-1.jpg", "https://pics.dmm.co.jp/mono-movie/adult/1abc-123/1abc-123jp-1.jpg"},
+		{"https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001jp-1.jpg", "https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001jp-1.jpg"},
+		{"https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001pl.jpg", "https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001pl.jpg"},
+		{"//pics.dmm.co.jp/digital/video/ssis00001/ssis00001-2.jpg", "https://pics.dmm.co.jp/digital/video/ssis00001/ssis00001jp-2.jpg"},
+		{"/digital/video/ssis00001/ssis00001pl.jpg", "https://www.dmm.co.jp/digital/video/ssis00001/ssis00001pl.jpg"},
+		{"//cc3001.dmm.co.jp/litevideo/freepv/s/ssi/ssis00001/ssis00001_mhb_w.mp4", "https://cc3001.dmm.co.jp/litevideo/freepv/s/ssi/ssis00001/ssis00001_mhb_w.mp4"},
+	}
+
+	cfg := &config.Config{}
+	s := &Scraper{urlTransforms: compileURLTransforms(cfg.GetURLTransforms())}
+	for _, tt := range tests {
+		if got := s.transformURL("dmm", tt.input); got != tt.expected {
+			t.Errorf("transformURL(dmm, %q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	// Small samples keep the thumbnail, a configured dmm list replaces the built-in rules
+	cfg.Scraper.DMMSampleSize = "small"
+	s.urlTransforms = compileURLTransforms(cfg.GetURLTransforms())
+	if got := s.transformURL("dmm", "//pics.dmm.co.jp/v/ssis00001-1.jpg"); got != "https://pics.dmm.co.jp/v/ssis00001-1.jpg" {
+		t.Errorf("small samples: got %q", got)
+	}
+	cfg.Scraper.URLTransforms = map[string][]config.URLTransform{"DMM": {}}
+	s.urlTransforms = compileURLTransforms(cfg.GetURLTransforms())
+	if got := s.transformURL("dmm", "//pics.dmm.co.jp/v/ssis00001-1.jpg"); got != "//pics.dmm.co.jp/v/ssis00001-1.jpg" {
+		t.Errorf("disabled rules: got %q", got)
+	}
+}
+
+func TestSourceBreakers(t *testing.T) {
+	breakers := newSourceBreakers(2, time.Minute)
+	ctx := context.Background()
+
+	misses := []string{
+		"no results found",
+		"no search results found in javdb",
+		"no title found for number: ABC-123",
+		"no detail page found for number: ABC-123",
+		"failed to parse HTML: unexpected EOF",
+	}
+	for _, miss := range misses {
+		notFound := func() (*MovieData, error) { return nil, errors.New(miss) }
+		for i := 0; i < 3; i++ {
+			if _, err := breakers.execute(ctx, "javbus", notFound); errors.Is(err, retry.ErrCircuitOpen) {
+				t.Fatalf("%q must not open the breaker", miss)
+			}
+		}
+	}
+
+	calls := 0
+	down := func() (*MovieData, error) { calls++; return nil, errors.New("connection refused") }
+	for i := 0; i < 3; i++ {
+		breakers.execute(ctx, "madou", down)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests before the breaker opened, got %d", calls)
+	}
+	if _, err := breakers.execute(ctx, "madou", down); FailureReason(err) != ReasonCircuitOpen {
+		t.Errorf("expected %s while open, got %v", ReasonCircuitOpen, err)
+	}
+
+	if _, err := newSourceBreakers(0, 0).execute(ctx, "madou", down); errors.Is(err, retry.ErrCircuitOpen) {
+		t.Errorf("disabled breakers must not reject requests")
+	}
+}
+
+func TestMergeCandidates(t *testing.T) {
+	results := map[string]*MovieData{
+		"dmm":    {Number: "SSIS-001", Title: "タイトル　A"},
+		"javbus": {Number: "ssis001", Title: "タイトル A!"},
+		"javdb":  {Number: "SSIS-001", Title: "Another title"},
+		"fanza":  nil,
+	}
+
+	candidates := MergeCandidates([]string{"dmm", "fanza", "javbus", "javdb"}, results)
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(candidates))
+	}
+	if got := fmt.Sprint(candidates[0].Sources); got != "[dmm javbus]" {
+		t.Errorf("first candidate sources = %s, want [dmm javbus]", got)
+	}
+	if candidates[1].Title != "Another title" || fmt.Sprint(candidates[1].Sources) != "[javdb]" {
+		t.Errorf("unexpected second candidate: %+v", candidates[1])
+	}
+}
+
+func TestIsOutage(t *testing.T) {
+	scrapeErr := func(reasons ...string) error {
+		err := &ScrapeError{Number: "ABC-123"}
+		for _, reason := range reasons {
+			err.Failures = append(err.Failures, SourceFailure{Source: "src", Reason: reason})
+		}
+		return err
+	}
+
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{scrapeErr(ReasonNotFound, ReasonNotFound), false},
+		{scrapeErr(ReasonNetwork, ReasonNotFound), false},
+		{scrapeErr(ReasonNetwork, ReasonServerError, ReasonCircuitOpen), true},
+		{scrapeErr(ReasonRateLimited, ReasonBudgetExhausted), true},
+		{scrapeErr(ReasonError), false},
+		{errors.New("connection refused"), true},
+	}
+	for _, tt := range tests {
+		if got := IsOutage(tt.err); got != tt.expected {
+			t.Errorf("IsOutage(%v) = %v, want %v", tt.err, got, tt.expected)
+		}
+	}
+}
+
+func TestExtractJavLibraryRating(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected float64
+	}{
+		{
+			name: "score",
+			html: `<div id="video_review"><table><tr><td class="text"><span class="score">(8.40)</span></td></tr></table></div>
+<div id="video_favorite_edit"><span id="watched"><a href="#">1234</a></span></div>`,
+			expected: 8.4,
+		},
+		{
+			name:     "no reviews",
+			html:     `<div id="video_review"><span class="score"></span></div>`,
+			expected: 0,
+		},
+		{
+			name:     "missing block",
+			html:     `<div id="video_info"></div>`,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+		if err != nil {
+			t.Fatalf("%s: failed to parse fixture: %v", tt.name, err)
+		}
+		if got := extractJavLibraryRating(doc); got != tt.expected {
+			t.Errorf("%s: extractJavLibraryRating() = %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestTrailerMatchesMovie(t *testing.T) {
+	tests := []struct {
+		trailer string
+		ids     []string
+		want    bool
+	}{
+		{"https://cc3001.dmm.co.jp/litevideo/freepv/s/ssi/ssis00123/ssis00123mhb.mp4", []string{"ssis00123", "SSIS-123"}, true},
+		{"https://cc3001.dmm.co.jp/litevideo/freepv/s/ssi/ssis00123/ssis00123mhb.mp4", []string{"", "SSIS-123"}, true},
+		{"https://cc3001.dmm.co.jp/litevideo/freepv/s/ssi/ssis01234/ssis01234mhb.mp4", []string{"ssis00123", "SSIS-123"}, false},
+		{"https://cc3001.dmm.co.jp/litevideo/freepv/h/h_1/h_123abc00045/h_123abc00045_dmb_w.mp4", []string{"h_123abc00045"}, true},
+		{"https://ads.example.com/banner.mp4", []string{"ssis00123", "SSIS-123"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := trailerMatchesMovie(tt.trailer, tt.ids...); got != tt.want {
+			t.Errorf("trailerMatchesMovie(%q, %v) = %v, want %v", tt.trailer, tt.ids, got, tt.want)
+		}
+	}
+}
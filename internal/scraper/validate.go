@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// coverImageExtensions 封面地址允许的图片扩展名（地址没有扩展名时不检查）
+var coverImageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".webp": true, ".gif": true, ".bmp": true,
+}
+
+// Validate 检查刮削结果的必填字段：番号、标题不能为空，封面必须是图片地址
+// 返回的错误包装 ErrInvalidData，并说明具体哪个字段有问题
+func (d *MovieData) Validate() error {
+	if d == nil {
+		return fmt.Errorf("%w: no data", ErrInvalidData)
+	}
+	if strings.TrimSpace(d.Number) == "" {
+		return fmt.Errorf("%w: empty number", ErrInvalidData)
+	}
+	if strings.TrimSpace(d.Title) == "" {
+		return fmt.Errorf("%w: empty title", ErrInvalidData)
+	}
+	if err := validateCoverURL(d.Cover); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return nil
+}
+
+// validateCoverURL 检查封面地址是否像一张图片：http(s) 地址，且扩展名不是网页
+func validateCoverURL(cover string) error {
+	if strings.TrimSpace(cover) == "" {
+		return fmt.Errorf("empty cover")
+	}
+	u, err := url.Parse(cover)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("cover is not an http(s) URL: %s", cover)
+	}
+	if ext := strings.ToLower(path.Ext(u.Path)); ext != "" && !coverImageExtensions[ext] {
+		return fmt.Errorf("cover does not look like an image: %s", cover)
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package scraper
 import (
 	"context"
 	"fmt"
-	"io"
 	"net/url"
 	"regexp"
 	"strings"
@@ -40,7 +39,7 @@ func (s *Scraper) scrapeXCity(ctx context.Context, number string) (*MovieData, e
 	}
 	
 	// 读取响应体用于调试
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPage(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -52,7 +51,7 @@ func (s *Scraper) scrapeXCity(ctx context.Context, number string) (*MovieData, e
 		logger.Debug("XCity response body preview: %s", string(body))
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	doc, err := parseHTML(strings.NewReader(string(body)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -92,7 +91,7 @@ func (s *Scraper) scrapeXCityPage(ctx context.Context, url string) (*MovieData,
 	}
 	
 	// 读取响应体以检查内容
-	body, err := io.ReadAll(resp.Body)
+	body, err := readPage(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -107,7 +106,7 @@ func (s *Scraper) scrapeXCityPage(ctx context.Context, url string) (*MovieData,
 		logger.Debug("XCity response preview: %s", preview)
 	}
 	
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	doc, err := parseHTML(strings.NewReader(string(body)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
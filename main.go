@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -12,34 +17,69 @@ import (
 	"movie-data-capture/internal/config"
 	"movie-data-capture/internal/core"
 	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/random"
 	"movie-data-capture/pkg/utils"
 )
 
 const Version = "1.0.0"
 
+// configList collects repeated -config flags in the order they were given
+type configList []string
+
+func (c *configList) String() string {
+	if len(*c) == 0 {
+		return "config.yaml"
+	}
+	return strings.Join(*c, "', '")
+}
+
+func (c *configList) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
 func main() {
 	var (
-		configPath     = flag.String("config", "config.yaml", "Config file path")
 		singleFile     = flag.String("file", "", "Single movie file path")
 		customNumber   = flag.String("number", "", "Custom file number")
-		mainMode       = flag.Int("mode", 1, "Main mode: 1=Scraping, 2=Organizing, 3=Analysis")
+		mainMode       = flag.Int("mode", 1, "Main mode: 1=Scraping, 2=Organizing, 3=Analysis, 4=Regenerate the NFOs of the organized library")
 		sourcePath     = flag.String("path", "", "Source folder path")
 		debug          = flag.Bool("debug", false, "Enable debug mode")
 		version        = flag.Bool("version", false, "Show version")
 		search         = flag.String("search", "", "Search number")
+		searchAll      = flag.Bool("all", false, "With -search, query every enabled source and print a side-by-side comparison of their results")
+		searchMerged   = flag.String("search-all", "", "Search a number on all sources in parallel and print the de-duplicated candidates with the sources that returned them")
 		specifiedSrc   = flag.String("source", "", "Specified source")
-		specifiedURL   = flag.String("url", "", "Specified URL")
+		sourceOrder    = flag.String("sources", "", "Comma separated sources to try for this run, in order (e.g. dmm,javmenu); overrides priority.website")
+		specifiedURL   = flag.String("url", "", "Detail page URL to scrape directly, skipping search (source is detected from the host unless -source is given)")
 		logDir         = flag.String("logdir", "", "Log directory")
 		gui            = flag.Bool("gui", false, "Launch GUI mode")
+		verify         = flag.Bool("verify", false, "Verify organized library (missing or corrupt poster/fanart/thumb, invalid NFOs)")
+		repair         = flag.Bool("repair", false, "Verify the organized library and fix trivial NFO problems (control characters, missing title)")
+		htmlIndex      = flag.Bool("html-index", false, "Generate static index.html pages of the organized library per actor and studio (common.html_index_dir)")
+		refresh        = flag.String("refresh", "", "Regenerate artwork of the organized library from its NFOs: poster, fanart, thumb, extrafanart or all (comma separated)")
+		dumpHTML       = flag.String("dump-html", "", "Fetch a URL as the scraper would and print the HTML to stdout")
+		seed           = flag.Int64("seed", 0, "Seed for randomized choices (jitter, user agent rotation); 0 = random")
+		scrapeStdin    = flag.Bool("scrape-stdin", false, "Scrape numbers read from stdin (one per line) without touching files")
+		scrapeFile     = flag.String("scrape-file", "", "Scrape numbers read from a file (one per line) without touching files")
+		jsonOutput     = flag.Bool("json", false, "Print scrape results as JSON lines on stdout (logs go to stderr)")
+		force          = flag.Bool("force", false, "Rescan source subfolders marked as processed")
+		maxDuration    = flag.String("max-duration", "", "Stop starting new movies after this long, e.g. 30m (in-flight ones finish)")
+		dryRun         = flag.Bool("dryrun", false, "Log the planned moves (source -> destination) and a summary without creating folders, moving files or writing art/NFOs")
+		benchmark      = flag.Bool("benchmark-sources", false, "Scrape a fixed set of numbers (or -number, comma separated) with every source and compare latency, success rate and field completeness")
+		resume         = flag.Bool("resume", false, "Checkpoint every finished file and skip files finished by an interrupted previous run (state in common.recovery_file, default recovery_state.json)")
 	)
+	var configPaths configList
+	flag.Var(&configPaths, "config", "Config file path (.yaml, .toml or .json); repeat to merge overrides over a base file")
 	flag.Parse()
 
 	// {{ AURA-X: Modify - GUI构建时默认进入GUI模式，无需-gui参数 }}
 	// 当使用 wails dev/build -tags gui 编译时，isGUIBuild 为 true
 	if isGUIBuild {
 		// GUI构建版本默认启动GUI，除非明确指定了其他CLI参数
-		hasCliArgs := *singleFile != "" || *search != "" || *version
+		hasCliArgs := *singleFile != "" || *search != "" || *searchMerged != "" || *version || *dumpHTML != "" || *scrapeStdin || *scrapeFile != "" || *benchmark
 		if !hasCliArgs {
 			runGUI()
 			return
@@ -63,9 +103,13 @@ func main() {
 	} else {
 		logger.InitConsoleLogger()
 	}
+	if *jsonOutput {
+		// Keep stdout for JSON lines only
+		logger.SetStderrOnly(true)
+	}
 
 	// Load configuration
-	cfg, err := config.Load(*configPath)
+	cfg, err := config.LoadFiles(configPaths)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -80,31 +124,115 @@ func main() {
 	if *debug {
 		cfg.DebugMode.Switch = true
 	}
+	if *force {
+		cfg.Common.ForceRescan = true
+	}
+	if *maxDuration != "" {
+		// Config validation already ran, and an unparsable value would mean no limit
+		if err := config.ValidateTimeFormat(*maxDuration); err != nil {
+			log.Fatalf("Invalid --max-duration: %v", err)
+		}
+		cfg.Common.MaxDuration = *maxDuration
+	}
+	if *sourceOrder != "" {
+		cfg.Priority.Website = *sourceOrder
+	}
+	if *dryRun {
+		cfg.Common.DryRun = true
+	}
+	if *resume && cfg.Common.RecoveryFile == "" {
+		cfg.Common.RecoveryFile = core.DefaultRecoveryFile
+	}
+
+	httpclient.SetMaxInflightRequests(cfg.Common.MaxInflightRequests)
+	httpclient.SetRequestLogging(cfg.DebugMode.HTTPTrace)
+	httpclient.SetDNSOptions(cfg.Common.MaxDNSLookups, time.Duration(cfg.Common.DNSCacheTTL)*time.Second)
+	httpclient.SetAdaptivePacing(cfg.Common.AdaptiveSleep, time.Duration(cfg.Common.AdaptiveSleepMax)*time.Second)
+
+	// Seed all randomized choices, the seed is logged so a run can be reproduced
+	usedSeed := random.Seed(*seed)
+
+	// Handle dump-html before printing anything so stdout only contains the page
+	if *dumpHTML != "" {
+		handleDumpHTML(*dumpHTML, cfg)
+		return
+	}
 
 	printHeader()
 
 	startTime := time.Now()
 	logger.Info("Start at %s", startTime.Format("2006-01-02 15:04:05"))
-	logger.Info("Load Config file '%s'", *configPath)
+	logger.Info("Load Config file '%s'", configPaths.String())
+	logger.Info("Random seed: %d", usedSeed)
 
 	if cfg.DebugMode.Switch {
 		logger.Info("Debug mode enabled")
 	}
 
+	// Handle HTML index generation
+	if *htmlIndex {
+		if cfg.Common.HTMLIndexDir == "" {
+			cfg.Common.HTMLIndexDir = "library_html"
+		}
+		if err := core.GenerateHTMLIndex(cfg); err != nil {
+			logger.Error("Failed to generate HTML index: %v", err)
+		}
+		return
+	}
+
+	// Handle verify mode
+	if *verify || *repair {
+		handleVerifyMode(cfg, *repair)
+		return
+	}
+
+	// Handle artwork refresh mode
+	if *refresh != "" {
+		handleRefreshMode(*refresh, cfg)
+		return
+	}
+
+	// Handle source benchmark
+	if *benchmark {
+		handleBenchmarkSources(*customNumber, cfg)
+		return
+	}
+
+	// Handle batch scrape mode
+	if *scrapeStdin || *scrapeFile != "" {
+		handleBatchScrape(*scrapeFile, *jsonOutput, cfg, *specifiedSrc)
+		return
+	}
+
+	// Handle merged search across all sources
+	if *searchMerged != "" {
+		handleSearchAll(*searchMerged, *jsonOutput, cfg)
+		return
+	}
+
 	// Handle search mode
 	if *search != "" {
-		handleSearchMode(*search, cfg, *specifiedSrc, *specifiedURL)
+		handleSearchMode(*search, cfg, *specifiedSrc, *specifiedURL, *searchAll)
 		return
 	}
 
-	// Handle single file mode
+	// Handle single file mode; mode 4 only regenerates the NFO of an organized video
 	if *singleFile != "" {
-		handleSingleFile(*singleFile, *customNumber, cfg, *specifiedSrc, *specifiedURL)
+		if cfg.Common.MainMode == core.MainModeRefreshNFO {
+			handleRefreshNFOFile(*singleFile, cfg)
+		} else {
+			handleSingleFile(*singleFile, *customNumber, cfg, *specifiedSrc, *specifiedURL)
+		}
 		return
 	}
 
-	// Handle folder processing
-	handleFolderProcessing(cfg)
+	if cfg.Common.MainMode == core.MainModeRefreshNFO {
+		// Regenerate the NFOs of the organized library in place
+		handleRefreshNFOMode(cfg)
+	} else {
+		// Handle folder processing
+		handleFolderProcessing(cfg)
+	}
 
 	endTime := time.Now()
 	elapsed := endTime.Sub(startTime)
@@ -125,10 +253,19 @@ func printHeader() {
 	logger.Info("======================================================")
 }
 
-func handleSearchMode(searchTerm string, cfg *config.Config, specifiedSrc, specifiedURL string) {
+func handleSearchMode(searchTerm string, cfg *config.Config, specifiedSrc, specifiedURL string, all bool) {
 	logger.Info("==================== Search Mode =====================")
 	
 	scraperInstance := scraper.New(cfg)
+	if all {
+		results, err := scraperInstance.GetDataFromNumberAll(searchTerm)
+		if err != nil {
+			logger.Error("Search failed: %v", err)
+			return
+		}
+		logger.MultiLineLog(logger.INFO, fmt.Sprintf("Search results for %s by source", searchTerm), compareLines(scraperInstance.Sources(), results))
+		return
+	}
 	data, err := scraperInstance.GetDataFromNumber(searchTerm, specifiedSrc, specifiedURL)
 	if err != nil {
 		logger.Error("Search failed: %v", err)
@@ -143,6 +280,174 @@ func handleSearchMode(searchTerm string, cfg *config.Config, specifiedSrc, speci
 	}
 }
 
+// searchCandidate is the JSON line written by -search-all -json for each candidate
+type searchCandidate struct {
+	Number  string   `json:"number"`
+	Title   string   `json:"title"`
+	Sources []string `json:"sources"`
+	Cover   string   `json:"cover,omitempty"`
+	Release string   `json:"release,omitempty"`
+}
+
+func handleSearchAll(searchTerm string, jsonOutput bool, cfg *config.Config) {
+	logger.Info("================== Search All Sources ==================")
+
+	scraperInstance := scraper.New(cfg)
+	results, err := scraperInstance.GetDataFromNumberAll(searchTerm)
+	if err != nil {
+		logger.Error("Search failed: %v", err)
+		return
+	}
+
+	candidates := scraper.MergeCandidates(scraperInstance.Sources(), results)
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetEscapeHTML(false)
+		for _, candidate := range candidates {
+			encoder.Encode(searchCandidate{
+				Number:  candidate.Number,
+				Title:   candidate.Title,
+				Sources: candidate.Sources,
+				Cover:   candidate.Data.Cover,
+				Release: candidate.Data.Release,
+			})
+		}
+		return
+	}
+
+	lines := make([]string, 0, len(candidates))
+	for i, candidate := range candidates {
+		lines = append(lines, fmt.Sprintf("%2d. %s %s [%s]", i+1, candidate.Number, candidate.Title, strings.Join(candidate.Sources, ", ")))
+	}
+	logger.MultiLineLog(logger.INFO, fmt.Sprintf("%d candidate(s) for %s", len(candidates), searchTerm), lines)
+}
+
+// compareFields are the fields shown by -search -all, one block per field with a line per source
+var compareFields = []struct {
+	name  string
+	value func(*scraper.MovieData) string
+}{
+	{"Title", func(d *scraper.MovieData) string { return d.Title }},
+	{"Release", func(d *scraper.MovieData) string { return d.Release }},
+	{"Runtime", func(d *scraper.MovieData) string { return d.Runtime }},
+	{"Actor", func(d *scraper.MovieData) string {
+		if len(d.ActorList) == 0 {
+			return d.Actor
+		}
+		return strings.Join(d.ActorList, ", ")
+	}},
+	{"Cover", func(d *scraper.MovieData) string { return d.Cover }},
+}
+
+// compareLines formats the per-source results of -search -all, marking sources without data
+func compareLines(sources []string, results map[string]*scraper.MovieData) []string {
+	var lines []string
+	for _, field := range compareFields {
+		lines = append(lines, field.name+":")
+		for _, source := range sources {
+			value := "(no data)"
+			if data := results[source]; data != nil {
+				if value = field.value(data); value == "" {
+					value = "-"
+				}
+			}
+			lines = append(lines, fmt.Sprintf("  %-14s %s", source, value))
+		}
+	}
+	return lines
+}
+
+// batchResult is the JSON line written for a number that could not be scraped
+type batchResult struct {
+	Number string `json:"number"`
+	Error  string `json:"error"`
+}
+
+func handleBatchScrape(listFile string, jsonOutput bool, cfg *config.Config, specifiedSrc string) {
+	logger.Info("==================== Batch Scrape ====================")
+
+	var input io.Reader = os.Stdin
+	if listFile != "" {
+		file, err := os.Open(listFile)
+		if err != nil {
+			logger.Error("Failed to open number list: %v", err)
+			return
+		}
+		defer file.Close()
+		input = file
+	}
+
+	scraperInstance := scraper.New(cfg)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+
+	var total, failed int
+	lineScanner := bufio.NewScanner(input)
+	for lineScanner.Scan() {
+		number := strings.TrimSpace(lineScanner.Text())
+		if number == "" || strings.HasPrefix(number, "#") {
+			continue
+		}
+		total++
+
+		data, err := scraperInstance.GetDataFromNumber(number, specifiedSrc, "")
+		if err == nil && data == nil {
+			err = fmt.Errorf("no data found")
+		}
+		if err != nil {
+			failed++
+			logger.Warn("Failed to scrape %s: %v", number, err)
+			if jsonOutput {
+				encoder.Encode(batchResult{Number: number, Error: err.Error()})
+			}
+			continue
+		}
+
+		if jsonOutput {
+			if err := encoder.Encode(data); err != nil {
+				logger.Error("Failed to encode result for %s: %v", number, err)
+			}
+		} else {
+			logger.Info("Result for %s:", number)
+			utils.DebugPrint(data)
+		}
+	}
+	if err := lineScanner.Err(); err != nil {
+		logger.Error("Failed to read number list: %v", err)
+	}
+
+	logger.Info("Batch scrape finished: %d numbers, %d failed", total, failed)
+}
+
+func handleBenchmarkSources(numberList string, cfg *config.Config) {
+	logger.Info("================== Source Benchmark ==================")
+
+	numbers := scraper.DefaultBenchmarkNumbers
+	if numberList != "" {
+		numbers = nil
+		for _, number := range strings.Split(numberList, ",") {
+			if number = strings.TrimSpace(number); number != "" {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	scraperInstance := scraper.New(cfg)
+	defer scraperInstance.Close()
+
+	sources := scraperInstance.Sources()
+	logger.Info("Benchmarking %d source(s) with %d number(s): %s", len(sources), len(numbers), strings.Join(numbers, ", "))
+	results := scraperInstance.BenchmarkSources(numbers, sources)
+
+	lines := []string{fmt.Sprintf("%-14s %12s %12s %13s", "source", "success", "avg latency", "completeness")}
+	for _, result := range results {
+		lines = append(lines, fmt.Sprintf("%-14s %3d/%-3d %3.0f%% %12v %12.0f%%",
+			result.Source, result.Successes, result.Attempts, result.SuccessRate()*100,
+			result.AvgLatency().Round(time.Millisecond), result.AvgCompleteness()*100))
+	}
+	logger.MultiLineLog(logger.INFO, "Source benchmark (best first)", lines)
+}
+
 func handleSingleFile(filePath, customNumber string, cfg *config.Config, specifiedSrc, specifiedURL string) {
 	logger.Info("==================== Single File =====================")
 	
@@ -155,7 +460,8 @@ func handleSingleFile(filePath, customNumber string, cfg *config.Config, specifi
 		number = utils.GetNumberFromFilenameWithConfig(filepath.Base(filePath), cfg)
 	}
 	
-	if number == "" {
+	// The number can come from the page when a detail URL is given
+	if number == "" && specifiedURL == "" {
 		logger.Error("Cannot extract number from filename")
 		return
 	}
@@ -173,7 +479,26 @@ func handleFolderProcessing(cfg *config.Config) {
 	}
 	
 	processor := core.NewProcessor(cfg)
+
+	if cfg.Common.StatsAddr != "" {
+		statsServer := core.NewStatsServer(cfg.Common.StatsAddr, processor)
+		if err := statsServer.Start(); err != nil {
+			logger.Warn("Failed to start stats server: %v", err)
+		} else {
+			defer statsServer.Close()
+		}
+	}
 	
+	// Finish or undo multi-part moves left half-done by a crash; a dry run leaves them as they are
+	if cfg.Common.DryRun {
+		logger.Info("[DRY RUN] Skipping recovery of interrupted moves")
+	} else {
+		processor.ResumeInterruptedMoves(sourceFolder)
+	}
+
+	// Remove sample/trailer junk before scanning so it is never picked up
+	utils.CleanJunkFiles(sourceFolder, cfg)
+
 	movieList, err := utils.GetMovieList(sourceFolder, cfg)
 	if err != nil {
 		logger.Error("Failed to get movie list: %v", err)
@@ -187,4 +512,110 @@ func handleFolderProcessing(cfg *config.Config) {
 	if err != nil {
 		logger.Error("Failed to process movie list: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func handleDumpHTML(rawURL string, cfg *config.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	body, status, err := scraper.FetchPage(ctx, cfg, rawURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fetch %s: %v\n", rawURL, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "HTTP %d, %d bytes\n", status, len(body))
+	os.Stdout.Write(body)
+}
+
+func handleRefreshMode(kindList string, cfg *config.Config) {
+	logger.Info("==================== Refresh Mode ====================")
+
+	kinds, err := core.ParseRefreshKinds(kindList)
+	if err != nil {
+		logger.Error("Invalid --refresh value: %v", err)
+		return
+	}
+
+	refresher := core.NewRefresher(cfg)
+	defer refresher.Close()
+
+	for _, root := range libraryRoots(cfg) {
+		result, err := refresher.Refresh(root, kinds)
+		if err != nil {
+			logger.Error("Refresh of %s failed: %v", root, err)
+			continue
+		}
+
+		logger.Info("Refreshed %d artwork file(s) for %d movies in %s, %d failed", result.Refreshed, result.Movies, root, result.Failed)
+	}
+}
+
+func handleRefreshNFOMode(cfg *config.Config) {
+	logger.Info("================== Regenerate NFOs ===================")
+
+	processor := core.NewProcessor(cfg)
+	defer processor.Close()
+
+	for _, root := range libraryRoots(cfg) {
+		result, err := processor.RefreshNFOs(root)
+		if err != nil {
+			logger.Error("Regenerating NFOs in %s failed: %v", root, err)
+			continue
+		}
+
+		logger.Info("Regenerated %d of %d NFO file(s) in %s, %d failed", result.Refreshed, result.Movies, root, result.Failed)
+	}
+}
+
+func handleRefreshNFOFile(filePath string, cfg *config.Config) {
+	logger.Info("================== Regenerate NFO ====================")
+
+	processor := core.NewProcessor(cfg)
+	defer processor.Close()
+
+	if err := processor.RefreshNFOForVideo(filePath); err != nil {
+		logger.Error("Failed to regenerate the NFO of %s: %v", filePath, err)
+		return
+	}
+	logger.Info("Regenerated the NFO of %s", filePath)
+}
+
+func handleVerifyMode(cfg *config.Config, repair bool) {
+	logger.Info("==================== Verify Mode =====================")
+
+	verifier := core.NewVerifier(cfg)
+	verifier.Repair = repair
+	for _, root := range libraryRoots(cfg) {
+		result, err := verifier.Verify(root)
+		if err != nil {
+			logger.Error("Verify of %s failed: %v", root, err)
+			continue
+		}
+
+		for _, issue := range result.Issues {
+			switch issue.Kind {
+			case core.IssueCorrupt:
+				logger.Warn("[CORRUPT] %s: %s", issue.Path, issue.Detail)
+			case core.IssueNFO:
+				logger.Warn("[NFO] %s: %s", issue.Path, issue.Detail)
+			case core.IssueRepaired:
+				logger.Info("[REPAIRED] %s: %s", issue.Path, issue.Detail)
+			default:
+				logger.Warn("[MISSING] %s: %s", issue.Folder, issue.Detail)
+			}
+		}
+
+		logger.Info("Verified %d folders in %s, found %d issues", result.Checked, root, len(result.Issues))
+	}
+}
+
+// libraryRoots returns the organized library folders (success, censored and uncensored roots),
+// falling back to the source folder when no output folder is configured
+func libraryRoots(cfg *config.Config) []string {
+	roots := cfg.OutputRoots()
+	if len(roots) == 0 {
+		roots = []string{cfg.Common.SourceFolder}
+	}
+	return roots
+}
@@ -0,0 +1,218 @@
+package downloader
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// imageCache keeps downloaded images on disk together with their validators
+// (ETag / Last-Modified) so a later run can revalidate them with a conditional
+// GET and reuse the stored copy when the server answers 304 Not Modified.
+type imageCache struct {
+	dir    string
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// cacheEntry is the metadata stored next to a cached image
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Stored       time.Time `json:"stored"`
+}
+
+// newImageCache opens the cache in dir, an empty dir disables caching.
+// When maxBytes > 0 the least recently used images are pruned down to that size.
+func newImageCache(dir string, maxBytes int64) *imageCache {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Warn("Image cache disabled, failed to create %s: %v", dir, err)
+		return nil
+	}
+	c := &imageCache{dir: dir}
+	if removed, err := c.prune(maxBytes); err != nil {
+		logger.Warn("Failed to prune image cache %s: %v", dir, err)
+	} else if removed > 0 {
+		logger.Info("Pruned %d images from the image cache", removed)
+	}
+	return c
+}
+
+// prune removes temporary files left by interrupted copies and, when maxBytes > 0,
+// the least recently used images until the cache fits in maxBytes.
+// The modification time of a body is its last use, see copyTo.
+func (c *imageCache) prune(maxBytes int64) (int, error) {
+	type cachedBody struct {
+		path string
+		size int64
+		used time.Time
+	}
+	var bodies []cachedBody
+	var total int64
+	err := filepath.WalkDir(c.dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if strings.HasSuffix(name, ".tmp") {
+			os.Remove(path)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		total += info.Size()
+		if !strings.HasSuffix(name, ".json") {
+			bodies = append(bodies, cachedBody{path: path, size: info.Size(), used: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil || maxBytes <= 0 || total <= maxBytes {
+		return 0, err
+	}
+
+	sort.Slice(bodies, func(i, j int) bool { return bodies[i].used.Before(bodies[j].used) })
+	removed := 0
+	for _, body := range bodies {
+		if total <= maxBytes {
+			break
+		}
+		meta := body.path + ".json"
+		if info, err := os.Stat(meta); err == nil {
+			total -= info.Size()
+		}
+		os.Remove(meta)
+		if err := os.Remove(body.path); err == nil {
+			total -= body.size
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// paths returns the body and metadata file of url
+func (c *imageCache) paths(url string) (body, meta string) {
+	sum := sha1.Sum([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	base := filepath.Join(c.dir, key[:2], key)
+	return base, base + ".json"
+}
+
+// lookup returns the stored entry of url, or nil when it is not cached
+func (c *imageCache) lookup(url string) *cacheEntry {
+	body, meta := c.paths(url)
+	data, err := os.ReadFile(meta)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	if _, err := os.Stat(body); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// conditionalHeaders adds the validators of entry to headers for a conditional GET
+func (c *imageCache) conditionalHeaders(headers map[string]string, entry *cacheEntry) map[string]string {
+	merged := make(map[string]string, len(headers)+2)
+	for k, v := range headers {
+		merged[k] = v
+	}
+	if entry.ETag != "" {
+		merged["If-None-Match"] = entry.ETag
+	}
+	if entry.LastModified != "" {
+		merged["If-Modified-Since"] = entry.LastModified
+	}
+	return merged
+}
+
+// copyTo writes the cached body of url to filePath and marks the body as used
+func (c *imageCache) copyTo(url, filePath string) error {
+	body, _ := c.paths(url)
+	if err := copyFile(body, filePath); err != nil {
+		return err
+	}
+	now := time.Now()
+	os.Chtimes(body, now, now)
+	return nil
+}
+
+// store saves the downloaded file of url when the response carries validators.
+// Without ETag or Last-Modified the copy could never be revalidated, so it is skipped.
+func (c *imageCache) store(url, filePath string, header http.Header) {
+	entry := cacheEntry{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Stored:       time.Now(),
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return
+	}
+
+	body, meta := c.paths(url)
+	if err := os.MkdirAll(filepath.Dir(body), 0755); err != nil {
+		logger.Debug("Failed to create image cache folder: %v", err)
+		return
+	}
+	if err := copyFile(filePath, body); err != nil {
+		logger.Debug("Failed to cache %s: %v", url, err)
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.WriteFile(meta, data, 0644)
+	}
+	if err != nil {
+		os.Remove(body)
+		logger.Debug("Failed to write image cache metadata for %s: %v", url, err)
+	}
+}
+
+// copyFile copies src to dst through a uniquely named temporary file in the
+// destination folder, so concurrent copies to the same dst do not collide
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := out.Name()
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, dst)
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package downloader
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImageCache_PruneLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	cache := newImageCache(dir, 0)
+	src := filepath.Join(t.TempDir(), "image.jpg")
+	if err := os.WriteFile(src, []byte(strings.Repeat("x", 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{"Etag": []string{`"v1"`}}
+	urls := []string{"https://example.com/old.jpg", "https://example.com/used.jpg", "https://example.com/new.jpg"}
+	for i, url := range urls {
+		cache.store(url, src, header)
+		body, _ := cache.paths(url)
+		stamp := time.Now().Add(time.Duration(i-10) * time.Hour)
+		os.Chtimes(body, stamp, stamp)
+	}
+	// Using an entry makes it the most recently used one
+	if err := cache.copyTo(urls[1], filepath.Join(t.TempDir(), "out.jpg")); err != nil {
+		t.Fatalf("copyTo error: %v", err)
+	}
+	leftover := filepath.Join(dir, "image.jpg.123.tmp")
+	os.WriteFile(leftover, []byte("partial"), 0644)
+
+	removed, err := cache.prune(2500)
+	if err != nil {
+		t.Fatalf("prune error: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d images, want 1", removed)
+	}
+	if cache.lookup(urls[0]) != nil {
+		t.Error("least recently used image was kept")
+	}
+	for _, url := range urls[1:] {
+		if cache.lookup(url) == nil {
+			t.Errorf("%s was pruned", url)
+		}
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Error("temporary file was not removed")
+	}
+}
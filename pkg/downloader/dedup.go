@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// extrafanartFileRegex matches the names written by DownloadExtrafanart
+var extrafanartFileRegex = regexp.MustCompile(`^extrafanart-(\d+)(\.[A-Za-z0-9]+)$`)
+
+// dedupeURLs removes repeated URLs, ignoring the scheme, host case and fragments
+func dedupeURLs(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	result := make([]string, 0, len(urls))
+	for _, url := range urls {
+		key := normalizeURLKey(url)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, url)
+	}
+	return result
+}
+
+// normalizeURLKey returns the comparison key for an image URL
+func normalizeURLKey(url string) string {
+	key := strings.TrimSpace(url)
+	if i := strings.Index(key, "#"); i >= 0 {
+		key = key[:i]
+	}
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https:"), "http:")
+	key = strings.TrimPrefix(key, "//")
+
+	// Host names are case-insensitive, paths are not
+	if i := strings.Index(key, "/"); i >= 0 {
+		return strings.ToLower(key[:i]) + key[i:]
+	}
+	return strings.ToLower(key)
+}
+
+// removeDuplicateImages deletes extrafanart files whose content is identical to an
+// earlier one and renumbers the rest so the sequence has no gaps.
+// It returns the number of removed files.
+func removeDuplicateImages(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	type image struct {
+		index int
+		name  string
+		ext   string
+	}
+	var images []image
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := extrafanartFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(matches[1])
+		images = append(images, image{index: index, name: entry.Name(), ext: matches[2]})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].index < images[j].index })
+
+	seen := make(map[string]bool, len(images))
+	var kept []image
+	removed := 0
+	for _, img := range images {
+		path := filepath.Join(dir, img.name)
+		sum, err := fileHash(path)
+		if err != nil {
+			return removed, err
+		}
+		if seen[sum] {
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+			removed++
+			continue
+		}
+		seen[sum] = true
+		kept = append(kept, img)
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	// Renumber in order; kept[i].index >= i+1, so a rename never overwrites a kept file
+	for i, img := range kept {
+		name := fmt.Sprintf("extrafanart-%d%s", i+1, img.ext)
+		if name == img.name {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, img.name), filepath.Join(dir, name)); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// fileHash returns the SHA-256 of the file content
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
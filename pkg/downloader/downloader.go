@@ -2,8 +2,10 @@ package downloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
@@ -17,6 +19,8 @@ import (
 type Downloader struct {
 	config     *config.Config
 	httpClient *httpclient.Client
+	cache      *imageCache
+	slots      chan struct{} // shared by every download of this downloader, nil means unlimited
 }
 
 // DownloadTask represents a download task
@@ -38,12 +42,41 @@ type DownloadResult struct {
 func New(cfg *config.Config) *Downloader {
 	return &Downloader{
 		config:     cfg,
-		httpClient: httpclient.NewClient(&cfg.Proxy),
+		httpClient: httpclient.NewClient(cfg.GetImageProxy()),
+		cache:      newImageCache(cfg.Common.ImageCacheDir, int64(cfg.Common.ImageCacheMaxSize)*1024*1024),
+		slots:      newDownloadSlots(cfg.Common.MaxConcurrentDownloads),
 	}
 }
 
-// DownloadFile downloads a single file
+// newDownloadSlots returns the semaphore that bounds concurrent downloads (nil when n <= 0)
+func newDownloadSlots(n int) chan struct{} {
+	if n <= 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
+
+// acquire waits for a download slot; the returned function gives it back
+func (d *Downloader) acquire(ctx context.Context) (func(), error) {
+	if d.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case d.slots <- struct{}{}:
+		return func() { <-d.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DownloadFile downloads a single file, revalidating it against the image cache when enabled
 func (d *Downloader) DownloadFile(ctx context.Context, url, filePath string, headers map[string]string) error {
+	return d.download(ctx, url, filePath, headers, d.cache)
+}
+
+// download fetches url into filePath; with a cache, a stored copy is reused
+// when the server reports it unchanged
+func (d *Downloader) download(ctx context.Context, url, filePath string, headers map[string]string, cache *imageCache) error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -58,6 +91,21 @@ func (d *Downloader) DownloadFile(ctx context.Context, url, filePath string, hea
 		}
 	}
 
+	var cached *cacheEntry
+	if cache != nil {
+		if cached = cache.lookup(url); cached != nil {
+			headers = cache.conditionalHeaders(headers, cached)
+		}
+	}
+
+	// Hold a slot until the body is written, so that the cap counts whole downloads
+	// across every movie in flight, not just the time to the response headers
+	release, err := d.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer release()
+
 	// Download the file
 	resp, err := d.httpClient.Get(ctx, url, headers)
 	if err != nil {
@@ -65,37 +113,76 @@ func (d *Downloader) DownloadFile(ctx context.Context, url, filePath string, hea
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if err := cache.copyTo(url, filePath); err != nil {
+			return fmt.Errorf("failed to restore cached %s: %w", url, err)
+		}
+		cache.hits.Add(1)
+		logger.Info("Downloaded (cached): %s", filepath.Base(filePath))
+		return nil
+	}
+
 	if resp.StatusCode != 200 {
 		return fmt.Errorf("download failed with status %d: %s", resp.StatusCode, url)
 	}
 
-	// Create the file
-	file, err := os.Create(filePath)
+	// Write to a temporary file first so an interrupted download never
+	// looks like a complete one, then rename it into place
+	tempPath := filePath + d.tempSuffix()
+	file, err := os.Create(tempPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+		return fmt.Errorf("failed to create file %s: %w", tempPath, err)
 	}
-	defer file.Close()
 
 	// Copy data
 	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		// Remove partially downloaded file
-		os.Remove(filePath)
+		os.Remove(tempPath)
 		return fmt.Errorf("failed to write file %s: %w", filePath, err)
 	}
 
+	if err := os.Rename(tempPath, filePath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to rename %s to %s: %w", tempPath, filePath, err)
+	}
+
+	if cache != nil {
+		cache.misses.Add(1)
+		cache.store(url, filePath, resp.Header)
+	}
+
 	logger.Info("Downloaded: %s", filepath.Base(filePath))
 	return nil
 }
 
+// DefaultTempSuffix is appended to files while they are being downloaded
+const DefaultTempSuffix = ".part"
+
+// tempSuffix returns the configured suffix for in-progress downloads
+func (d *Downloader) tempSuffix() string {
+	if d.config.Common.DownloadTempSuffix == "" {
+		return DefaultTempSuffix
+	}
+	return d.config.Common.DownloadTempSuffix
+}
+
 // DownloadFiles downloads multiple files in parallel
 func (d *Downloader) DownloadFiles(ctx context.Context, tasks []DownloadTask) []DownloadResult {
+	return d.downloadFiles(ctx, tasks, d.config.Extrafanart.ParallelDownload)
+}
+
+// downloadFiles downloads tasks with at most maxWorkers concurrent downloads (0 = 5).
+// Results come back in completion order; each carries its task.
+func (d *Downloader) downloadFiles(ctx context.Context, tasks []DownloadTask, maxWorkers int) []DownloadResult {
 	if len(tasks) == 0 {
 		return nil
 	}
 
 	// Determine number of workers
-	maxWorkers := d.config.Extrafanart.ParallelDownload
 	if maxWorkers <= 0 {
 		maxWorkers = 5
 	}
@@ -184,6 +271,10 @@ func (d *Downloader) DownloadExtrafanart(ctx context.Context, urls []string, sav
 		return fmt.Errorf("failed to create extrafanart directory: %w", err)
 	}
 
+	if d.config.Extrafanart.Dedup {
+		urls = dedupeURLs(urls)
+	}
+
 	// Create download tasks
 	var tasks []DownloadTask
 	for i, url := range urls {
@@ -213,26 +304,39 @@ func (d *Downloader) DownloadExtrafanart(ctx context.Context, urls []string, sav
 		return nil
 	}
 
-	// Download in parallel
+	// Download in parallel; file names were fixed above, so completion order does not matter
 	results := d.DownloadFiles(ctx, tasks)
 
-	// Count successes and failures
+	// Count successes and collect failures, one failed image does not stop the others
 	successCount := 0
-	failureCount := 0
+	var failures []error
 	for _, result := range results {
 		if result.Success {
 			successCount++
 		} else {
-			failureCount++
+			failures = append(failures, fmt.Errorf("%s: %w", filepath.Base(result.Task.FilePath), result.Error))
 		}
 	}
 
-	if failureCount > 0 {
-		logger.Warn("Failed to download %d/%d extrafanart images", failureCount, len(results))
+	if len(failures) > 0 {
+		logger.Warn("Failed to download %d/%d extrafanart images", len(failures), len(results))
 	} else {
 		logger.Info("Successfully downloaded %d extrafanart images", successCount)
 	}
 
+	// The same still is sometimes served under different URLs
+	if d.config.Extrafanart.Dedup && successCount > 0 {
+		removed, err := removeDuplicateImages(extrafanartDir)
+		if err != nil {
+			logger.Warn("Failed to remove duplicate extrafanart: %v", err)
+		} else if removed > 0 {
+			logger.Info("Removed %d duplicate extrafanart images", removed)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d extrafanart images failed: %w", len(failures), len(results), errors.Join(failures...))
+	}
 	return nil
 }
 
@@ -311,7 +415,17 @@ func (d *Downloader) DownloadTrailer(ctx context.Context, url, savePath string,
 		return fmt.Errorf("trailer URL is empty")
 	}
 
-	return d.DownloadFile(ctx, url, savePath, headers)
+	// Trailers are too large to keep in the image cache
+	return d.download(ctx, url, savePath, headers, nil)
+}
+
+// CacheStats returns how many downloads were served from the image cache and how
+// many had to be fetched; both are 0 when the cache is disabled
+func (d *Downloader) CacheStats() (hits, misses uint64) {
+	if d.cache == nil {
+		return 0, 0
+	}
+	return d.cache.hits.Load(), d.cache.misses.Load()
 }
 
 // Close closes the downloader and cleans up resources
@@ -7,6 +7,8 @@ import (
 	_ "image/png"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"movie-data-capture/pkg/logger"
 )
@@ -21,8 +23,23 @@ type Face struct {
 type FaceDetector struct {
 	modelPath string
 	enabled   bool
+
+	// The model is loaded lazily exactly once and then shared by all goroutines
+	loadOnce sync.Once
+	loadErr  error
+}
+
+// builtinModels are model names that do not refer to a file on disk
+var builtinModels = map[string]bool{
+	"hog": true,
+	"cnn": true,
 }
 
+var (
+	sharedMu        sync.Mutex
+	sharedDetectors = make(map[string]*FaceDetector)
+)
+
 // NewFaceDetector creates a new face detector
 func NewFaceDetector(modelPath string) *FaceDetector {
 	return &FaceDetector{
@@ -31,6 +48,45 @@ func NewFaceDetector(modelPath string) *FaceDetector {
 	}
 }
 
+// SharedFaceDetector returns a process-wide detector for modelPath so the model
+// is only loaded once no matter how many image processors are created
+func SharedFaceDetector(modelPath string) *FaceDetector {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if fd, ok := sharedDetectors[modelPath]; ok {
+		return fd
+	}
+	fd := NewFaceDetector(modelPath)
+	sharedDetectors[modelPath] = fd
+	return fd
+}
+
+// Load initializes the detection model up front instead of on the first detection.
+// It does nothing for a disabled detector and is safe to call concurrently.
+func (fd *FaceDetector) Load() error {
+	if !fd.enabled {
+		return nil
+	}
+	return fd.load()
+}
+
+// load initializes the detection model. It is safe to call concurrently.
+func (fd *FaceDetector) load() error {
+	fd.loadOnce.Do(func() {
+		if builtinModels[strings.ToLower(fd.modelPath)] {
+			logger.Debug("Using built-in face detection model: %s", fd.modelPath)
+			return
+		}
+		if _, err := os.Stat(fd.modelPath); err != nil {
+			fd.loadErr = fmt.Errorf("failed to load face model %s: %w", fd.modelPath, err)
+			return
+		}
+		logger.Debug("Loaded face detection model: %s", fd.modelPath)
+	})
+	return fd.loadErr
+}
+
 // DetectFaces detects faces in the given image file
 // Returns the center position of the rightmost face and the top position
 func (fd *FaceDetector) DetectFaces(imagePath string) (centerX, topY int, found bool) {
@@ -39,6 +95,11 @@ func (fd *FaceDetector) DetectFaces(imagePath string) (centerX, topY int, found
 		return 0, 0, false
 	}
 
+	if err := fd.load(); err != nil {
+		logger.Warn("Face detection unavailable: %v", err)
+		return 0, 0, false
+	}
+
 	// For now, implement a simple fallback that mimics face detection behavior
 	// In a real implementation, this would use OpenCV or similar library
 	faces, err := fd.detectFacesSimulated(imagePath)
@@ -0,0 +1,44 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// ErrRetryBudgetExhausted is returned instead of retrying once the shared budget is used up
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+type retryBudgetKey struct{}
+
+// retryBudget is the number of retries left for everything sharing a context
+type retryBudget struct {
+	remaining atomic.Int64
+}
+
+// WithRetryBudget returns a context whose requests share at most retries retries in
+// total, across clients and sources. retries <= 0 leaves retrying unlimited.
+func WithRetryBudget(ctx context.Context, retries int) context.Context {
+	if retries <= 0 {
+		return ctx
+	}
+	budget := &retryBudget{}
+	budget.remaining.Store(int64(retries))
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// takeRetry consumes one retry from the context budget, reporting whether the
+// retry may go ahead. Contexts without a budget always may retry.
+func takeRetry(ctx context.Context) bool {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+	if budget.remaining.Add(-1) < 0 {
+		logger.Debug("Retry budget exhausted, not retrying")
+		return false
+	}
+	return true
+}
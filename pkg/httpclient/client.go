@@ -50,10 +50,10 @@ func NewClient(cfg *config.ProxyConfig) *Client {
 // buildHTTPClient builds HTTP client with proxy and TLS configuration
 func (c *Client) buildHTTPClient() *http.Client {
 	transport := &http.Transport{
-		DialContext: (&net.Dialer{
+		DialContext: NewDialContext(&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		}),
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
@@ -112,7 +112,7 @@ func (c *Client) buildHTTPClient() *http.Client {
 
 	return &http.Client{
 		Timeout:   c.timeout,
-		Transport: transport,
+		Transport: WrapTransport(transport),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			if len(via) >= 10 {
 				return fmt.Errorf("stopped after 10 redirects")
@@ -194,11 +194,19 @@ func (c *Client) GetString(ctx context.Context, url string, headers map[string]s
 	return string(data), nil
 }
 
-// doRequestWithRetry performs HTTP request with retry mechanism
+// doRequestWithRetry performs HTTP request with retry mechanism. Requests carrying a
+// scrape retry policy (WithScrapeRetry) are also retried on transient statuses.
 func (c *Client) doRequestWithRetry(ctx context.Context, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	if policy := scrapeRetryFromContext(ctx); policy != nil {
+		return c.doRequestWithPolicy(ctx, policy, method, url, body, headers)
+	}
+	return c.doRequest(ctx, method, url, body, headers, c.retry)
+}
+
+// doRequest performs HTTP request, retrying network errors up to maxRetries attempts
+func (c *Client) doRequest(ctx context.Context, method, url string, body io.Reader, headers map[string]string, maxRetries int) (*http.Response, error) {
 	var lastErr error
 	
-	maxRetries := c.retry
 	if maxRetries <= 0 {
 		maxRetries = 1
 	}
@@ -221,8 +229,15 @@ func (c *Client) doRequestWithRetry(ctx context.Context, method, url string, bod
 		if err != nil {
 			lastErr = err
 			if attempt < maxRetries-1 {
+				if !takeRetry(ctx) {
+					return nil, fmt.Errorf("request failed: %w (%w)", err, ErrRetryBudgetExhausted)
+				}
 				// Wait before retry
-				time.Sleep(time.Duration(attempt+1) * time.Second)
+				select {
+				case <-time.After(time.Duration(attempt+1) * time.Second):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
 				continue
 			}
 			return nil, fmt.Errorf("request failed after %d attempts: %w", maxRetries, err)
@@ -5,7 +5,6 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
-	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
@@ -15,6 +14,7 @@ import (
 
 	"golang.org/x/net/proxy"
 	"golang.org/x/net/publicsuffix"
+	"movie-data-capture/pkg/random"
 )
 
 // CloudScraperClient mimics Python's cloudscraper functionality
@@ -71,6 +71,10 @@ func NewCloudScraperClientWithProxy(proxyConfig *ProxyConfig) (*CloudScraperClie
 
 	// Create transport with optimized settings
 	transport := &http.Transport{
+		DialContext: NewDialContext(&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}),
 		TLSClientConfig:       tlsConfig,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
@@ -101,7 +105,7 @@ func NewCloudScraperClientWithProxy(proxyConfig *ProxyConfig) (*CloudScraperClie
 	// Create HTTP client
 	client := &http.Client{
 		Jar:       jar,
-		Transport: transport,
+		Transport: WrapTransport(transport),
 		Timeout:   30 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Allow up to 10 redirects and copy important headers
@@ -138,7 +142,7 @@ func NewCloudScraperClientWithProxy(proxyConfig *ProxyConfig) (*CloudScraperClie
 // Get performs a GET request with anti-bot measures
 func (c *CloudScraperClient) Get(ctx context.Context, targetURL string, headers map[string]string) (*http.Response, error) {
 	// Add random delay to mimic human behavior
-	delay := time.Duration(rand.Intn(2000)+500) * time.Millisecond
+	delay := time.Duration(random.Intn(2000)+500) * time.Millisecond
 	time.Sleep(delay)
 
 	// Create request
@@ -162,8 +166,8 @@ func (c *CloudScraperClient) Get(ctx context.Context, targetURL string, headers
 // setRealisticHeaders sets headers that mimic a real browser
 func (c *CloudScraperClient) setRealisticHeaders(req *http.Request, targetURL string) {
 	// Rotate user agent occasionally
-	if rand.Float32() < 0.1 { // 10% chance to rotate
-		c.currentUA = c.userAgents[rand.Intn(len(c.userAgents))]
+	if random.Float32() < 0.1 { // 10% chance to rotate
+		c.currentUA = c.userAgents[random.Intn(len(c.userAgents))]
 	}
 
 	req.Header.Set("User-Agent", c.currentUA)
@@ -188,19 +192,10 @@ func (c *CloudScraperClient) setRealisticHeaders(req *http.Request, targetURL st
 		req.Header.Set("sec-ch-ua-platform", `"Windows"`)
 	}
 
-	// Set site-specific headers
+	// Set site-specific headers; site cookies come from the context (see WithCookies)
 	parsedURL, _ := url.Parse(targetURL)
-	if parsedURL != nil {
-		switch {
-		case strings.Contains(parsedURL.Host, "fc2.com"):
-			req.Header.Set("Referer", "https://adult.contents.fc2.com/")
-		case strings.Contains(parsedURL.Host, "javdb"):
-			req.Header.Set("Cookie", "over18=1; locale=zh")
-		case strings.Contains(parsedURL.Host, "javbus"):
-			req.Header.Set("Cookie", "existmag=all")
-		case strings.Contains(parsedURL.Host, "mgstage"):
-			req.Header.Set("Cookie", "adc=1")
-		}
+	if parsedURL != nil && strings.Contains(parsedURL.Host, "fc2.com") {
+		req.Header.Set("Referer", "https://adult.contents.fc2.com/")
 	}
 }
 
@@ -210,9 +205,13 @@ func (c *CloudScraperClient) performRequestWithRetry(req *http.Request, maxRetri
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
+			if !takeRetry(req.Context()) {
+				return nil, fmt.Errorf("request failed: %v (%w)", lastErr, ErrRetryBudgetExhausted)
+			}
+
 			// Exponential backoff with jitter
 			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			jitter := time.Duration(rand.Intn(1000)) * time.Millisecond
+			jitter := time.Duration(random.Intn(1000)) * time.Millisecond
 			time.Sleep(backoff + jitter)
 		}
 
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// cookiesKey is the context key for per-request cookies
+type cookiesKey struct{}
+
+// WithCookies returns a context whose requests carry the given cookies.
+// Scrapers use it to attach the cookies configured for a source (age check,
+// region, ...) without each scraper having to know about them.
+func WithCookies(ctx context.Context, cookies map[string]string) context.Context {
+	if len(cookies) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, cookiesKey{}, cookies)
+}
+
+// cookiesFromContext returns the cookies attached with WithCookies
+func cookiesFromContext(ctx context.Context) map[string]string {
+	cookies, _ := ctx.Value(cookiesKey{}).(map[string]string)
+	return cookies
+}
+
+// cookieTransport adds the context cookies to every request that does not
+// already carry a cookie with the same name
+type cookieTransport struct {
+	base http.RoundTripper
+}
+
+// NewCookieTransport wraps base so that cookies attached with WithCookies are sent
+func NewCookieTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cookieTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cookies := cookiesFromContext(req.Context())
+	if len(cookies) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	existing := make(map[string]bool)
+	for _, cookie := range req.Cookies() {
+		existing[cookie.Name] = true
+	}
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, value := range cookies {
+		if !existing[name] {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,240 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// resolver is the process-wide DNS resolver used by every client's dialer.
+// It limits concurrent lookups and caches results so that many workers hitting
+// cold connections do not flood the system resolver.
+var resolver = &cachingResolver{
+	entries: make(map[string]dnsEntry),
+}
+
+// dnsEntry is a cached lookup result
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// cachingResolver resolves host names with an optional concurrency cap and TTL cache
+type cachingResolver struct {
+	mu      sync.Mutex
+	sem     chan struct{}
+	max     int
+	ttl     time.Duration
+	entries map[string]dnsEntry
+	// lookup is net.DefaultResolver.LookupHost, replaceable in tests
+	lookup func(ctx context.Context, host string) ([]string, error)
+}
+
+// SetDNSOptions configures the shared resolver. maxLookups <= 0 disables the
+// concurrency cap; ttl <= 0 disables caching. Changing the TTL clears the cache.
+func SetDNSOptions(maxLookups int, ttl time.Duration) {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+
+	if maxLookups < 0 {
+		maxLookups = 0
+	}
+	if maxLookups != resolver.max {
+		resolver.max = maxLookups
+		if maxLookups == 0 {
+			resolver.sem = nil
+		} else {
+			resolver.sem = make(chan struct{}, maxLookups)
+		}
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	if ttl != resolver.ttl {
+		resolver.ttl = ttl
+		resolver.entries = make(map[string]dnsEntry)
+	}
+}
+
+// enabled reports whether lookups need to go through the resolver at all
+func (r *cachingResolver) enabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sem != nil || r.ttl > 0
+}
+
+// LookupHost returns the addresses of host, from the cache when possible
+func (r *cachingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	if entry, ok := r.entries[host]; ok && time.Now().Before(entry.expires) {
+		r.mu.Unlock()
+		return entry.addrs, nil
+	}
+	sem, ttl := r.sem, r.ttl
+	lookup := r.lookup
+	r.mu.Unlock()
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// Another worker may have resolved the host while this one was waiting
+		r.mu.Lock()
+		if entry, ok := r.entries[host]; ok && time.Now().Before(entry.expires) {
+			r.mu.Unlock()
+			return entry.addrs, nil
+		}
+		r.mu.Unlock()
+	}
+
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupHost
+	}
+	addrs, err := lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		r.mu.Lock()
+		r.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(ttl)}
+		r.mu.Unlock()
+	}
+	return addrs, nil
+}
+
+// NewDialContext returns a DialContext function for http.Transport that resolves
+// host names through the shared resolver before dialing with dialer.
+// The addresses are dialed like net.Dialer does for a host name, see dialAddrs.
+func NewDialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !resolver.enabled() {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		primaries, fallbacks := partitionAddrs(network, addrs)
+		if len(primaries) == 0 {
+			return nil, &net.DNSError{Err: "no addresses found", Name: host, IsNotFound: true}
+		}
+		return dialAddrs(ctx, dialer, network, port, primaries, fallbacks)
+	}
+}
+
+// partitionAddrs splits addrs into the family of the first usable address and the
+// other family, keeping the resolver's order. tcp4 and tcp6 keep only their family.
+func partitionAddrs(network string, addrs []string) (primaries, fallbacks []string) {
+	var primaryIsIPv4 bool
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		isIPv4 := ip.To4() != nil
+		if (network == "tcp4" && !isIPv4) || (network == "tcp6" && isIPv4) {
+			continue
+		}
+		if len(primaries) == 0 {
+			primaryIsIPv4 = isIPv4
+		}
+		if isIPv4 == primaryIsIPv4 {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	return primaries, fallbacks
+}
+
+// dialAddrs dials resolved addresses with Happy Eyeballs (RFC 6555), as net.Dialer
+// does for a host name: the primaries are tried in order, and the fallbacks race
+// them once dialer.FallbackDelay has passed or the primaries have failed.
+func dialAddrs(ctx context.Context, dialer *net.Dialer, network, port string, primaries, fallbacks []string) (net.Conn, error) {
+	if len(fallbacks) == 0 || dialer.FallbackDelay < 0 {
+		return dialSerial(ctx, dialer, network, port, append(primaries, fallbacks...))
+	}
+	delay := dialer.FallbackDelay
+	if delay == 0 {
+		delay = 300 * time.Millisecond
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan dialResult)
+	race := func(primary bool, addrs []string) {
+		conn, err := dialSerial(ctx, dialer, network, port, addrs)
+		select {
+		case results <- dialResult{conn: conn, err: err, primary: primary}:
+		case <-ctx.Done():
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}
+
+	go race(true, primaries)
+	fallbackTimer := time.NewTimer(delay)
+	defer fallbackTimer.Stop()
+
+	var primaryErr error
+	fallbackStarted, primaryDone, fallbackDone := false, false, false
+	for {
+		select {
+		case <-fallbackTimer.C:
+			fallbackStarted = true
+			go race(false, fallbacks)
+		case res := <-results:
+			if res.err == nil {
+				return res.conn, nil
+			}
+			if res.primary {
+				primaryDone, primaryErr = true, res.err
+				if !fallbackStarted {
+					fallbackTimer.Reset(0)
+				}
+			} else {
+				fallbackDone = true
+			}
+			if primaryDone && fallbackDone {
+				return nil, primaryErr
+			}
+		}
+	}
+}
+
+// dialSerial tries addrs in order until one connects
+func dialSerial(ctx context.Context, dialer *net.Dialer, network, port string, addrs []string) (net.Conn, error) {
+	var lastErr error
+	for _, ip := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLookup installs lookup on the shared resolver for the duration of the test
+func fakeLookup(t *testing.T, maxLookups int, ttl time.Duration, lookup func(ctx context.Context, host string) ([]string, error)) {
+	t.Helper()
+	SetDNSOptions(maxLookups, ttl)
+	resolver.mu.Lock()
+	resolver.lookup = lookup
+	resolver.mu.Unlock()
+	t.Cleanup(func() {
+		SetDNSOptions(0, 0)
+		resolver.mu.Lock()
+		resolver.lookup = nil
+		resolver.entries = make(map[string]dnsEntry)
+		resolver.mu.Unlock()
+	})
+}
+
+func TestResolver_Cache(t *testing.T) {
+	var calls int32
+	fakeLookup(t, 0, time.Minute, func(ctx context.Context, host string) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		return []string{"127.0.0.1"}, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := resolver.LookupHost(context.Background(), "example.test"); err != nil {
+			t.Fatalf("LookupHost error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("lookups with cache = %d, want 1", calls)
+	}
+
+	SetDNSOptions(0, 0)
+	for i := 0; i < 2; i++ {
+		resolver.LookupHost(context.Background(), "example.test")
+	}
+	if calls != 3 {
+		t.Errorf("lookups without cache = %d, want 3", calls)
+	}
+}
+
+func TestResolver_MaxLookups(t *testing.T) {
+	var inflight, peak int32
+	fakeLookup(t, 2, 0, func(ctx context.Context, host string) ([]string, error) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return []string{"127.0.0.1"}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolver.LookupHost(context.Background(), "example.test")
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("concurrent lookups = %d, want at most 2", peak)
+	}
+}
+
+func TestPartitionAddrs(t *testing.T) {
+	addrs := []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2"}
+	tests := []struct {
+		network   string
+		primaries []string
+		fallbacks []string
+	}{
+		{"tcp", []string{"2001:db8::1", "2001:db8::2"}, []string{"192.0.2.1", "192.0.2.2"}},
+		{"tcp4", []string{"192.0.2.1", "192.0.2.2"}, nil},
+		{"tcp6", []string{"2001:db8::1", "2001:db8::2"}, nil},
+	}
+	for _, tt := range tests {
+		primaries, fallbacks := partitionAddrs(tt.network, addrs)
+		if !reflect.DeepEqual(primaries, tt.primaries) || !reflect.DeepEqual(fallbacks, tt.fallbacks) {
+			t.Errorf("partitionAddrs(%s) = %v, %v, want %v, %v", tt.network, primaries, fallbacks, tt.primaries, tt.fallbacks)
+		}
+	}
+}
+
+func TestNewDialContext_ResolvedAddresses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	// 127.0.0.2 refuses the port, the next address is tried
+	fakeLookup(t, 1, 0, func(ctx context.Context, host string) ([]string, error) {
+		return []string{"127.0.0.2", "127.0.0.1"}, nil
+	})
+	dial := NewDialContext(&net.Dialer{Timeout: 5 * time.Second})
+	conn, err := dial(context.Background(), "tcp", net.JoinHostPort("example.test", port))
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDialAddrs_FallbackAfterPrimaryFails(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen error: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	// The fallback starts as soon as the primaries fail instead of after the delay
+	dialer := &net.Dialer{Timeout: 5 * time.Second, FallbackDelay: 10 * time.Second}
+	start := time.Now()
+	conn, err := dialAddrs(context.Background(), dialer, "tcp", port, []string{"127.0.0.2"}, []string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("dialAddrs error: %v", err)
+	}
+	conn.Close()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("dialAddrs took %v, fallback waited for the delay", elapsed)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
-	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
@@ -16,6 +15,7 @@ import (
 	"golang.org/x/net/proxy"
 	"movie-data-capture/internal/config"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/random"
 )
 
 var userAgents = []string{
@@ -61,10 +61,10 @@ func NewImprovedClient(cfg *config.ProxyConfig) *ImprovedClient {
 // buildHTTPClient builds HTTP client with improved configuration
 func (c *ImprovedClient) buildHTTPClient() *http.Client {
 	transport := &http.Transport{
-		DialContext: (&net.Dialer{
+		DialContext: NewDialContext(&net.Dialer{
 			Timeout:   30 * time.Second,
 			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		}),
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
@@ -118,7 +118,7 @@ func (c *ImprovedClient) buildHTTPClient() *http.Client {
 
 	return &http.Client{
 		Timeout:   c.timeout,
-		Transport: transport,
+		Transport: WrapTransport(transport),
 		Jar:       c.jar, // Enable cookie jar for session management
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Allow up to 10 redirects
@@ -146,6 +146,10 @@ func (c *ImprovedClient) doRequestWithSession(ctx context.Context, method, url s
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		if attempt > 0 {
+			if !takeRetry(ctx) {
+				return nil, fmt.Errorf("request failed: %v (%w)", lastErr, ErrRetryBudgetExhausted)
+			}
+
 			// Wait before retry with exponential backoff
 			waitTime := time.Duration(attempt) * time.Second
 			logger.Debug("Retrying request in %v (attempt %d/%d)", waitTime, attempt+1, maxRetries)
@@ -170,7 +174,7 @@ func (c *ImprovedClient) doRequestWithSession(ctx context.Context, method, url s
 
 		// Add random delay to mimic human behavior
 		if attempt > 0 {
-			delay := time.Duration(500+rand.Intn(1000)) * time.Millisecond
+			delay := time.Duration(500+random.Intn(1000)) * time.Millisecond
 			time.Sleep(delay)
 		}
 
@@ -259,7 +263,7 @@ func (c *ImprovedClient) GetCookies(urlStr string) ([]*http.Cookie, error) {
 
 // getRandomUserAgent returns a random user agent
 func getRandomUserAgent() string {
-	return userAgents[rand.Intn(len(userAgents))]
+	return userAgents[random.Intn(len(userAgents))]
 }
 
 // parseProxy parses proxy configuration
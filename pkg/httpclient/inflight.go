@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// inflight holds the global outbound request budget shared by every client
+// (scraping, image downloads, trailers...). A nil semaphore means unlimited.
+var inflight struct {
+	mu  sync.RWMutex
+	sem chan struct{}
+	max int
+}
+
+// SetMaxInflightRequests caps the total number of concurrent outbound HTTP requests.
+// n <= 0 disables the limit. A request counts as in flight until its response
+// body has been fully read or closed.
+func SetMaxInflightRequests(n int) {
+	inflight.mu.Lock()
+	defer inflight.mu.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	if n == inflight.max {
+		return
+	}
+
+	inflight.max = n
+	if n == 0 {
+		inflight.sem = nil
+		return
+	}
+	inflight.sem = make(chan struct{}, n)
+}
+
+// currentInflightSemaphore returns the active semaphore (nil when unlimited)
+func currentInflightSemaphore() chan struct{} {
+	inflight.mu.RLock()
+	defer inflight.mu.RUnlock()
+	return inflight.sem
+}
+
+// limitedTransport acquires a slot of the global budget for every round trip
+type limitedTransport struct {
+	base http.RoundTripper
+}
+
+// NewLimitedTransport wraps base so that requests are subject to the global in-flight cap
+func NewLimitedTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &limitedTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sem := currentInflightSemaphore()
+	if sem == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	select {
+	case sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	release := func() { <-sem }
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody returns the in-flight slot once the body is exhausted or closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// requestLogging toggles the request/response trace for every client
+var requestLogging atomic.Bool
+
+// redactedHeaders are never written to the trace
+var redactedHeaders = []string{"Cookie", "Set-Cookie", "Authorization", "Proxy-Authorization"}
+
+// SetRequestLogging enables or disables the debug trace of every outbound HTTP request
+func SetRequestLogging(enabled bool) {
+	requestLogging.Store(enabled)
+}
+
+// WrapTransport applies the shared middlewares (context cookies, request logging,
+// adaptive per-host pacing, the global in-flight cap and per-source proxies) to base. Every client in
+// the project should build its transport with it.
+func WrapTransport(base http.RoundTripper) http.RoundTripper {
+	return NewCookieTransport(NewLoggingTransport(NewPacingTransport(NewLimitedTransport(NewProxyTransport(base)))))
+}
+
+// loggingTransport logs method, URL, status, size and latency of each request
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+// NewLoggingTransport wraps base with request/response logging at debug level.
+// Logging only happens while SetRequestLogging(true) is in effect.
+func NewLoggingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &loggingTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !requestLogging.Load() {
+		return t.base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	target := req.URL.Redacted()
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		logger.Debug("[HTTP] %s %s -> error after %v: %v%s", req.Method, target, time.Since(start).Round(time.Millisecond), err, redactedSummary(req.Header, nil))
+		return resp, err
+	}
+	if resp.Body == nil {
+		logger.Debug("[HTTP] %s %s -> %d, 0 bytes, %v%s", req.Method, target, resp.StatusCode, time.Since(start).Round(time.Millisecond), redactedSummary(req.Header, resp.Header))
+		return resp, nil
+	}
+
+	// The size is only known once the body has been consumed
+	resp.Body = &loggingBody{
+		ReadCloser: resp.Body,
+		log: func(n int64) {
+			logger.Debug("[HTTP] %s %s -> %d, %d bytes, %v%s", req.Method, target, resp.StatusCode, n, time.Since(start).Round(time.Millisecond), redactedSummary(req.Header, resp.Header))
+		},
+	}
+	return resp, nil
+}
+
+// redactedSummary notes which sensitive headers were present without logging their values
+func redactedSummary(reqHeader, respHeader http.Header) string {
+	summary := ""
+	for _, name := range redactedHeaders {
+		if reqHeader.Get(name) != "" || (respHeader != nil && respHeader.Get(name) != "") {
+			summary += " " + name + "=<redacted>"
+		}
+	}
+	return summary
+}
+
+// loggingBody counts the bytes read and logs once the body is exhausted or closed
+type loggingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	log  func(n int64)
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil {
+		b.once.Do(func() { b.log(b.n) })
+	}
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.log(b.n) })
+	return err
+}
@@ -0,0 +1,158 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// DefaultAdaptiveMaxDelay caps the adaptive gap between requests to one host
+const DefaultAdaptiveMaxDelay = 10 * time.Second
+
+// healthSmoothing is the weight of the newest sample in the per-host averages
+const healthSmoothing = 0.3
+
+// hostHealth tracks the smoothed latency and error rate of one host
+type hostHealth struct {
+	latency   float64 // seconds
+	errorRate float64 // 0..1
+	samples   int
+	next      time.Time
+}
+
+// pacing spaces requests to each host according to its measured health.
+// It is disabled until SetAdaptivePacing(true, ...) is called.
+var pacing struct {
+	mu       sync.Mutex
+	enabled  bool
+	maxDelay time.Duration
+	hosts    map[string]*hostHealth
+}
+
+// SetAdaptivePacing enables or disables adaptive per-host request spacing. The gap
+// between two requests to a host follows its recent latency and grows with its
+// error rate, capped at maxDelay (0 = DefaultAdaptiveMaxDelay).
+func SetAdaptivePacing(enabled bool, maxDelay time.Duration) {
+	if maxDelay <= 0 {
+		maxDelay = DefaultAdaptiveMaxDelay
+	}
+
+	pacing.mu.Lock()
+	defer pacing.mu.Unlock()
+	pacing.enabled = enabled
+	pacing.maxDelay = maxDelay
+	if pacing.hosts == nil {
+		pacing.hosts = make(map[string]*hostHealth)
+	}
+}
+
+// HostDelay returns the current adaptive gap between requests to host
+func HostDelay(host string) time.Duration {
+	pacing.mu.Lock()
+	defer pacing.mu.Unlock()
+
+	health, ok := pacing.hosts[strings.ToLower(host)]
+	if !ok {
+		return 0
+	}
+	return health.delay(pacing.maxDelay)
+}
+
+// delay converts the host health into a gap: a healthy host is spaced by its own
+// latency, and every 10% of failing requests adds another latency on top
+func (h *hostHealth) delay(maxDelay time.Duration) time.Duration {
+	d := time.Duration(h.latency * (1 + 10*h.errorRate) * float64(time.Second))
+	if h.errorRate > 0 && d < time.Second {
+		d = time.Second
+	}
+	if d > maxDelay {
+		d = maxDelay
+	}
+	return d
+}
+
+// reserveHost returns how long a request to host has to wait for its slot
+func reserveHost(host string) time.Duration {
+	pacing.mu.Lock()
+	defer pacing.mu.Unlock()
+
+	if !pacing.enabled {
+		return 0
+	}
+	health, ok := pacing.hosts[host]
+	if !ok {
+		health = &hostHealth{}
+		pacing.hosts[host] = health
+	}
+
+	now := time.Now()
+	start := now
+	if health.next.After(now) {
+		start = health.next
+	}
+	health.next = start.Add(health.delay(pacing.maxDelay))
+	return start.Sub(now)
+}
+
+// recordHost folds the outcome of one request into the host averages
+func recordHost(host string, latency time.Duration, failed bool) {
+	pacing.mu.Lock()
+	defer pacing.mu.Unlock()
+
+	health, ok := pacing.hosts[host]
+	if !ok {
+		return
+	}
+
+	errorSample := 0.0
+	if failed {
+		errorSample = 1
+	}
+	if health.samples == 0 {
+		health.latency = latency.Seconds()
+		health.errorRate = errorSample
+	} else {
+		health.latency += healthSmoothing * (latency.Seconds() - health.latency)
+		health.errorRate += healthSmoothing * (errorSample - health.errorRate)
+	}
+	health.samples++
+}
+
+// pacingTransport delays requests per host and measures their latency and failures
+type pacingTransport struct {
+	base http.RoundTripper
+}
+
+// NewPacingTransport wraps base with adaptive per-host request spacing.
+// Nothing is delayed or measured while pacing is disabled.
+func NewPacingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &pacingTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *pacingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	wait := reserveHost(host)
+	if wait > 0 {
+		logger.Debug("Adaptive pacing: waiting %v before %s", wait.Round(time.Millisecond), host)
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	recordHost(host, time.Since(start), failed)
+	return resp, err
+}
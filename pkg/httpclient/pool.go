@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"movie-data-capture/internal/config"
+)
+
+// ClientPool shares one ImprovedClient per host, so that keep-alive connections
+// and session cookies (e.g. age verification) are reused across pages and movies
+// instead of paying a new TLS handshake for every request
+type ClientPool struct {
+	cfg     *config.ProxyConfig
+	mu      sync.Mutex
+	clients map[string]*ImprovedClient
+}
+
+// NewClientPool creates an empty pool whose clients use cfg
+func NewClientPool(cfg *config.ProxyConfig) *ClientPool {
+	return &ClientPool{
+		cfg:     cfg,
+		clients: make(map[string]*ImprovedClient),
+	}
+}
+
+// GetClient returns the client for the host of rawURL, creating it on first use
+func (p *ClientPool) GetClient(rawURL string) *ImprovedClient {
+	host := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	host = strings.ToLower(host)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	client, ok := p.clients[host]
+	if !ok {
+		client = NewImprovedClient(p.cfg)
+		p.clients[host] = client
+	}
+	return client
+}
+
+// Close closes the idle connections of every pooled client
+func (p *ClientPool) Close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for host, client := range p.clients {
+		client.httpClient.CloseIdleConnections()
+		delete(p.clients, host)
+	}
+}
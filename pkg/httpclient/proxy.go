@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/proxy"
+	"movie-data-capture/internal/config"
+)
+
+// proxyKey is the context key for a per-request proxy override
+type proxyKey struct{}
+
+// WithProxy returns a context whose requests go through cfg instead of the proxy the
+// client was built with. A cfg with Switch off sends the requests directly.
+// Scrapers use it to apply the proxy configured for a source (proxy.per_source).
+func WithProxy(ctx context.Context, cfg *config.ProxyConfig) context.Context {
+	if cfg == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyKey{}, cfg)
+}
+
+// proxyFromContext returns the proxy attached with WithProxy
+func proxyFromContext(ctx context.Context) *config.ProxyConfig {
+	cfg, _ := ctx.Value(proxyKey{}).(*config.ProxyConfig)
+	return cfg
+}
+
+// DescribeProxy returns a short description of cfg for logs, e.g. "socks5://127.0.0.1:1080" or "direct"
+func DescribeProxy(cfg *config.ProxyConfig) string {
+	if cfg == nil || !cfg.Switch || cfg.Proxy == "" {
+		return "direct"
+	}
+	if strings.Contains(cfg.Proxy, "://") {
+		return cfg.Proxy
+	}
+	return cfg.Type + "://" + cfg.Proxy
+}
+
+// proxyTransport sends requests carrying a WithProxy override through a transport
+// built for that proxy; other requests use base
+type proxyTransport struct {
+	base http.RoundTripper
+
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+// NewProxyTransport wraps base so that proxy overrides attached with WithProxy are honored
+func NewProxyTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &proxyTransport{base: base, transports: make(map[string]http.RoundTripper)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cfg := proxyFromContext(req.Context())
+	if cfg == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	transport, err := t.transportFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// transportFor returns the cached transport for cfg, building it from base on first use
+func (t *proxyTransport) transportFor(cfg *config.ProxyConfig) (http.RoundTripper, error) {
+	key := DescribeProxy(cfg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if transport, ok := t.transports[key]; ok {
+		return transport, nil
+	}
+
+	base, ok := t.base.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy override needs an *http.Transport, got %T", t.base)
+	}
+	transport := base.Clone()
+	transport.Proxy = nil
+	transport.DialContext = NewDialContext(&net.Dialer{})
+
+	if cfg.Switch && cfg.Proxy != "" {
+		switch strings.ToLower(cfg.Type) {
+		case "socks5", "socks5h":
+			dialer, err := proxy.SOCKS5("tcp", strings.TrimPrefix(strings.TrimPrefix(cfg.Proxy, "socks5h://"), "socks5://"), nil, proxy.Direct)
+			if err != nil {
+				return nil, fmt.Errorf("invalid socks5 proxy %s: %w", cfg.Proxy, err)
+			}
+			transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.Dial(network, addr)
+			}
+		default:
+			proxyURL, err := url.Parse(key)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proxy %s: %w", cfg.Proxy, err)
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	t.transports[key] = transport
+	return transport, nil
+}
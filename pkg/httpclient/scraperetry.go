@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/retry"
+)
+
+type scrapeRetryKey struct{}
+
+// WithScrapeRetry returns a context whose requests are retried on transient failures:
+// network errors, 5xx and 429 responses. Other responses, such as 404, are returned
+// as they are. retries is the number of retries after the first attempt and delay
+// the initial backoff (0 uses retry.NetworkConfig's). retries <= 0 leaves ctx unchanged.
+func WithScrapeRetry(ctx context.Context, retries int, delay time.Duration) context.Context {
+	if retries <= 0 {
+		return ctx
+	}
+	policy := retry.NetworkConfig()
+	policy.MaxAttempts = retries + 1
+	if delay > 0 {
+		policy.InitialDelay = delay
+	}
+	return context.WithValue(ctx, scrapeRetryKey{}, policy)
+}
+
+// scrapeRetryFromContext returns the retry policy attached by WithScrapeRetry, or nil
+func scrapeRetryFromContext(ctx context.Context) *retry.Config {
+	policy, _ := ctx.Value(scrapeRetryKey{}).(*retry.Config)
+	return policy
+}
+
+// transientStatusError is a response status worth retrying
+type transientStatusError struct {
+	code int
+	url  string
+}
+
+func (e *transientStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.code, e.url)
+}
+
+// isTransientStatus reports whether a response status is worth retrying
+func isTransientStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// scrapeRetryIf retries transient statuses and network errors, unless the shared
+// retry budget ran out
+func scrapeRetryIf(err error) bool {
+	if errors.Is(err, ErrRetryBudgetExhausted) {
+		return false
+	}
+	var status *transientStatusError
+	return errors.As(err, &status) || retry.NetworkRetryIf(err)
+}
+
+// doRequestWithPolicy performs the request under a scrape retry policy. When the last
+// attempt still gets a transient status, that response is returned so that callers
+// see the status as they would without retrying.
+func (c *Client) doRequestWithPolicy(ctx context.Context, policy *retry.Config, method, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	// A body can only be sent again if it can be rewound
+	seeker, rewindable := body.(io.Seeker)
+	if body != nil && !rewindable {
+		return c.doRequest(ctx, method, url, body, headers, c.retry)
+	}
+
+	config := *policy
+	config.RetryIf = scrapeRetryIf
+
+	var resp, lastStatus *http.Response
+	attempt := 0
+	err := retry.RetryWithContext(ctx, func(ctx context.Context) error {
+		attempt++
+		if attempt > 1 {
+			if !takeRetry(ctx) {
+				return ErrRetryBudgetExhausted
+			}
+			logger.Debug("Retrying %s %s (attempt %d/%d)", method, url, attempt, config.MaxAttempts)
+		}
+		if seeker != nil {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		r, err := c.doRequest(ctx, method, url, body, headers, 1)
+		if err != nil {
+			return err
+		}
+		if isTransientStatus(r.StatusCode) {
+			// Keep the response in memory and close it right away so that its
+			// connection and in-flight slot are free for the next attempt
+			lastStatus = bufferResponse(r)
+			return &transientStatusError{code: r.StatusCode, url: url}
+		}
+		resp = r
+		return nil
+	}, &config)
+
+	if resp != nil {
+		return resp, nil
+	}
+	if lastStatus != nil && ctx.Err() == nil {
+		return lastStatus, nil
+	}
+	return nil, err
+}
+
+// maxBufferedErrorBody caps how much of a transient error response is kept in memory
+const maxBufferedErrorBody = 1 << 20
+
+// bufferResponse reads the body of r into memory and closes it, returning r with
+// an in-memory body
+func bufferResponse(r *http.Response) *http.Response {
+	data, _ := io.ReadAll(io.LimitReader(r.Body, maxBufferedErrorBody))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	return r
+}
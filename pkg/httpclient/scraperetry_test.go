@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"movie-data-capture/internal/config"
+)
+
+// statusSequence serves the given statuses in order, repeating the last one
+func statusSequence(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.WriteHeader(statuses[n-1])
+		io.WriteString(w, http.StatusText(statuses[n-1]))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestScrapeRetry_TransientStatusWithInflightLimit(t *testing.T) {
+	SetMaxInflightRequests(1)
+	defer SetMaxInflightRequests(0)
+
+	server, calls := statusSequence(t, http.StatusServiceUnavailable, http.StatusOK)
+	client := NewClient(&config.ProxyConfig{Timeout: 5, Retry: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.Get(WithScrapeRetry(ctx, 2, 10*time.Millisecond), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 after retrying the 503, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestScrapeRetry_NotFoundIsNotRetried(t *testing.T) {
+	server, calls := statusSequence(t, http.StatusNotFound, http.StatusOK)
+	client := NewClient(&config.ProxyConfig{Timeout: 5, Retry: 1})
+
+	resp, err := client.Get(WithScrapeRetry(context.Background(), 2, 10*time.Millisecond), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected the 404 to be returned, got %d", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("expected a single request, got %d", got)
+	}
+}
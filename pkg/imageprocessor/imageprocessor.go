@@ -1,24 +1,34 @@
 package imageprocessor
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"movie-data-capture/internal/config"
 	"movie-data-capture/pkg/facedetection"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/retry"
 )
 
+// DefaultCutRetries is the number of retries for CutImage when Face.CutRetries is not set
+const DefaultCutRetries = 2
+
 // ImageProcessor handles image cutting and processing operations
 type ImageProcessor struct {
 	config       *config.Config
 	faceDetector *facedetection.FaceDetector
+	// detectSlots limits how many face detections run at the same time
+	detectSlots chan struct{}
 }
 
 // NewImageProcessor creates a new image processor
@@ -29,15 +39,63 @@ func NewImageProcessor(cfg *config.Config) *ImageProcessor {
 		modelPath = cfg.Face.LocationsModel
 	}
 	
+	// Load the shared model once here rather than on the first cut
+	faceDetector := facedetection.SharedFaceDetector(modelPath)
+	if err := faceDetector.Load(); err != nil {
+		logger.Warn("Face detection unavailable: %v", err)
+	}
+
+	workers := cfg.Face.MaxWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
 	return &ImageProcessor{
 		config:       cfg,
-		faceDetector: facedetection.NewFaceDetector(modelPath),
+		faceDetector: faceDetector,
+		detectSlots:  make(chan struct{}, workers),
 	}
 }
 
-// CutImage performs image cutting based on imagecut parameter
+// detectFaces runs face detection on the shared model within the worker limit
+func (ip *ImageProcessor) detectFaces(srcPath string) (centerX, topY int, found bool) {
+	ip.detectSlots <- struct{}{}
+	defer func() { <-ip.detectSlots }()
+	return ip.faceDetector.DetectFaces(srcPath)
+}
+
+// CutImage performs image cutting based on imagecut parameter, retrying transient failures
 // imagecut: 0=copy, 1=crop with face detection, 4=crop with face detection for uncensored
 func (ip *ImageProcessor) CutImage(imagecut int, fanartPath, posterPath string, skipFaceRec bool) error {
+	retries := ip.config.Face.CutRetries
+	if retries <= 0 {
+		retries = DefaultCutRetries
+	}
+
+	retryConfig := &retry.Config{
+		MaxAttempts:     retries + 1,
+		InitialDelay:    200 * time.Millisecond,
+		MaxDelay:        2 * time.Second,
+		BackoffStrategy: retry.LinearBackoff,
+		RetryIf: func(err error) bool {
+			// A missing source image will not appear by retrying
+			return !errors.Is(err, fs.ErrNotExist)
+		},
+	}
+
+	attempt := 0
+	return retry.Retry(func() error {
+		attempt++
+		err := ip.cutImageOnce(imagecut, fanartPath, posterPath, skipFaceRec)
+		if err != nil && attempt <= retries {
+			logger.Debug("Image cut attempt %d failed: %v", attempt, err)
+		}
+		return err
+	}, retryConfig)
+}
+
+// cutImageOnce performs a single image cutting attempt
+func (ip *ImageProcessor) cutImageOnce(imagecut int, fanartPath, posterPath string, skipFaceRec bool) error {
 	if imagecut == 0 {
 		// Copy fanart to poster
 		return ip.copyImage(fanartPath, posterPath)
@@ -216,7 +274,7 @@ func (ip *ImageProcessor) cropWidth(img image.Image, width, height, imagecut int
 
 	if imagecut == 4 || (!skipFaceRec && imagecut == 1) {
 		// Try face detection
-		centerX, _, found := ip.faceDetector.DetectFaces(srcPath)
+		centerX, _, found := ip.detectFaces(srcPath)
 		
 		if found {
 			// Use detected face center for cropping
@@ -268,7 +326,7 @@ func (ip *ImageProcessor) cropHeight(img image.Image, width, height int, srcPath
 	var cropTop int
 	
 	// Try face detection for better vertical positioning
-	_, topY, found := ip.faceDetector.DetectFaces(srcPath)
+	_, topY, found := ip.detectFaces(srcPath)
 	
 	if found {
 		// Position crop area to include the detected face
@@ -314,6 +372,20 @@ func (ip *ImageProcessor) cropImageRect(img image.Image, rect image.Rectangle) i
 	return newImg
 }
 
+// VerifyImage decodes the image at path and returns an error if it is corrupt
+func (ip *ImageProcessor) VerifyImage(path string) error {
+	img, err := ip.openImage(path)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return fmt.Errorf("image has empty dimensions")
+	}
+	return nil
+}
+
 // openImage opens an image file
 func (ip *ImageProcessor) openImage(path string) (image.Image, error) {
 	file, err := os.Open(path)
@@ -0,0 +1,139 @@
+package library
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema 影片库索引的表结构
+//
+// 常用查询示例：
+//
+//	SELECT number, title, path FROM movies WHERE studio = 'S1';
+//	SELECT m.number, m.title FROM movies m JOIN movie_actors a ON a.path = m.path WHERE a.actor = '三上悠亜';
+const schema = `
+CREATE TABLE IF NOT EXISTS movies (
+	path       TEXT PRIMARY KEY,
+	number     TEXT NOT NULL,
+	title      TEXT NOT NULL DEFAULT '',
+	actors     TEXT NOT NULL DEFAULT '',
+	studio     TEXT NOT NULL DEFAULT '',
+	release    TEXT NOT NULL DEFAULT '',
+	source     TEXT NOT NULL DEFAULT '',
+	added_at   TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_movies_number ON movies(number);
+CREATE INDEX IF NOT EXISTS idx_movies_studio ON movies(studio);
+CREATE TABLE IF NOT EXISTS movie_actors (
+	path  TEXT NOT NULL REFERENCES movies(path) ON DELETE CASCADE,
+	actor TEXT NOT NULL,
+	PRIMARY KEY (path, actor)
+);
+CREATE INDEX IF NOT EXISTS idx_movie_actors_actor ON movie_actors(actor);
+`
+
+// Entry 索引中的一部影片
+type Entry struct {
+	Number  string
+	Title   string
+	Actors  []string
+	Studio  string
+	Release string
+	Source  string
+	Path    string // 影片所在目录
+}
+
+// Index 记录所有已处理影片元数据的SQLite数据库
+type Index struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// Open 打开（不存在时创建）位于path的影片库索引
+func Open(path string) (*Index, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create library index directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open library index: %w", err)
+	}
+	// SQLite 同一时间只允许一个写入者，多线程处理时统一走一个连接
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize library index: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Record 写入或更新一部影片；同一目录再次处理时保留首次加入时间
+func (i *Index) Record(entry Entry) error {
+	if entry.Path == "" {
+		return fmt.Errorf("library entry has no path")
+	}
+	path, err := filepath.Abs(entry.Path)
+	if err == nil {
+		entry.Path = path
+	}
+	now := time.Now().Format(time.RFC3339)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	tx, err := i.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin library index transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO movies (path, number, title, actors, studio, release, source, added_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			number = excluded.number,
+			title = excluded.title,
+			actors = excluded.actors,
+			studio = excluded.studio,
+			release = excluded.release,
+			source = excluded.source,
+			updated_at = excluded.updated_at`,
+		entry.Path, entry.Number, entry.Title, strings.Join(entry.Actors, ", "),
+		entry.Studio, entry.Release, entry.Source, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to write library entry: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM movie_actors WHERE path = ?`, entry.Path); err != nil {
+		return fmt.Errorf("failed to write library actors: %w", err)
+	}
+	for _, actor := range entry.Actors {
+		if actor = strings.TrimSpace(actor); actor == "" {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO movie_actors (path, actor) VALUES (?, ?)`, entry.Path, actor); err != nil {
+			return fmt.Errorf("failed to write library actors: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit library entry: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭数据库
+func (i *Index) Close() error {
+	return i.db.Close()
+}
@@ -0,0 +1,51 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIndex_Record(t *testing.T) {
+	dir := t.TempDir()
+	index, err := Open(filepath.Join(dir, "library.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer index.Close()
+
+	moviePath := filepath.Join(dir, "ABC-123")
+	entry := Entry{
+		Number: "ABC-123",
+		Title:  "first title",
+		Actors: []string{"Actor A", "Actor B"},
+		Studio: "Studio",
+		Path:   moviePath,
+	}
+	if err := index.Record(entry); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// Re-recording the same folder updates the row instead of adding a new one
+	entry.Title = "second title"
+	entry.Actors = []string{"Actor B"}
+	if err := index.Record(entry); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	var count int
+	var title string
+	if err := index.db.QueryRow(`SELECT COUNT(*), MAX(title) FROM movies`).Scan(&count, &title); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if count != 1 || title != "second title" {
+		t.Errorf("movies = %d rows, title %q; want 1 row, title %q", count, title, "second title")
+	}
+
+	var actors int
+	if err := index.db.QueryRow(`SELECT COUNT(*) FROM movie_actors WHERE actor = 'Actor A'`).Scan(&actors); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if actors != 0 {
+		t.Errorf("stale actor row was kept")
+	}
+}
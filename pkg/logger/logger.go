@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -57,6 +58,8 @@ type Logger struct {
 	enableColor bool
 	enableFile  bool
 	minLevel    LogLevel
+	// stderrOnly sends all console output to stderr, keeping stdout free for machine-readable output
+	stderrOnly bool
 }
 
 var (
@@ -157,13 +160,13 @@ func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, coloredLine)
 		} else {
-			fmt.Fprintln(os.Stdout, coloredLine)
+			fmt.Fprintln(l.stdout(), coloredLine)
 		}
 	} else {
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, logLine)
 		} else {
-			fmt.Fprintln(os.Stdout, logLine)
+			fmt.Fprintln(l.stdout(), logLine)
 		}
 	}
 	
@@ -201,6 +204,22 @@ func SetLevel(level LogLevel) {
 	logger.minLevel = level
 }
 
+// SetStderrOnly 将所有控制台日志输出到stderr，使stdout只包含程序输出（如JSON）
+func SetStderrOnly(enabled bool) {
+	logger := getDefaultLogger()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.stderrOnly = enabled
+}
+
+// stdout 返回非错误级别日志的控制台输出目标
+func (l *Logger) stdout() io.Writer {
+	if l.stderrOnly {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 // SetColorEnabled 设置是否启用颜色
 func SetColorEnabled(enabled bool) {
 	logger := getDefaultLogger()
@@ -240,13 +259,13 @@ func LogWithContext(level LogLevel, context map[string]interface{}, format strin
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, coloredLine)
 		} else {
-			fmt.Fprintln(os.Stdout, coloredLine)
+			fmt.Fprintln(logger.stdout(), coloredLine)
 		}
 	} else {
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, logLine)
 		} else {
-			fmt.Fprintln(os.Stdout, logLine)
+			fmt.Fprintln(logger.stdout(), logLine)
 		}
 	}
 	
@@ -319,13 +338,13 @@ func MultiLineLog(level LogLevel, title string, lines []string) {
 				if level == ERROR {
 					fmt.Fprintln(os.Stderr, coloredLine)
 				} else {
-					fmt.Fprintln(os.Stdout, coloredLine)
+					fmt.Fprintln(logger.stdout(), coloredLine)
 				}
 			} else {
 				if level == ERROR {
 					fmt.Fprintln(os.Stderr, logLine)
 				} else {
-					fmt.Fprintln(os.Stdout, logLine)
+					fmt.Fprintln(logger.stdout(), logLine)
 				}
 			}
 			
@@ -358,13 +377,13 @@ func HighlightLog(level LogLevel, format string, args ...interface{}) {
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, highlightedLine)
 		} else {
-			fmt.Fprintln(os.Stdout, highlightedLine)
+			fmt.Fprintln(logger.stdout(), highlightedLine)
 		}
 	} else {
 		if level == ERROR {
 			fmt.Fprintln(os.Stderr, logLine)
 		} else {
-			fmt.Fprintln(os.Stdout, logLine)
+			fmt.Fprintln(logger.stdout(), logLine)
 		}
 	}
 	
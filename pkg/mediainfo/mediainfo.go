@@ -0,0 +1,216 @@
+package mediainfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFFprobePath is used when no ffprobe path is configured
+const DefaultFFprobePath = "ffprobe"
+
+// probeTimeout limits how long a single ffprobe run may take
+const probeTimeout = 30 * time.Second
+
+// Info holds the properties of a video file reported by ffprobe
+type Info struct {
+	Width    int
+	Height   int
+	Codec    string
+	Duration time.Duration
+	Streams  []Stream
+}
+
+// Stream describes a single stream inside the container
+type Stream struct {
+	Index     int
+	CodecType string
+	CodecName string
+	Language  string
+	Title     string
+}
+
+// ffprobeOutput mirrors the JSON produced by ffprobe -show_streams -show_format
+type ffprobeOutput struct {
+	Streams []struct {
+		Index     int               `json:"index"`
+		CodecType string            `json:"codec_type"`
+		CodecName string            `json:"codec_name"`
+		Width     int               `json:"width"`
+		Height    int               `json:"height"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// Prober runs ffprobe against video files
+type Prober struct {
+	ffprobePath string
+}
+
+// NewProber creates a prober using the given ffprobe binary
+func NewProber(ffprobePath string) *Prober {
+	if ffprobePath == "" {
+		ffprobePath = DefaultFFprobePath
+	}
+	return &Prober{ffprobePath: ffprobePath}
+}
+
+// Available reports whether the ffprobe binary can be found
+func (p *Prober) Available() bool {
+	_, err := exec.LookPath(p.ffprobePath)
+	return err == nil
+}
+
+// Probe returns the media information of the file at path
+func (p *Prober) Probe(path string) (*Info, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.ffprobePath,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_streams",
+		"-show_format",
+		path,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return nil, fmt.Errorf("ffprobe failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	return parseOutput(output)
+}
+
+// parseOutput converts ffprobe JSON into Info
+func parseOutput(output []byte) (*Info, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &Info{}
+	for _, stream := range probe.Streams {
+		info.Streams = append(info.Streams, Stream{
+			Index:     stream.Index,
+			CodecType: stream.CodecType,
+			CodecName: stream.CodecName,
+			Language:  stream.Tags["language"],
+			Title:     stream.Tags["title"],
+		})
+
+		// The first video stream describes the movie
+		if stream.CodecType == "video" && info.Width == 0 {
+			info.Width = stream.Width
+			info.Height = stream.Height
+			info.Codec = stream.CodecName
+		}
+	}
+
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if info.Width == 0 || info.Height == 0 {
+		return info, fmt.Errorf("no video stream found")
+	}
+	return info, nil
+}
+
+// chineseLanguageCodes are language tags used for Chinese subtitle tracks
+var chineseLanguageCodes = map[string]bool{
+	"chi": true, "zho": true, "zh": true, "chs": true, "cht": true,
+	"zh-cn": true, "zh-tw": true, "zh-hans": true, "zh-hant": true,
+}
+
+// HasChineseSubtitle reports whether the container has an embedded Chinese subtitle track
+func (i *Info) HasChineseSubtitle() bool {
+	for _, stream := range i.Streams {
+		if stream.CodecType != "subtitle" {
+			continue
+		}
+		if chineseLanguageCodes[strings.ToLower(stream.Language)] {
+			return true
+		}
+		title := strings.ToLower(stream.Title)
+		if strings.Contains(title, "chinese") || strings.Contains(title, "中文") ||
+			strings.Contains(title, "简") || strings.Contains(title, "繁") {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolution returns the resolution label of the video, e.g. "1080p"
+func (i *Info) Resolution() string {
+	return ResolutionLabel(i.Width, i.Height)
+}
+
+// ResolutionLabel maps a frame size to the usual resolution label.
+// Width is considered as well so that cropped widescreen video (e.g. 1920x800)
+// is still labeled by its horizontal resolution.
+func ResolutionLabel(width, height int) string {
+	switch {
+	case width <= 0 || height <= 0:
+		return ""
+	case height >= 4320 || width >= 7680:
+		return "4320p"
+	case height >= 2160 || width >= 3840:
+		return "2160p"
+	case height >= 1440 || width >= 2560:
+		return "1440p"
+	case height >= 1080 || width >= 1920:
+		return "1080p"
+	case height >= 720 || width >= 1280:
+		return "720p"
+	case height >= 576:
+		return "576p"
+	case height >= 480:
+		return "480p"
+	default:
+		return fmt.Sprintf("%dp", height)
+	}
+}
+
+// codecLabels maps ffprobe codec names and filename markers to a display label
+var codecLabels = map[string]string{
+	"h264": "H264", "x264": "H264", "avc": "H264",
+	"hevc": "HEVC", "h265": "HEVC", "x265": "HEVC",
+	"av1": "AV1", "vp9": "VP9", "mpeg4": "MPEG4", "mpeg2video": "MPEG2", "wmv3": "WMV", "vc1": "VC1",
+}
+
+// CodecLabel returns the display label of a codec name, e.g. "HEVC" for "hevc";
+// unknown codecs are upper-cased
+func CodecLabel(codec string) string {
+	codec = strings.ToLower(strings.TrimSpace(codec))
+	if label, ok := codecLabels[codec]; ok {
+		return label
+	}
+	return strings.ToUpper(codec)
+}
+
+// codecNameRegex matches codec markers in file names such as x265, H.264 or HEVC
+var codecNameRegex = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(x26[45]|h\.?26[45]|hevc|avc|av1|vp9)(?:[^a-z0-9]|$)`)
+
+// CodecFromName returns the codec label marked in a file name, or "" if there is none
+func CodecFromName(name string) string {
+	match := codecNameRegex.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return CodecLabel(strings.ReplaceAll(match[1], ".", ""))
+}
@@ -0,0 +1,93 @@
+package mediainfo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolutionLabel(t *testing.T) {
+	tests := []struct {
+		width, height int
+		expected      string
+	}{
+		{3840, 2160, "2160p"},
+		{4096, 1716, "2160p"},
+		{1920, 1080, "1080p"},
+		{1920, 800, "1080p"},
+		{1280, 720, "720p"},
+		{720, 480, "480p"},
+		{320, 240, "240p"},
+		{0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := ResolutionLabel(tt.width, tt.height); got != tt.expected {
+			t.Errorf("ResolutionLabel(%d, %d) = %q, want %q", tt.width, tt.height, got, tt.expected)
+		}
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	output := []byte(`{
+  "streams": [
+    {"index": 0, "codec_type": "video", "codec_name": "hevc", "width": 3840, "height": 2160},
+    {"index": 1, "codec_type": "audio", "codec_name": "aac", "tags": {"language": "jpn"}},
+    {"index": 2, "codec_type": "subtitle", "codec_name": "subrip", "tags": {"language": "chi"}}
+  ],
+  "format": {"duration": "7200.500000"}
+}`)
+
+	info, err := parseOutput(output)
+	if err != nil {
+		t.Fatalf("parseOutput failed: %v", err)
+	}
+
+	if info.Resolution() != "2160p" {
+		t.Errorf("Expected 2160p, got %s", info.Resolution())
+	}
+	if info.Codec != "hevc" {
+		t.Errorf("Expected codec hevc, got %s", info.Codec)
+	}
+	if info.Duration != 7200*time.Second+500*time.Millisecond {
+		t.Errorf("Unexpected duration %v", info.Duration)
+	}
+	if len(info.Streams) != 3 || info.Streams[2].Language != "chi" {
+		t.Errorf("Unexpected streams %+v", info.Streams)
+	}
+}
+
+func TestMuxArgs(t *testing.T) {
+	subtitles := MuxableSubtitles([]string{"/m/ABC-123.zh.srt", "/m/ABC-123.smi", "/m/ABC-123_eng.ass"})
+	if len(subtitles) != 2 || subtitles[0].Language != "chi" || subtitles[1].Language != "eng" {
+		t.Fatalf("Unexpected subtitles %+v", subtitles)
+	}
+
+	args := strings.Join(muxArgs("/m/ABC-123.mkv", "/m/out.mkv", subtitles, "/m/ABC-123.chapters.txt", 1), " ")
+	expected := "-y -v error -i /m/ABC-123.mkv -i /m/ABC-123.zh.srt -i /m/ABC-123_eng.ass -f ffmetadata -i /m/ABC-123.chapters.txt " +
+		"-map 0 -map 1:s -map 2:s -map_chapters 3 -c copy -metadata:s:s:1 language=chi -metadata:s:s:2 language=eng /m/out.mkv"
+	if args != expected {
+		t.Errorf("muxArgs =\n%s\nwant\n%s", args, expected)
+	}
+}
+
+func TestCodecFromName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"ABC-123.x265.mkv", "HEVC"},
+		{"ABC-123 [H.264].mp4", "H264"},
+		{"ABC-123-HEVC.mp4", "HEVC"},
+		{"ABC-123.mp4", ""},
+		{"ABCX264-1.mp4", ""},
+	}
+	for _, tt := range tests {
+		if got := CodecFromName(tt.name); got != tt.expected {
+			t.Errorf("CodecFromName(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+	if got := CodecLabel("hevc"); got != "HEVC" {
+		t.Errorf("CodecLabel(hevc) = %q, want HEVC", got)
+	}
+}
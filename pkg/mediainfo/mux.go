@@ -0,0 +1,173 @@
+package mediainfo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultFFmpegPath is used when no ffmpeg path is configured
+const DefaultFFmpegPath = "ffmpeg"
+
+// muxTimeout limits how long a single ffmpeg remux may take.
+// Streams are copied, so this only has to cover reading and writing the file once.
+const muxTimeout = 30 * time.Minute
+
+// ChapterFileSuffix is the suffix of the chapter sidecar next to a video (<video name>.chapters.txt).
+// The file must be in ffmpeg's FFMETADATA format.
+const ChapterFileSuffix = ".chapters.txt"
+
+// muxableSubtitleExts are the external subtitle formats ffmpeg can stream copy into mkv.
+// For VobSub only the .idx is given to ffmpeg, it picks up the .sub itself.
+var muxableSubtitleExts = map[string]bool{
+	".srt": true, ".ass": true, ".ssa": true, ".vtt": true, ".idx": true, ".sup": true,
+}
+
+// subtitleLanguages maps language suffixes of subtitle files (movie.zh.srt) to ISO 639-2 codes
+var subtitleLanguages = map[string]string{
+	"zh": "chi", "chi": "chi", "zho": "chi", "chs": "chi", "cht": "chi", "sc": "chi", "tc": "chi",
+	"zh-cn": "chi", "zh-tw": "chi", "zh-hans": "chi", "zh-hant": "chi", "chinese": "chi",
+	"en": "eng", "eng": "eng", "english": "eng",
+	"ja": "jpn", "jp": "jpn", "jpn": "jpn", "japanese": "jpn",
+	"ko": "kor", "kor": "kor", "korean": "kor",
+}
+
+// Subtitle is an external subtitle file to be muxed
+type Subtitle struct {
+	Path     string
+	Language string // ISO 639-2 code, empty if unknown
+}
+
+// Muxer runs ffmpeg to copy external subtitles and chapters into an mkv
+type Muxer struct {
+	ffmpegPath string
+}
+
+// NewMuxer creates a muxer using the given ffmpeg binary
+func NewMuxer(ffmpegPath string) *Muxer {
+	if ffmpegPath == "" {
+		ffmpegPath = DefaultFFmpegPath
+	}
+	return &Muxer{ffmpegPath: ffmpegPath}
+}
+
+// Available reports whether the ffmpeg binary can be found
+func (m *Muxer) Available() bool {
+	_, err := exec.LookPath(m.ffmpegPath)
+	return err == nil
+}
+
+// MuxableSubtitles returns the subtitle files that can be muxed into an mkv,
+// with the language taken from the file name suffix (movie.zh.srt)
+func MuxableSubtitles(paths []string) []Subtitle {
+	var subtitles []Subtitle
+	for _, path := range paths {
+		ext := strings.ToLower(filepath.Ext(path))
+		if !muxableSubtitleExts[ext] {
+			continue
+		}
+		subtitles = append(subtitles, Subtitle{Path: path, Language: subtitleLanguage(path)})
+	}
+	return subtitles
+}
+
+// subtitleLanguage returns the ISO 639-2 code of the language suffix of a subtitle file name
+func subtitleLanguage(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if i := strings.LastIndexAny(base, "._"); i >= 0 {
+		return subtitleLanguages[strings.ToLower(base[i+1:])]
+	}
+	return ""
+}
+
+// IsChapterFile reports whether path is an FFMETADATA file ffmpeg can read chapters from
+func IsChapterFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	return scanner.Scan() && strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")) == ";FFMETADATA1"
+}
+
+// MuxMKV copies subtitles and chapters into the mkv at videoPath without re-encoding.
+// existingSubtitles is the number of subtitle tracks already in the file, it is needed
+// to tag the languages of the new tracks. The result is written to a temporary file
+// next to the video and replaces it only when ffmpeg succeeds.
+func (m *Muxer) MuxMKV(videoPath string, subtitles []Subtitle, chapterFile string, existingSubtitles int) error {
+	if !strings.EqualFold(filepath.Ext(videoPath), ".mkv") {
+		return fmt.Errorf("not an mkv file: %s", videoPath)
+	}
+	if len(subtitles) == 0 && chapterFile == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), muxTimeout)
+	defer cancel()
+
+	tmpPath := strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + ".mux.tmp.mkv"
+	cmd := exec.CommandContext(ctx, m.ffmpegPath, muxArgs(videoPath, tmpPath, subtitles, chapterFile, existingSubtitles)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		os.Remove(tmpPath)
+		if stderr.Len() > 0 {
+			return fmt.Errorf("ffmpeg failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return fmt.Errorf("ffmpeg failed: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, videoPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace video with muxed file: %w", err)
+	}
+	return nil
+}
+
+// muxArgs builds the ffmpeg arguments that stream copy the video, the subtitles and
+// the chapters into output. All streams and chapters already in the video are kept.
+func muxArgs(videoPath, output string, subtitles []Subtitle, chapterFile string, existingSubtitles int) []string {
+	args := []string{"-y", "-v", "error", "-i", videoPath}
+	for _, subtitle := range subtitles {
+		args = append(args, "-i", subtitle.Path)
+	}
+	if chapterFile != "" {
+		args = append(args, "-f", "ffmetadata", "-i", chapterFile)
+	}
+
+	args = append(args, "-map", "0")
+	for i := range subtitles {
+		args = append(args, "-map", fmt.Sprintf("%d:s", i+1))
+	}
+	if chapterFile != "" {
+		args = append(args, "-map_chapters", fmt.Sprintf("%d", len(subtitles)+1))
+	}
+	args = append(args, "-c", "copy")
+
+	for i, subtitle := range subtitles {
+		if subtitle.Language != "" {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", existingSubtitles+i), "language="+subtitle.Language)
+		}
+	}
+	return append(args, output)
+}
+
+// SubtitleCount returns the number of subtitle tracks in the container
+func (i *Info) SubtitleCount() int {
+	count := 0
+	for _, stream := range i.Streams {
+		if stream.CodecType == "subtitle" {
+			count++
+		}
+	}
+	return count
+}
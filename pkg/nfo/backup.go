@@ -0,0 +1,99 @@
+package nfo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// DefaultNFOBackupKeep 未配置 NameRule.BackupNFOKeep 时保留的带时间戳备份数量
+const DefaultNFOBackupKeep = 5
+
+// nfoBackupTimeFormat 带时间戳备份文件名中的时间格式，按字典序即按时间排序
+const nfoBackupTimeFormat = "20060102-150405"
+
+// backupExisting 覆盖NFO前按 NameRule.BackupNFO 备份已有的NFO，保留手动修改的内容
+//
+//	bak:       movie.nfo → movie.nfo.bak（只保留最近一份）
+//	timestamp: movie.nfo → movie.nfo.20240102-030405.bak（保留最近 BackupNFOKeep 份）
+func (g *Generator) backupExisting(filePath string) error {
+	mode := strings.ToLower(g.config.NameRule.BackupNFO)
+	if mode == "" || mode == "off" {
+		return nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat existing NFO: %w", err)
+	}
+
+	backupPath := filePath + ".bak"
+	if mode == "timestamp" {
+		backupPath = fmt.Sprintf("%s.%s.bak", filePath, g.now().Format(nfoBackupTimeFormat))
+	}
+	if err := copyFile(filePath, backupPath); err != nil {
+		return fmt.Errorf("failed to back up NFO: %w", err)
+	}
+	logger.Debug("Backed up NFO to %s", filepath.Base(backupPath))
+
+	if mode == "timestamp" {
+		g.pruneBackups(filePath)
+	}
+	return nil
+}
+
+// pruneBackups 删除超出保留数量的旧时间戳备份
+func (g *Generator) pruneBackups(filePath string) {
+	keep := g.config.NameRule.BackupNFOKeep
+	if keep <= 0 {
+		keep = DefaultNFOBackupKeep
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	if err != nil {
+		return
+	}
+	prefix := filepath.Base(filePath) + "."
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".bak")
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ".bak") && len(stamp) == len(nfoBackupTimeFormat) {
+			backups = append(backups, filepath.Join(filepath.Dir(filePath), name))
+		}
+	}
+	if len(backups) <= keep {
+		return
+	}
+	sort.Strings(backups)
+	for _, old := range backups[:len(backups)-keep] {
+		if err := os.Remove(old); err != nil {
+			logger.Warn("Failed to remove old NFO backup %s: %v", old, err)
+		}
+	}
+}
+
+// copyFile 复制文件内容到 dst（覆盖已有文件）
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
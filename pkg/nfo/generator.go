@@ -6,8 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"movie-data-capture/internal/config"
 	"movie-data-capture/internal/scraper"
@@ -33,6 +37,8 @@ type Movie struct {
 	Thumb           string   `xml:"thumb"`
 	Fanart          string   `xml:"fanart,omitempty"`
 	Actors          []Actor  `xml:"actor,omitempty"`
+	TotalActors     int      `xml:"totalactors,omitempty"`
+	OtherActors     string   `xml:"otheractors,omitempty"`
 	Maker           string   `xml:"maker"`
 	Label           string   `xml:"label"`
 	Tags            []string `xml:"tag,omitempty"`
@@ -48,6 +54,13 @@ type Movie struct {
 	Cover           string   `xml:"cover"`
 	Trailer         string   `xml:"trailer,omitempty"`
 	Website         string   `xml:"website"`
+	// Emby偏好的字段
+	UniqueID         *UniqueID `xml:"uniqueid,omitempty"`
+	LockData         string    `xml:"lockdata,omitempty"`
+	DateAdded        string    `xml:"dateadded,omitempty"`
+	CollectionNumber int       `xml:"collectionnumber,omitempty"`
+	// 数据源特有字段
+	Extra           []ExtraField `xml:"extra,omitempty"`
 	// 分片相关字段
 	IsMultiPart     bool     `xml:"ismultipart,omitempty"`
 	TotalParts      int      `xml:"totalparts,omitempty"`
@@ -59,9 +72,23 @@ type Movie struct {
 // Actor 表示NFO中的演员
 type Actor struct {
 	Name  string `xml:"name"`
+	Type  string `xml:"type,omitempty"`
 	Thumb string `xml:"thumb,omitempty"`
 }
 
+// ExtraField 表示NFO中的一个数据源特有字段
+type ExtraField struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// UniqueID 表示NFO中的唯一标识（Emby使用）
+type UniqueID struct {
+	Type    string `xml:"type,attr"`
+	Default string `xml:"default,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
 // Ratings 表示评分信息
 type Ratings struct {
 	Rating RatingInfo `xml:"rating"`
@@ -79,12 +106,17 @@ type RatingInfo struct {
 // Generator 处理NFO文件生成
 type Generator struct {
 	config *config.Config
+	now    func() time.Time // 写入<dateadded>的时间，测试中可固定
+
+	sortTitleOnce sync.Once
+	sortTitleTmpl *template.Template
 }
 
 // New 创建一个新的NFO生成器
 func New(cfg *config.Config) *Generator {
 	return &Generator{
 		config: cfg,
+		now:    time.Now,
 	}
 }
 
@@ -92,8 +124,8 @@ func New(cfg *config.Config) *Generator {
 func (g *Generator) GenerateNFO(data *scraper.MovieData, outputPath, part string, chineseSubtitle, leak, uncensored, hack, fourK, iso bool, actorList []string, posterPath, thumbPath, fanartPath string, isMultiPart bool, totalParts, currentPart int, fragmentFiles []string, totalFileSize int64) error {
 	// 确定NFO文件路径
 	var nfoPath string
-	if g.config.Common.MainMode == 3 {
-		// 模式3：NFO必须与视频文件名完全匹配
+	if g.config.Common.MainMode == 3 || g.config.Common.MainMode == 4 {
+		// 模式3：NFO必须与视频文件名完全匹配；模式4：outputPath 即要重新生成的NFO
 		nfoPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".nfo"
 	} else {
 		// 其他模式：使用基于编号的命名
@@ -163,8 +195,20 @@ func (g *Generator) GenerateNFO(data *scraper.MovieData, outputPath, part string
 		TotalFileSize: totalFileSize,
 	}
 
+	// 系列内排序：排序标题使用 "系列名 序号"
+	if data.Series != "" && data.SeriesIndex > 0 {
+		movie.SortTitle = fmt.Sprintf("%s %03d", data.Series, data.SeriesIndex)
+	}
+	// 配置了排序标题模板时优先使用模板结果
+	if sortTitle := g.sortTitle(data); sortTitle != "" {
+		movie.SortTitle = sortTitle
+	}
+
 	// 设置概要和剧情
 	outline := data.Outline
+	if outline == "" && strings.EqualFold(g.config.NameRule.OutlineFallback, "generate") {
+		outline = generateOutline(data, actorList)
+	}
 	if outline == "" {
 		// 保持为空
 	} else if data.Source == "pissplay" {
@@ -177,8 +221,8 @@ func (g *Generator) GenerateNFO(data *scraper.MovieData, outputPath, part string
 		movie.Plot = movie.Outline
 	}
 
-	// 为非Jellyfin设置fanart
-	if g.config.Common.Jellyfin == 0 {
+	// 未关闭fanart时写入（Jellyfin默认不写）
+	if g.config.GetFanartSource() == "cover" {
 		movie.Fanart = fanartPath
 	}
 
@@ -192,6 +236,7 @@ func (g *Generator) GenerateNFO(data *scraper.MovieData, outputPath, part string
 		}
 		movie.Actors = append(movie.Actors, actor)
 	}
+	g.capActors(movie)
 
 	// 添加标签和类型
 	if g.config.Common.Jellyfin == 0 {
@@ -277,10 +322,135 @@ func (g *Generator) GenerateNFO(data *scraper.MovieData, outputPath, part string
 		movie.Trailer = data.Trailer
 	}
 
+	// 写入配置中要求的数据源特有字段
+	g.addExtraFields(movie, data)
+
+	// 根据NFO方言调整字段
+	g.applyDialect(movie)
+
+	// Emby按系列序号排列合集
+	if g.config.GetNfoFlavor() == "emby" && data.Series != "" && data.SeriesIndex > 0 {
+		movie.CollectionNumber = data.SeriesIndex
+	}
+
 	// Write NFO file
 	return g.writeNFO(nfoPath, movie)
 }
 
+// sortTitle 按 NameRule.SortTitleTemplate 生成排序标题，多余空白合并
+// 未配置模板、模板无效或结果为空时返回空字符串
+func (g *Generator) sortTitle(data *scraper.MovieData) string {
+	g.sortTitleOnce.Do(func() {
+		tmpl, err := g.config.ParseSortTitleTemplate()
+		if err != nil {
+			logger.Warn("Invalid sort_title_template, using the default sort title: %v", err)
+			return
+		}
+		g.sortTitleTmpl = tmpl
+	})
+	if g.sortTitleTmpl == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	if err := g.sortTitleTmpl.Execute(&b, data); err != nil {
+		logger.Warn("Failed to generate sort title for %s: %v", data.Number, err)
+		return ""
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// addExtraFields 按 NameRule.NFOExtraFields 写入 MovieData.Extra 中的字段，"*" 表示全部
+func (g *Generator) addExtraFields(movie *Movie, data *scraper.MovieData) {
+	wanted := g.config.NameRule.NFOExtraFields
+	if len(wanted) == 0 || len(data.Extra) == 0 {
+		return
+	}
+
+	all := false
+	for _, name := range wanted {
+		if strings.TrimSpace(name) == "*" {
+			all = true
+			break
+		}
+	}
+
+	names := wanted
+	if all {
+		names = make([]string, 0, len(data.Extra))
+		for name := range data.Extra {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if value, ok := data.Extra[name]; ok && value != "" {
+			movie.Extra = append(movie.Extra, ExtraField{Name: name, Value: value})
+		}
+	}
+}
+
+// generateOutline 未抓取到简介时由标题、片商和演员生成一段简介，字段都为空时返回空字符串
+func generateOutline(data *scraper.MovieData, actorList []string) string {
+	var parts []string
+	if title := strings.TrimSpace(data.Title); title != "" {
+		parts = append(parts, title)
+	}
+	if studio := strings.TrimSpace(data.Studio); studio != "" {
+		parts = append(parts, "制作商："+studio)
+	}
+	if len(actorList) > 0 {
+		parts = append(parts, "演员："+strings.Join(actorList, "、"))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "。") + "。"
+}
+
+// capActors 按 NameRule.MaxNFOActors 限制写入的演员数量
+// 超出部分不再生成<actor>，但会记录演员总数并在<otheractors>中汇总其余演员名
+func (g *Generator) capActors(movie *Movie) {
+	maxActors := g.config.NameRule.MaxNFOActors
+	if maxActors <= 0 || len(movie.Actors) <= maxActors {
+		return
+	}
+
+	others := make([]string, 0, len(movie.Actors)-maxActors)
+	for _, actor := range movie.Actors[maxActors:] {
+		others = append(others, actor.Name)
+	}
+
+	movie.TotalActors = len(movie.Actors)
+	movie.OtherActors = strings.Join(others, ", ")
+	movie.Actors = movie.Actors[:maxActors]
+	logger.Debug("NFO actor list capped at %d of %d actors", maxActors, movie.TotalActors)
+}
+
+// applyDialect 根据 NameRule.NFODialect 调整Kodi/Emby各自偏好的标签
+// kodi: 保持原有输出; emby: 添加Emby字段并去掉Kodi专用的<ratings>; both: 写入两者的超集
+// 设置了 NameRule.NfoFlavor 时方言由风格决定（见 Config.GetNFODialect）
+func (g *Generator) applyDialect(movie *Movie) {
+	dialect := g.config.GetNFODialect()
+	if dialect == "kodi" {
+		return
+	}
+
+	// Emby使用uniqueid识别条目，演员需要显式的type
+	movie.UniqueID = &UniqueID{Type: "num", Default: "true", Value: movie.Number}
+	movie.LockData = "false"
+	movie.DateAdded = g.now().Format("2006-01-02 15:04:05")
+	for i := range movie.Actors {
+		movie.Actors[i].Type = "Actor"
+	}
+
+	if dialect == "emby" {
+		// Emby不读取Kodi的<ratings>块，只保留rating/criticrating
+		movie.Ratings = nil
+	}
+}
+
 // writeNFO 以适当的格式写入NFO文件
 func (g *Generator) writeNFO(filePath string, movie *Movie) error {
 	// Create directory if needed
@@ -289,6 +459,11 @@ func (g *Generator) writeNFO(filePath string, movie *Movie) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
+	// 覆盖前备份已有的NFO，备份失败时不覆盖，避免丢失手动修改
+	if err := g.backupExisting(filePath); err != nil {
+		return err
+	}
+
 	file, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to create NFO file: %w", err)
@@ -299,7 +474,7 @@ func (g *Generator) writeNFO(filePath string, movie *Movie) error {
 	file.WriteString(`<?xml version="1.0" encoding="UTF-8" ?>` + "\n")
 
 	// For Jellyfin, use simple text nodes; for others, use CDATA
-	if g.config.Common.Jellyfin > 0 {
+	if g.config.GetNfoFlavor() == "jellyfin" {
 		// Jellyfin mode: simple XML
 		encoder := xml.NewEncoder(file)
 		encoder.Indent("", "  ")
@@ -314,9 +489,41 @@ func (g *Generator) writeNFO(filePath string, movie *Movie) error {
 	}
 
 	logger.Info("Generated NFO: %s", filepath.Base(filePath))
+
+	if g.config.NameRule.ValidateNFO {
+		g.validateWritten(filePath)
+	}
 	return nil
 }
 
+// validateWritten 检查刚写入的NFO，可修复的问题直接修复，其余记录警告
+func (g *Generator) validateWritten(filePath string) {
+	issues, err := ValidateFile(filePath)
+	if err != nil || len(issues) == 0 {
+		return
+	}
+	for _, issue := range issues {
+		logger.Warn("NFO %s: %s", filepath.Base(filePath), issue)
+	}
+	if repaired, err := Repair(filePath); err != nil {
+		logger.Warn("Failed to repair NFO: %v", err)
+	} else if repaired {
+		logger.Info("Repaired NFO: %s", filepath.Base(filePath))
+	}
+}
+
+// escapeXMLAttr 转义属性值中的 XML 特殊字符（包括引号）
+func escapeXMLAttr(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// escapeCDATA 拆开内容中的 ]]>，避免提前结束 CDATA 段
+func escapeCDATA(value string) string {
+	return strings.ReplaceAll(value, "]]>", "]]]]><![CDATA[>")
+}
+
 // writeKodiNFO 为KODI写入带有CDATA部分的NFO
 func (g *Generator) writeKodiNFO(file *os.File, movie *Movie) error {
 	write := func(format string, args ...interface{}) {
@@ -347,11 +554,18 @@ func (g *Generator) writeKodiNFO(file *os.File, movie *Movie) error {
 	for _, actor := range movie.Actors {
 		write("  <actor>\n")
 		write("    <name>%s</name>\n", actor.Name)
+		if actor.Type != "" {
+			write("    <type>%s</type>\n", actor.Type)
+		}
 		if actor.Thumb != "" {
 			write("    <thumb>%s</thumb>\n", actor.Thumb)
 		}
 		write("  </actor>\n")
 	}
+	if movie.TotalActors > 0 {
+		write("  <totalactors>%d</totalactors>\n", movie.TotalActors)
+		write("  <otheractors>%s</otheractors>\n", movie.OtherActors)
+	}
 
 	write("  <maker>%s</maker>\n", movie.Maker)
 	write("  <label>%s</label>\n", movie.Label)
@@ -399,8 +613,33 @@ func (g *Generator) writeKodiNFO(file *os.File, movie *Movie) error {
 	
 	write("  <website>%s</website>\n", movie.Website)
 
+	// Emby specific fields
+	if movie.UniqueID != nil {
+		write("  <uniqueid type=\"%s\" default=\"%s\">%s</uniqueid>\n", movie.UniqueID.Type, movie.UniqueID.Default, movie.UniqueID.Value)
+	}
+	if movie.LockData != "" {
+		write("  <lockdata>%s</lockdata>\n", movie.LockData)
+	}
+	if movie.DateAdded != "" {
+		write("  <dateadded>%s</dateadded>\n", movie.DateAdded)
+	}
+	if movie.CollectionNumber > 0 {
+		write("  <collectionnumber>%d</collectionnumber>\n", movie.CollectionNumber)
+	}
+	for _, extra := range movie.Extra {
+		write("  <extra name=\"%s\"><![CDATA[%s]]></extra>\n", escapeXMLAttr(extra.Name), escapeCDATA(extra.Value))
+	}
+
 	// Write fragment information if applicable
-	if movie.IsMultiPart {
+	if movie.IsMultiPart && g.config.GetNfoFlavor() == "emby" {
+		// Emby把 -cd1/-part1 命名的分段堆叠为一部影片，共用一个NFO：
+		// 只描述整部影片（总分段数、总大小和各分段文件），不写当前分段
+		write("  <totalparts>%d</totalparts>\n", movie.TotalParts)
+		write("  <totalfilesize>%d</totalfilesize>\n", movie.TotalFileSize)
+		for _, fragmentFile := range movie.FragmentFiles {
+			write("  <fragmentfile>%s</fragmentfile>\n", fragmentFile)
+		}
+	} else if movie.IsMultiPart {
 		write("  <ismultipart>true</ismultipart>\n")
 		write("  <totalparts>%d</totalparts>\n", movie.TotalParts)
 		write("  <currentpart>%d</currentpart>\n", movie.CurrentPart)
@@ -0,0 +1,209 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/internal/scraper"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerateNFO_Flavors(t *testing.T) {
+	data := &scraper.MovieData{
+		Number:         "ABC-123",
+		Title:          "Title",
+		NamingRule:     "ABC-123 Title",
+		OriginalNaming: "ABC-123 Original",
+		Studio:         "Studio",
+		Label:          "Label",
+		Series:         "Series",
+		SeriesIndex:    3,
+		Year:           "2023",
+		Release:        "2023-01-05",
+		Runtime:        "120",
+		Director:       "Director",
+		Outline:        "Outline",
+		Tag:            []string{"Drama"},
+		Cover:          "https://example.com/abc123pl.jpg",
+		Website:        "https://example.com/abc123",
+		Source:         "javbus",
+		UserRating:     4.5,
+		UserVotes:      100,
+	}
+	fragments := []string{"ABC-123-cd1.mp4", "ABC-123-cd2.mp4"}
+
+	tests := []struct {
+		name    string
+		flavor  string
+		dialect string
+		golden  string
+	}{
+		{"kodi", "kodi", "", "kodi"},
+		{"jellyfin", "jellyfin", "", "jellyfin"},
+		{"emby", "emby", "", "emby"},
+		// An explicit flavor overrides nfo_dialect
+		{"kodi over emby dialect", "kodi", "emby", "kodi"},
+		{"jellyfin over emby dialect", "jellyfin", "emby", "jellyfin"},
+		{"emby over both dialect", "emby", "both", "emby"},
+		// Without a flavor nfo_dialect picks the tags
+		{"kodi dialect", "", "kodi", "kodi"},
+		{"emby dialect", "", "emby", "emby"},
+		{"both", "", "both", "both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Common.MainMode = 1
+			cfg.NameRule.NfoFlavor = tt.flavor
+			cfg.NameRule.NFODialect = tt.dialect
+
+			g := New(cfg)
+			g.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+			dir := t.TempDir()
+			err := g.GenerateNFO(data, dir, "", false, false, false, false, false, false, []string{"Actor"},
+				"ABC-123-poster.jpg", "ABC-123-thumb.jpg", "ABC-123-fanart.jpg", true, 2, 1, fragments, 2048)
+			if err != nil {
+				t.Fatalf("GenerateNFO failed: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "ABC-123.nfo"))
+			if err != nil {
+				t.Fatalf("Failed to read NFO: %v", err)
+			}
+
+			golden := filepath.Join("testdata", tt.golden+".nfo.golden")
+			if *update && tt.name == tt.golden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("NFO for %s differs from %s:\n%s", tt.name, golden, got)
+			}
+		})
+	}
+}
+
+func TestSortTitle(t *testing.T) {
+	data := &scraper.MovieData{Number: "ABC-123", Title: "Title", Series: "Series", SeriesIndex: 3}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"", ""},
+		{"{{.Series}} {{pad .SeriesIndex 3}} {{.Number}}", "Series 003 ABC-123"},
+		{"{{.Label}} {{.Number}}", "ABC-123"},
+		{"{{.Label}}", ""},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{}
+		cfg.NameRule.SortTitleTemplate = tt.template
+		if got := New(cfg).sortTitle(data); got != tt.expected {
+			t.Errorf("sortTitle(%q) = %q, want %q", tt.template, got, tt.expected)
+		}
+	}
+}
+
+func TestWriteNFO_Backup(t *testing.T) {
+	for _, mode := range []string{"bak", "timestamp"} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.NameRule.BackupNFO = mode
+			cfg.NameRule.BackupNFOKeep = 2
+
+			g := New(cfg)
+			clock := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			g.now = func() time.Time { return clock }
+
+			path := filepath.Join(t.TempDir(), "ABC-123.nfo")
+			if err := os.WriteFile(path, []byte("hand edited"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 3; i++ {
+				if err := g.writeNFO(path, &Movie{Title: "Title"}); err != nil {
+					t.Fatalf("writeNFO failed: %v", err)
+				}
+				clock = clock.Add(time.Second)
+			}
+
+			backups, _ := filepath.Glob(path + ".*")
+			if mode == "bak" {
+				if len(backups) != 1 || backups[0] != path+".bak" {
+					t.Fatalf("expected a single .bak backup, got %v", backups)
+				}
+				return
+			}
+			if len(backups) != 2 {
+				t.Fatalf("expected 2 timestamped backups to be kept, got %v", backups)
+			}
+			oldest, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "ABC-123.nfo.20240102-030405.bak"))
+			if len(oldest) != 0 {
+				t.Errorf("oldest backup should have been pruned")
+			}
+		})
+	}
+}
+
+func TestFindNFOFiles_SkipsActorsAndExtrafanart(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"ABC-123/ABC-123.nfo",
+		"ABC-123/.actors/Actor/person.nfo",
+		"ABC-123/extrafanart/fanart.nfo",
+		"DEF-456/DEF-456.NFO",
+	}
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("<movie></movie>"), 0644); err != nil {
+			t.Fatalf("Failed to write NFO: %v", err)
+		}
+	}
+
+	got, err := FindNFOFiles(root, "extrafanart")
+	if err != nil {
+		t.Fatalf("FindNFOFiles failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "ABC-123", "ABC-123.nfo"),
+		filepath.Join(root, "DEF-456", "DEF-456.NFO"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindNFOFiles() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FindNFOFiles()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtraFieldEscaping(t *testing.T) {
+	var parsed struct {
+		Extra ExtraField `xml:"extra"`
+	}
+	name, value := `a"b<c&d`, "x]]>y"
+	doc := "<movie><extra name=\"" + escapeXMLAttr(name) + "\"><![CDATA[" + escapeCDATA(value) + "]]></extra></movie>"
+	if err := xml.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Fatalf("Unmarshal(%s) error: %v", doc, err)
+	}
+	if parsed.Extra.Name != name || parsed.Extra.Value != value {
+		t.Errorf("got %q=%q, want %q=%q", parsed.Extra.Name, parsed.Extra.Value, name, value)
+	}
+}
@@ -0,0 +1,109 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// Parse 读取并解析一个NFO文件
+func Parse(filePath string) (*Movie, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read NFO: %w", err)
+	}
+
+	movie := &Movie{}
+	if err := xml.Unmarshal(data, movie); err != nil {
+		return nil, fmt.Errorf("malformed NFO %s: %w", filePath, err)
+	}
+	return movie, nil
+}
+
+// FindNFOFiles 递归查找目录下的所有影片NFO文件
+// 跳过演员目录 .actors（其中的 person.nfo 不是影片NFO）和剧照目录 extrafanartFolder
+func FindNFOFiles(root, extrafanartFolder string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // 无法访问的目录直接跳过
+		}
+		if d.IsDir() {
+			if path != root && (d.Name() == ".actors" || (extrafanartFolder != "" && d.Name() == extrafanartFolder)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".nfo") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// ParseEach 用最多 workers 个并发解析 paths 中的NFO，每解析成功一个调用一次 fn
+// fn 在调用方的goroutine中串行执行，无需加锁；格式错误的NFO记录警告后跳过
+// workers 为0时使用CPU核数；返回解析失败的文件数
+func ParseEach(paths []string, workers int, fn func(path string, movie *Movie)) int {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type parsed struct {
+		path  string
+		movie *Movie
+		err   error
+	}
+
+	jobs := make(chan string)
+	results := make(chan parsed, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				movie, err := Parse(path)
+				results <- parsed{path: path, movie: movie, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, path := range paths {
+			jobs <- path
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	failed := 0
+	for result := range results {
+		if result.err != nil {
+			logger.Warn("Skipping NFO: %v", result.err)
+			failed++
+			continue
+		}
+		fn(result.path, result.movie)
+	}
+	return failed
+}
+
+// ParseAll 并发解析所有NFO，返回按路径索引的结果和解析失败的文件数
+func ParseAll(paths []string, workers int) (map[string]*Movie, int) {
+	movies := make(map[string]*Movie, len(paths))
+	failed := ParseEach(paths, workers, func(path string, movie *Movie) {
+		movies[path] = movie
+	})
+	return movies, failed
+}
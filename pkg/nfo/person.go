@@ -0,0 +1,87 @@
+package nfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"movie-data-capture/internal/scraper"
+	"movie-data-capture/pkg/logger"
+)
+
+// PersonNFOName 演员目录中NFO文件的名称
+const PersonNFOName = "person.nfo"
+
+// Person 表示演员NFO（.actors/<演员名>/person.nfo）的XML结构
+type Person struct {
+	XMLName xml.Name `xml:"person"`
+	Name    string   `xml:"name"`
+	Type    string   `xml:"type"`
+	Thumb   string   `xml:"thumb,omitempty"`
+}
+
+// GeneratePersonNFOs 为影片中的每位演员在 .actors/<演员名>/ 下生成 person.nfo
+// 已存在的 person.nfo 不覆盖，以保留用户编辑的资料；返回新生成的数量
+func (g *Generator) GeneratePersonNFOs(data *scraper.MovieData, outputDir string) int {
+	actorsDir := filepath.Join(outputDir, ".actors")
+	created := 0
+
+	for _, name := range data.ActorList {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		personDir := filepath.Join(actorsDir, name)
+		nfoPath := filepath.Join(personDir, PersonNFOName)
+		if _, err := os.Stat(nfoPath); err == nil {
+			continue
+		}
+
+		if err := g.writePersonNFO(personDir, name, data.ActorPhoto[name]); err != nil {
+			logger.Warn("Failed to write person NFO for %s: %v", name, err)
+			continue
+		}
+		created++
+	}
+
+	if created > 0 {
+		logger.Debug("Generated %d person NFO(s) in %s", created, actorsDir)
+	}
+	return created
+}
+
+// writePersonNFO 写入单个演员的 person.nfo，已下载的演员照片复制为目录中的 folder 图片
+func (g *Generator) writePersonNFO(personDir, name, photoURL string) error {
+	if err := os.MkdirAll(personDir, 0755); err != nil {
+		return fmt.Errorf("failed to create actor directory: %w", err)
+	}
+
+	person := &Person{Name: name, Type: "Actor", Thumb: photoURL}
+
+	// DownloadActorPhotos 保存的照片位于 .actors/<演员名><扩展名>
+	if photoURL != "" {
+		ext := filepath.Ext(photoURL)
+		if ext == "" {
+			ext = ".jpg"
+		}
+		localPhoto := filepath.Join(filepath.Dir(personDir), name+ext)
+		if photo, err := os.ReadFile(localPhoto); err == nil {
+			folderImage := "folder" + ext
+			if err := os.WriteFile(filepath.Join(personDir, folderImage), photo, 0644); err == nil {
+				person.Thumb = folderImage
+			}
+		}
+	}
+
+	content, err := xml.MarshalIndent(person, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode person NFO: %w", err)
+	}
+	content = append([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>`+"\n"), content...)
+	content = append(content, '\n')
+
+	return os.WriteFile(filepath.Join(personDir, PersonNFOName), content, 0644)
+}
@@ -0,0 +1,208 @@
+package nfo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// requiredElements NFO中必须存在且非空的元素（<movie> 的直接子元素）
+var requiredElements = []string{"title", "num"}
+
+// Issue NFO不符合结构要求的一处问题
+type Issue struct {
+	Element    string // 相关的元素名，结构问题为空
+	Detail     string
+	Repairable bool // 能否由 Repair 自动修复
+}
+
+func (i Issue) String() string {
+	if i.Element == "" {
+		return i.Detail
+	}
+	return fmt.Sprintf("<%s>: %s", i.Element, i.Detail)
+}
+
+// Validate 检查NFO内容：XML格式正确、根元素为 <movie>、不含非法控制字符、必需元素存在且非空
+func Validate(data []byte) []Issue {
+	var issues []Issue
+
+	if hasControlChars(data) {
+		issues = append(issues, Issue{Detail: "contains control characters not allowed in XML", Repairable: true})
+		data = stripControlChars(data)
+	}
+
+	values, root, err := topLevelElements(data)
+	if err != nil {
+		return append(issues, Issue{Detail: fmt.Sprintf("malformed XML: %v", err)})
+	}
+	if root != "movie" {
+		return append(issues, Issue{Detail: fmt.Sprintf("root element is <%s>, expected <movie>", root)})
+	}
+
+	for _, name := range requiredElements {
+		if strings.TrimSpace(values[name]) != "" {
+			continue
+		}
+		// 标题可以用原标题或番号补上
+		repairable := name == "title" && titleFallback(values) != ""
+		issues = append(issues, Issue{Element: name, Detail: "missing or empty", Repairable: repairable})
+	}
+	return issues
+}
+
+// ValidateFile 读取并检查一个NFO文件
+func ValidateFile(path string) ([]Issue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read NFO: %w", err)
+	}
+	return Validate(data), nil
+}
+
+// Repair 修复NFO中可自动修复的问题（去除控制字符、用原标题或番号补全 <title>）
+// 返回是否修改了文件；修复后仍有无法修复的问题时返回错误且不修改文件
+func Repair(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read NFO: %w", err)
+	}
+
+	issues := Validate(data)
+	if len(issues) == 0 {
+		return false, nil
+	}
+	for _, issue := range issues {
+		if !issue.Repairable {
+			return false, fmt.Errorf("cannot repair %s: %s", path, issue)
+		}
+	}
+
+	fixed := stripControlChars(data)
+	values, _, err := topLevelElements(fixed)
+	if err != nil {
+		return false, fmt.Errorf("cannot repair %s: %w", path, err)
+	}
+	if strings.TrimSpace(values["title"]) == "" {
+		fixed = setTitle(fixed, titleFallback(values))
+	}
+
+	if issues := Validate(fixed); len(issues) > 0 {
+		return false, fmt.Errorf("cannot repair %s: %s", path, issues[0])
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, fixed, 0644); err != nil {
+		return false, fmt.Errorf("failed to write repaired NFO: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return false, fmt.Errorf("failed to replace NFO: %w", err)
+	}
+	return true, nil
+}
+
+// topLevelElements 解析XML，返回根元素名和根元素下各直接子元素的文本（同名取第一个）
+func topLevelElements(data []byte) (map[string]string, string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	values := make(map[string]string)
+
+	var root, current string
+	var text strings.Builder
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				root = t.Name.Local
+			} else if depth == 2 {
+				current = t.Name.Local
+				text.Reset()
+			}
+		case xml.CharData:
+			if depth == 2 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				if _, exists := values[current]; !exists {
+					values[current] = text.String()
+				}
+			}
+			depth--
+		}
+	}
+
+	if root == "" {
+		return nil, "", errors.New("no root element")
+	}
+	return values, root, nil
+}
+
+// titleFallback 缺少标题时使用的替代值
+func titleFallback(values map[string]string) string {
+	for _, name := range []string{"originaltitle", "sorttitle", "num"} {
+		if value := strings.TrimSpace(values[name]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// setTitle 替换空的 <title> 元素，不存在时插入到 <movie> 之后
+func setTitle(data []byte, title string) []byte {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(title))
+	element := "<title>" + escaped.String() + "</title>"
+
+	content := string(data)
+	for _, empty := range []string{"<title></title>", "<title/>", "<title />", "<title><![CDATA[]]></title>"} {
+		if strings.Contains(content, empty) {
+			return []byte(strings.Replace(content, empty, element, 1))
+		}
+	}
+
+	index := strings.Index(content, "<movie>")
+	if index < 0 {
+		return data
+	}
+	index += len("<movie>")
+	return []byte(content[:index] + "\n  " + element + content[index:])
+}
+
+// isControlChar 判断字节是否为XML 1.0不允许的控制字符（制表符和换行除外）
+func isControlChar(b byte) bool {
+	return b < 0x20 && b != '\t' && b != '\n' && b != '\r'
+}
+
+func hasControlChars(data []byte) bool {
+	for _, b := range data {
+		if isControlChar(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripControlChars(data []byte) []byte {
+	cleaned := make([]byte, 0, len(data))
+	for _, b := range data {
+		if !isControlChar(b) {
+			cleaned = append(cleaned, b)
+		}
+	}
+	return cleaned
+}
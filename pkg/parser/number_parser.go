@@ -1,108 +1,157 @@
 package parser
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"movie-data-capture/internal/config"
 	"movie-data-capture/pkg/logger"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // NumberParser 处理从文件名中提取电影编号
 type NumberParser struct {
 	config *config.Config
 	cleanupRegex *regexp.Regexp
-	takeNumRules map[string]func(string) string
 }
 
 // NewNumberParser 创建一个新的编号解析器实例
 func NewNumberParser(cfg *config.Config) *NumberParser {
-	p := &NumberParser{
+	return &NumberParser{
 		config: cfg,
 		cleanupRegex: regexp.MustCompile(`(?i)^\w+\.(cc|com|net|me|club|jp|tv|xyz|biz|wiki|info|tw|us|de)@|^22-sht\.me|^(fhd|hd|sd|1080p|720p|4K)(-|_)|(-|_)(fhd|hd|sd|1080p|720p|4K|x264|x265|uncensored|hack|leak)`),
 	}
-	
-	// 初始化提取编号规则（类似于Python的G_TAKE_NUM_RULES）
-	p.initTakeNumRules()
-	
-	return p
 }
 
-// initTakeNumRules 初始化特殊编号提取规则
-func (p *NumberParser) initTakeNumRules() {
-	p.takeNumRules = map[string]func(string) string{
-		"tokyo.*hot": func(filename string) string {
-			re := regexp.MustCompile(`(?i)(cz|gedo|k|n|red-|se)\d{2,4}`)
-			if match := re.FindString(filename); match != "" {
-				return match
-			}
-			return ""
-		},
-		"carib": func(filename string) string {
-			re := regexp.MustCompile(`(?i)\d{6}(-|_)\d{3}`)
-			if match := re.FindString(filename); match != "" {
-				return strings.ReplaceAll(match, "_", "-")
-			}
-			return ""
-		},
-		"1pon|mura|paco": func(filename string) string {
-			re := regexp.MustCompile(`(?i)\d{6}(-|_)\d{3}`)
-			if match := re.FindString(filename); match != "" {
-				return strings.ReplaceAll(match, "-", "_")
-			}
-			return ""
-		},
-		"10mu": func(filename string) string {
-			re := regexp.MustCompile(`(?i)\d{6}(-|_)\d{2}`)
-			if match := re.FindString(filename); match != "" {
-				return strings.ReplaceAll(match, "-", "_")
-			}
-			return ""
-		},
-		"x-art": func(filename string) string {
-			re := regexp.MustCompile(`(?i)x-art\.\d{2}\.\d{2}\.\d{2}`)
-			return re.FindString(filename)
-		},
-		"xxx-av": func(filename string) string {
-			re := regexp.MustCompile(`(?i)xxx-av[^\d]*(\d{3,5})[^\d]*`)
-			if matches := re.FindStringSubmatch(filename); len(matches) > 1 {
-				return "xxx-av-" + matches[1]
-			}
-			return ""
-		},
-		"heydouga": func(filename string) string {
-			re := regexp.MustCompile(`(?i)(\d{4})[\-_](\d{3,4})[^\d]*`)
-			if matches := re.FindStringSubmatch(filename); len(matches) > 2 {
-				return "heydouga-" + matches[1] + "-" + matches[2]
-			}
-			return ""
-		},
-		"heyzo": func(filename string) string {
-			re := regexp.MustCompile(`(?i)heyzo[^\d]*(\d{4})`)
-			if matches := re.FindStringSubmatch(filename); len(matches) > 1 {
-				return "HEYZO-" + matches[1]
-			}
+// numberRule 特殊番号规则：文件名匹配 trigger 时由 extract 提取番号
+type numberRule struct {
+	name    string
+	trigger *regexp.Regexp
+	extract func(filename string) string
+}
+
+// numberRules 特殊番号规则（类似于Python的G_TAKE_NUM_RULES），按顺序尝试，先匹配的优先，
+// 提取结果再经过 normalizeNumber 统一格式：
+//
+//	fc2       FC2-PPV-1234567、FC2PPV_1234567、fc2 ppv 1234567  -> FC2-1234567
+//	heyzo     heyzo-1234、HEYZO_hd_1234_full                    -> HEYZO-1234
+//	tokyo-hot Tokyo-Hot n1234                                   -> N1234
+//	carib     carib-123456-789、caribbeancompr_123456_789       -> 123456-789
+//	1pondo    1pondo_012345_678、muramura、pacopacomama         -> 012345-678
+//	10musume  10musume_123456_01                                -> 123456-01
+//	x-art     x-art.12.03.04                                    -> X-ART.12.03.04
+//	xxx-av    XXX-AV 22061                                      -> XXX-AV-22061
+//	heydouga  heydouga-4102-023                                 -> HEYDOUGA-4102-023
+//	mdbk/mdtm mdbk_0123                                         -> MDBK-0123
+var numberRules = []numberRule{
+	{
+		name:    "fc2",
+		trigger: regexp.MustCompile(`(?i)fc2`),
+		extract: submatchRule(`(?i)fc2[-_ ]*(?:ppv[-_ ]*)?(\d{5,8})`, "FC2-%s"),
+	},
+	{
+		name:    "heyzo",
+		trigger: regexp.MustCompile(`(?i)heyzo`),
+		extract: submatchRule(`(?i)heyzo[^\d]*(\d{4})`, "HEYZO-%s"),
+	},
+	{
+		name:    "tokyo-hot",
+		trigger: regexp.MustCompile(`(?i)tokyo.*hot`),
+		extract: matchRule(`(?i)(cz|gedo|k|n|red-|se)\d{2,4}`, nil),
+	},
+	{
+		name:    "carib",
+		trigger: regexp.MustCompile(`(?i)carib`),
+		extract: matchRule(`(?i)\d{6}(-|_)\d{3}`, strings.NewReplacer("_", "-")),
+	},
+	{
+		name:    "1pondo",
+		trigger: regexp.MustCompile(`(?i)1pon|mura|paco`),
+		extract: matchRule(`(?i)\d{6}(-|_)\d{3}`, strings.NewReplacer("-", "_")),
+	},
+	{
+		name:    "10musume",
+		trigger: regexp.MustCompile(`(?i)10mu`),
+		extract: matchRule(`(?i)\d{6}(-|_)\d{2}`, strings.NewReplacer("-", "_")),
+	},
+	{
+		name:    "x-art",
+		trigger: regexp.MustCompile(`(?i)x-art`),
+		extract: matchRule(`(?i)x-art\.\d{2}\.\d{2}\.\d{2}`, nil),
+	},
+	{
+		name:    "xxx-av",
+		trigger: regexp.MustCompile(`(?i)xxx-av`),
+		extract: submatchRule(`(?i)xxx-av[^\d]*(\d{3,5})`, "xxx-av-%s"),
+	},
+	{
+		name:    "heydouga",
+		trigger: regexp.MustCompile(`(?i)heydouga`),
+		extract: submatchRule(`(?i)(\d{4})[\-_](\d{3,4})`, "heydouga-%s-%s"),
+	},
+	{
+		name:    "mdbk",
+		trigger: regexp.MustCompile(`(?i)mdbk|mdtm`),
+		extract: submatchRule(`(?i)(mdbk|mdtm)[-_](\d{4})`, "%s-%s", strings.ToUpper),
+	},
+}
+
+// matchRule 返回提取整个匹配的规则函数，replacer 不为空时用于统一分隔符
+func matchRule(pattern string, replacer *strings.Replacer) func(string) string {
+	re := regexp.MustCompile(pattern)
+	return func(filename string) string {
+		match := re.FindString(filename)
+		if replacer != nil {
+			match = replacer.Replace(match)
+		}
+		return match
+	}
+}
+
+// submatchRule 返回按 format 拼接捕获组的规则函数，transforms 依次作用于每个捕获组
+func submatchRule(pattern, format string, transforms ...func(string) string) func(string) string {
+	re := regexp.MustCompile(pattern)
+	return func(filename string) string {
+		matches := re.FindStringSubmatch(filename)
+		if len(matches) < 2 {
 			return ""
-		},
-		"mdbk": func(filename string) string {
-			re := regexp.MustCompile(`(?i)mdbk(-|_)(\d{4})`)
-			return re.FindString(filename)
-		},
-		"mdtm": func(filename string) string {
-			re := regexp.MustCompile(`(?i)mdtm(-|_)(\d{4})`)
-			return re.FindString(filename)
-		},
-		"caribpr": func(filename string) string {
-			re := regexp.MustCompile(`(?i)\d{6}(-|_)\d{3}`)
-			if match := re.FindString(filename); match != "" {
-				return strings.ReplaceAll(match, "_", "-")
+		}
+		args := make([]interface{}, 0, len(matches)-1)
+		for _, group := range matches[1:] {
+			for _, transform := range transforms {
+				group = transform(group)
 			}
-			return ""
-		},
+			args = append(args, group)
+		}
+		return fmt.Sprintf(format, args...)
 	}
 }
 
+// bracketTagRegex 匹配文件名中的括号标签，如 [1080p]、[ThZu.Cc]、【字幕组】
+var bracketTagRegex = regexp.MustCompile(`\[[^\]]*\]|【[^】]*】`)
+
+// bracketNumberRegex 判断括号内容是否包含番号（[ABC-123]、[FC2-PPV-1234567]）
+var bracketNumberRegex = regexp.MustCompile(`(?i)[a-z]{2,}[-_ ]?\d{2,}|\d{6}[-_]\d{2,3}`)
+
+// stripBracketTags 去掉分辨率、网站、发布组等括号标签，包含番号的括号只去掉括号本身
+func stripBracketTags(name string) string {
+	stripped := bracketTagRegex.ReplaceAllStringFunc(name, func(tag string) string {
+		content := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(tag, "["), "【"), "]")
+		content = strings.TrimSuffix(content, "】")
+		if bracketNumberRegex.MatchString(content) {
+			return " " + content + " "
+		}
+		return " "
+	})
+	return strings.TrimSpace(stripped)
+}
+
 // GetNumber 使用增强逻辑从文件名中提取电影编号
 func (p *NumberParser) GetNumber(filename string) string {
+	// 全角字母、数字和符号统一为半角（ＡＢＣ－１２３ -> ABC-123）
+	filename = NormalizeWidth(filename)
+
 	// 移除路径并获取基础文件名
 	basename := strings.TrimSuffix(filename, getFileExtension(filename))
 	
@@ -147,8 +196,8 @@ func (p *NumberParser) GetNumber(filename string) string {
 		}
 	}
 	
-	// 尝试特殊字典规则
-	if number := p.getNumberByDict(basename); number != "" {
+	// 尝试特殊番号规则
+	if number := p.getNumberByRules(basename); number != "" {
 		return p.normalizeNumber(number)
 	}
 	
@@ -168,6 +217,11 @@ func (p *NumberParser) GetNumber(filename string) string {
 		}
 	}
 	
+	// 去掉分辨率、网站、发布组等括号标签后再按常规格式提取
+	if stripped := stripBracketTags(basename); stripped != "" {
+		basename = stripped
+	}
+	
 	// 处理带有破折号或下划线的正常提取
 	if strings.Contains(basename, "-") || strings.Contains(basename, "_") {
 		number := p.extractNormalNumber(basename)
@@ -184,14 +238,15 @@ func (p *NumberParser) GetNumber(filename string) string {
 	return ""
 }
 
-// getNumberByDict 尝试将文件名与特殊规则字典匹配
-func (p *NumberParser) getNumberByDict(filename string) string {
-	for pattern, extractor := range p.takeNumRules {
-		re := regexp.MustCompile(`(?i)` + pattern)
-		if re.MatchString(filename) {
-			if result := extractor(filename); result != "" {
-				return result
-			}
+// getNumberByRules 按顺序尝试特殊番号规则
+func (p *NumberParser) getNumberByRules(filename string) string {
+	for _, rule := range numberRules {
+		if !rule.trigger.MatchString(filename) {
+			continue
+		}
+		if result := rule.extract(filename); result != "" {
+			logger.Debug("特殊番号规则匹配: %s -> %s", rule.name, result)
+			return result
 		}
 	}
 	return ""
@@ -343,6 +398,9 @@ func (p *NumberParser) IsUncensored(number string) bool {
 // normalizeNumber 规范化提取的番号（统一格式处理）
 // {{ AURA-X: Add - 统一的后处理规范化函数，类似Python版本. Confirmed via 寸止 }}
 func (p *NumberParser) normalizeNumber(number string) string {
+	// 0. 全角转半角
+	number = NormalizeWidth(number)
+
 	// 1. 下划线统一转为破折号
 	number = strings.ReplaceAll(number, "_", "-")
 	
@@ -374,10 +432,15 @@ func (p *NumberParser) normalizeNumber(number string) string {
 	return number
 }
 
+// NormalizeWidth 使用NFKC规范化文本，将全角字母、数字和符号转换为半角形式
+func NormalizeWidth(text string) string {
+	return norm.NFKC.String(text)
+}
+
+// extensionRegex 匹配文件扩展名（点后为1-5位字母数字），避免把 [ThZu.Cc]ABC-123 中的点当作扩展名
+var extensionRegex = regexp.MustCompile(`\.[A-Za-z0-9]{1,5}$`)
+
 // getFileExtension 返回包含点的文件扩展名
 func getFileExtension(filename string) string {
-	if idx := strings.LastIndex(filename, "."); idx != -1 {
-		return filename[idx:]
-	}
-	return ""
+	return extensionRegex.FindString(filename)
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// All randomized choices (jitter, user agent rotation, mirror order) go through this
+// source so that a run can be reproduced with the same seed.
+var (
+	mu   sync.Mutex
+	rng  = rand.New(rand.NewSource(time.Now().UnixNano()))
+	seed int64
+)
+
+// Seed reseeds the shared source. A seed of 0 picks a time based seed.
+// It returns the seed actually used so it can be logged for reproduction.
+func Seed(s int64) int64 {
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	rng = rand.New(rand.NewSource(s))
+	seed = s
+	return s
+}
+
+// CurrentSeed returns the seed set by the last call to Seed, or 0 if never seeded
+func CurrentSeed() int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return seed
+}
+
+// Intn returns a non-negative random int in [0,n)
+func Intn(n int) int {
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.Intn(n)
+}
+
+// Int63n returns a non-negative random int64 in [0,n)
+func Int63n(n int64) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.Int63n(n)
+}
+
+// Float64 returns a random float64 in [0.0,1.0)
+func Float64() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.Float64()
+}
+
+// Float32 returns a random float32 in [0.0,1.0)
+func Float32() float32 {
+	mu.Lock()
+	defer mu.Unlock()
+	return rng.Float32()
+}
+
+// Shuffle pseudo-randomizes the order of elements
+func Shuffle(n int, swap func(i, j int)) {
+	mu.Lock()
+	defer mu.Unlock()
+	rng.Shuffle(n, swap)
+}
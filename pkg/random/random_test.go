@@ -0,0 +1,34 @@
+package random
+
+import "testing"
+
+func TestSeed_Reproducible(t *testing.T) {
+	draw := func() []int {
+		values := make([]int, 0, 10)
+		for i := 0; i < 10; i++ {
+			values = append(values, Intn(1000))
+		}
+		return values
+	}
+
+	Seed(42)
+	first := draw()
+	Seed(42)
+	second := draw()
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Same seed produced different values: %v vs %v", first, second)
+		}
+	}
+
+	if CurrentSeed() != 42 {
+		t.Errorf("Expected current seed 42, got %d", CurrentSeed())
+	}
+}
+
+func TestSeed_ZeroPicksSeed(t *testing.T) {
+	if used := Seed(0); used == 0 {
+		t.Error("Seed(0) should pick a non-zero seed")
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"movie-data-capture/pkg/logger"
 )
 
 // RecoveryManager 管理错误恢复和状态持久化
@@ -19,6 +21,11 @@ type RecoveryManager struct {
 	strategies    map[string]RecoveryStrategy
 	processStates map[string]*ProcessState
 	config        *RecoveryConfig
+
+	saveMu       sync.Mutex    // 串行化状态文件写入，避免旧快照覆盖新快照
+	saveRequests chan struct{} // 待保存通知，写入进行中到达的多次更新合并为一次保存
+	saveDone     chan struct{}
+	closed       bool
 }
 
 // RecoveryConfig 保存恢复配置
@@ -135,6 +142,8 @@ func NewRecoveryManager(config *RecoveryConfig) (*RecoveryManager, error) {
 		strategies:    make(map[string]RecoveryStrategy),
 		processStates: make(map[string]*ProcessState),
 		config:        config,
+		saveRequests:  make(chan struct{}, 1),
+		saveDone:      make(chan struct{}),
 	}
 
 	// 如果备份目录不存在则创建
@@ -153,9 +162,23 @@ func NewRecoveryManager(config *RecoveryConfig) (*RecoveryManager, error) {
 	rm.RegisterStrategy(&RetryRecoveryStrategy{})
 	rm.RegisterStrategy(&RestartRecoveryStrategy{})
 
+	go rm.saveLoop()
 	return rm, nil
 }
 
+// Close 停止后台保存并将最终状态写入磁盘，之后不应再更新状态
+func (rm *RecoveryManager) Close() error {
+	rm.mu.Lock()
+	if !rm.closed {
+		rm.closed = true
+		close(rm.saveRequests)
+	}
+	rm.mu.Unlock()
+
+	<-rm.saveDone
+	return rm.SaveState()
+}
+
 // RegisterStrategy 注册恢复策略
 func (rm *RecoveryManager) RegisterStrategy(strategy RecoveryStrategy) {
 	rm.mu.Lock()
@@ -234,6 +257,27 @@ func (rm *RecoveryManager) ListProcesses() map[string]*ProcessState {
 	return result
 }
 
+// SnapshotProcess 返回进程状态的深拷贝，可在其他 goroutine 更新状态时安全读取
+func (rm *RecoveryManager) SnapshotProcess(id string) (*ProcessState, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	state, exists := rm.processStates[id]
+	if !exists {
+		return nil, fmt.Errorf("process %s not found", id)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+	snapshot := &ProcessState{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	return snapshot, nil
+}
+
 // CreateCheckpoint 创建恢复检查点
 func (rm *RecoveryManager) CreateCheckpoint(processID, step string, data map[string]interface{}) error {
 	return rm.UpdateProcess(processID, func(state *ProcessState) {
@@ -309,11 +353,14 @@ func (rm *RecoveryManager) findRecoveryStrategy(err error, state *ProcessState)
 }
 
 // SaveState 将当前状态保存到磁盘
+// 先写入临时文件再重命名，中途崩溃不会留下不完整的状态文件
 func (rm *RecoveryManager) SaveState() error {
-	rm.mu.RLock()
-	defer rm.mu.RUnlock()
+	rm.saveMu.Lock()
+	defer rm.saveMu.Unlock()
 
+	rm.mu.RLock()
 	data, err := json.MarshalIndent(rm.processStates, "", "  ")
+	rm.mu.RUnlock()
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
@@ -324,13 +371,35 @@ func (rm *RecoveryManager) SaveState() error {
 	}
 
 	// 写入状态文件
-	if err := ioutil.WriteFile(rm.stateFile, data, 0644); err != nil {
+	if err := writeFileAtomic(rm.stateFile, data); err != nil {
 		return fmt.Errorf("failed to write state file: %w", err)
 	}
 
 	return nil
 }
 
+// writeFileAtomic 通过同目录下的临时文件和重命名替换 path 的内容
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
 // LoadState 从磁盘加载状态
 func (rm *RecoveryManager) LoadState() error {
 	if _, err := os.Stat(rm.stateFile); os.IsNotExist(err) {
@@ -406,14 +475,26 @@ func (rm *RecoveryManager) cleanupOldBackups() error {
 	return nil
 }
 
-// saveStateAsync 异步保存状态
+// saveStateAsync 通知后台 goroutine 保存状态，调用方需持有 rm.mu
 func (rm *RecoveryManager) saveStateAsync() {
-	go func() {
+	if rm.closed {
+		return
+	}
+	select {
+	case rm.saveRequests <- struct{}{}:
+	default:
+		// 已有待处理的保存，它会写入本次更新
+	}
+}
+
+// saveLoop 由唯一的后台 goroutine 执行，依次处理保存请求
+func (rm *RecoveryManager) saveLoop() {
+	defer close(rm.saveDone)
+	for range rm.saveRequests {
 		if err := rm.SaveState(); err != nil {
-			// 记录错误（在实际实现中，使用适当的日志记录）
-			fmt.Printf("Failed to save state: %v\n", err)
+			logger.Warn("Failed to save recovery state: %v", err)
 		}
-	}()
+	}
 }
 
 // calculateCheckpointHash 计算检查点数据的哈希值
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -22,6 +23,7 @@ func TestRecoveryManager_CreateProcess(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建一个进程
 	process := rm.CreateProcess("test-process-1", "Test Process", 5)
@@ -58,6 +60,7 @@ func TestRecoveryManager_UpdateProcess(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建并更新一个进程
 	_ = rm.CreateProcess("test-process-2", "Test Process 2", 3)
@@ -104,6 +107,7 @@ func TestRecoveryManager_CreateCheckpoint(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建一个进程和检查点
 	_ = rm.CreateProcess("test-process-3", "Test Process 3", 2)
@@ -152,6 +156,7 @@ func TestRecoveryManager_SaveLoadState(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm1.Close()
 
 	_ = rm1.CreateProcess("test-process-4", "Test Process 4", 3)
 	rm1.UpdateProcess("test-process-4", func(state *ProcessState) {
@@ -170,6 +175,7 @@ func TestRecoveryManager_SaveLoadState(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create second recovery manager: %v", err)
 	}
+	defer rm2.Close()
 
 	// 验证加载的状态
 	loadedProcess, err := rm2.GetProcess("test-process-4")
@@ -190,6 +196,59 @@ func TestRecoveryManager_SaveLoadState(t *testing.T) {
 	}
 }
 
+// TestRecoveryManager_ConcurrentUpdates 测试并发更新后状态文件完整且包含所有检查点
+func TestRecoveryManager_ConcurrentUpdates(t *testing.T) {
+	tempDir := t.TempDir()
+	config := &RecoveryConfig{
+		StateFile: filepath.Join(tempDir, "test_state.json"),
+		BackupDir: filepath.Join(tempDir, "backups"),
+	}
+
+	rm, err := NewRecoveryManager(config)
+	if err != nil {
+		t.Fatalf("Failed to create recovery manager: %v", err)
+	}
+	rm.CreateProcess("test-process-5", "Test Process 5", 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rm.CreateCheckpoint("test-process-5", "done", map[string]interface{}{"file": fmt.Sprintf("%d.mp4", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	if err := rm.Close(); err != nil {
+		t.Fatalf("Failed to close recovery manager: %v", err)
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read state directory: %v", err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".tmp" {
+			t.Errorf("Temporary state file left behind: %s", entry.Name())
+		}
+	}
+
+	loaded, err := NewRecoveryManager(config)
+	if err != nil {
+		t.Fatalf("Failed to load saved state: %v", err)
+	}
+	defer loaded.Close()
+
+	state, err := loaded.GetProcess("test-process-5")
+	if err != nil {
+		t.Fatalf("Failed to get loaded process: %v", err)
+	}
+	if len(state.Checkpoints) != 50 {
+		t.Errorf("Expected 50 checkpoints, got %d", len(state.Checkpoints))
+	}
+}
+
 // TestNetworkRecoveryStrategy 测试网络恢复
 func TestNetworkRecoveryStrategy(t *testing.T) {
 	strategy := &NetworkRecoveryStrategy{
@@ -396,6 +455,7 @@ func TestRecoveryManager_RecoverProcess(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建一个进程
 	_ = rm.CreateProcess("test-process-5", "Test Process 5", 3)
@@ -435,6 +495,7 @@ func TestRecoveryManager_GetRecoveryStats(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建不同状态的进程
 	rm.CreateProcess("pending-1", "Pending Process", 3)
@@ -502,6 +563,7 @@ func TestRecoveryManager_CleanupOldStates(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建旧的已完成进程
 	_ = rm.CreateProcess("old-completed", "Old Completed Process", 3)
@@ -547,6 +609,7 @@ func BenchmarkRecoveryManager_CreateProcess(b *testing.B) {
 	if err != nil {
 		b.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -567,6 +630,7 @@ func BenchmarkRecoveryManager_UpdateProcess(b *testing.B) {
 	if err != nil {
 		b.Fatalf("Failed to create recovery manager: %v", err)
 	}
+	defer rm.Close()
 
 	// 创建用于基准测试的进程
 	rm.CreateProcess("bench-process", "Benchmark Process", 100)
@@ -2,10 +2,13 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
-	"math/rand"
+	"sync"
 	"time"
+
+	"movie-data-capture/pkg/random"
 )
 
 // RetryableFunc 表示可以重试的函数
@@ -149,7 +152,7 @@ func (c *Config) calculateDelay(attempt int) time.Duration {
 
 	// 如果启用则添加抖动
 	if c.Jitter {
-		jitter := time.Duration(rand.Float64() * float64(delay) * 0.1) // 10% 抖动
+		jitter := time.Duration(random.Float64() * float64(delay) * 0.1) // 10% 抖动
 		delay += jitter
 	}
 
@@ -359,13 +362,19 @@ func RetryWithContextAndCallback(ctx context.Context, fn RetryableFuncWithContex
 	return fmt.Errorf("max retry attempts (%d) exceeded, last error: %w", config.MaxAttempts, lastErr)
 }
 
-// CircuitBreaker 实现熔断器模式
+// ErrCircuitOpen 熔断器处于打开状态，调用被直接拒绝
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker 实现熔断器模式，可在多个 goroutine 间共享
 type CircuitBreaker struct {
+	mu              sync.Mutex
 	maxFailures     int
 	resetTimeout    time.Duration
 	failureCount    int
 	lastFailureTime time.Time
 	state           CircuitState
+	probing         bool // 半开状态下已有一个试探调用在执行
+	onStateChange   func(from, to CircuitState)
 }
 
 // CircuitState 表示熔断器的状态
@@ -377,6 +386,19 @@ const (
 	HalfOpen
 )
 
+// String 返回状态名称：closed, open, half-open
+func (s CircuitState) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
 // NewCircuitBreaker 创建新的熔断器
 func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBreaker {
 	return &CircuitBreaker{
@@ -386,17 +408,43 @@ func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBrea
 	}
 }
 
-// Execute 通过熔断器执行函数
+// OnStateChange 设置状态变化时的回调（如 closed→open），回调在持有锁时调用，不应再访问熔断器
+func (cb *CircuitBreaker) OnStateChange(fn func(from, to CircuitState)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onStateChange = fn
+}
+
+// Execute 通过熔断器执行函数，熔断器打开时返回 ErrCircuitOpen
+// 关闭状态下 fn 执行期间不持有锁，多个调用可以并发执行；
+// 半开状态下只放行一个试探调用，试探结束前其他调用返回 ErrCircuitOpen
 func (cb *CircuitBreaker) Execute(fn func() error) error {
+	cb.mu.Lock()
 	if cb.state == Open {
 		if time.Since(cb.lastFailureTime) > cb.resetTimeout {
-			cb.state = HalfOpen
+			cb.setState(HalfOpen)
 		} else {
-			return fmt.Errorf("circuit breaker is open")
+			cb.mu.Unlock()
+			return ErrCircuitOpen
 		}
 	}
+	probe := cb.state == HalfOpen
+	if probe {
+		if cb.probing {
+			cb.mu.Unlock()
+			return ErrCircuitOpen
+		}
+		cb.probing = true
+	}
+	cb.mu.Unlock()
 
 	err := fn()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if probe {
+		cb.probing = false
+	}
 	if err != nil {
 		cb.onFailure()
 		return err
@@ -406,23 +454,37 @@ func (cb *CircuitBreaker) Execute(fn func() error) error {
 	return nil
 }
 
-// onFailure 处理失败
+// setState 切换状态并通知回调，调用方需持有锁
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state == state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	if cb.onStateChange != nil {
+		cb.onStateChange(from, state)
+	}
+}
+
+// onFailure 处理失败，调用方需持有锁
 func (cb *CircuitBreaker) onFailure() {
 	cb.failureCount++
 	cb.lastFailureTime = time.Now()
 
 	if cb.failureCount >= cb.maxFailures {
-		cb.state = Open
+		cb.setState(Open)
 	}
 }
 
-// onSuccess 处理成功
+// onSuccess 处理成功，调用方需持有锁
 func (cb *CircuitBreaker) onSuccess() {
 	cb.failureCount = 0
-	cb.state = Closed
+	cb.setState(Closed)
 }
 
 // GetState 返回熔断器的当前状态
 func (cb *CircuitBreaker) GetState() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
 	return cb.state
 }
\ No newline at end of file
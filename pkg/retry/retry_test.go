@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -496,6 +497,67 @@ func TestCircuitBreaker(t *testing.T) {
 	}
 }
 
+// TestCircuitBreakerStateChange 测试状态变化回调
+func TestCircuitBreakerStateChange(t *testing.T) {
+	cb := NewCircuitBreaker(2, 50*time.Millisecond)
+
+	var transitions []string
+	cb.OnStateChange(func(from, to CircuitState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	fail := func() error { return errors.New("failure") }
+	cb.Execute(fail)
+	cb.Execute(fail)
+
+	if err := cb.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen while open, got: %v", err)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	cb.Execute(func() error { return nil })
+
+	expected := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if strings.Join(transitions, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected transitions %v, got %v", expected, transitions)
+	}
+}
+
+// TestCircuitBreakerSingleProbe 测试半开状态下只放行一个试探调用
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	cb := NewCircuitBreaker(1, 20*time.Millisecond)
+	cb.Execute(func() error { return errors.New("failure") })
+	time.Sleep(40 * time.Millisecond)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- cb.Execute(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	calls := 0
+	if err := cb.Execute(func() error { calls++; return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen while the probe runs, got: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no call while the probe runs, got %d", calls)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the probe to succeed, got: %v", err)
+	}
+	if cb.GetState() != Closed {
+		t.Errorf("Expected state to be Closed after the probe, got %v", cb.GetState())
+	}
+}
+
 // TestRetryableError 测试可重试错误包装器
 func TestRetryableError(t *testing.T) {
 	originalErr := errors.New("original error")
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// PlannedMove 演练模式下记录的一次计划中的文件移动
+type PlannedMove struct {
+	Source      string
+	Destination string
+	Collision   bool // 目标文件已存在，实际运行时会失败
+}
+
+// dryRunPlan 演练模式下收集的计划移动，可被多个处理协程并发写入
+type dryRunPlan struct {
+	mu    sync.Mutex
+	moves []PlannedMove
+}
+
+// dryRun 是否处于演练模式（只记录计划，不修改文件）
+func (s *Storage) dryRun() bool {
+	return s.config.Common.DryRun
+}
+
+// planMove 记录一次计划中的移动并输出日志
+func (s *Storage) planMove(sourcePath, destPath string, collision bool) {
+	if collision {
+		logger.Warn("[DRY RUN] %s -> %s (destination exists)", sourcePath, destPath)
+	} else {
+		logger.Info("[DRY RUN] %s -> %s", sourcePath, destPath)
+	}
+
+	s.plan.mu.Lock()
+	defer s.plan.mu.Unlock()
+	s.plan.moves = append(s.plan.moves, PlannedMove{Source: sourcePath, Destination: destPath, Collision: collision})
+}
+
+// PlannedMoves 返回演练模式下记录的所有计划移动
+func (s *Storage) PlannedMoves() []PlannedMove {
+	s.plan.mu.Lock()
+	defer s.plan.mu.Unlock()
+	return append([]PlannedMove(nil), s.plan.moves...)
+}
+
+// LogDryRunSummary 输出演练结果汇总：移动的文件数、各目标文件夹的文件数以及目标已存在的冲突
+func (s *Storage) LogDryRunSummary() {
+	moves := s.PlannedMoves()
+
+	folders := make(map[string]int)
+	var collisions []PlannedMove
+	for _, move := range moves {
+		if move.Collision {
+			collisions = append(collisions, move)
+			continue
+		}
+		folders[filepath.Dir(move.Destination)]++
+	}
+
+	names := make([]string, 0, len(folders))
+	width := len("Folder")
+	for folder := range folders {
+		names = append(names, folder)
+		width = max(width, len(folder))
+	}
+	sort.Strings(names)
+
+	logger.Info("==================== Dry Run Summary ====================")
+	logger.Info("%d file(s) would be moved into %d folder(s), %d collision(s)", len(moves)-len(collisions), len(folders), len(collisions))
+	if len(names) > 0 {
+		logger.Info("%-*s  %s", width, "Folder", "Files")
+		for _, folder := range names {
+			logger.Info("%-*s  %d", width, folder, folders[folder])
+		}
+	}
+	for _, move := range collisions {
+		logger.Warn("Collision: %s -> %s", move.Source, move.Destination)
+	}
+}
+
+// destinationExists 判断计划中的目标是否已存在（磁盘上已有或本次演练中已计划）
+func (s *Storage) destinationExists(destPath string) bool {
+	if _, err := os.Stat(destPath); err == nil {
+		return true
+	}
+	s.plan.mu.Lock()
+	defer s.plan.mu.Unlock()
+	for _, move := range s.plan.moves {
+		if !move.Collision && SamePath(move.Destination, destPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// fileNameEncodings 可用于转换非UTF-8文件名的编码（Common.FilenameEncoding）
+var fileNameEncodings = map[string]encoding.Encoding{
+	"shift_jis": japanese.ShiftJIS,
+	"euc-jp":    japanese.EUCJP,
+	"gbk":       simplifiedchinese.GBK,
+	"big5":      traditionalchinese.Big5,
+	"latin1":    charmap.ISO8859_1,
+}
+
+// decodeFileName 将非UTF-8文件名按配置的编码转换为UTF-8
+// 未配置编码或转换失败时保留原始字节，保证文件仍能被找到且名字不被破坏
+func (s *Storage) decodeFileName(name string) string {
+	if utf8.ValidString(name) {
+		return name
+	}
+
+	enc, ok := fileNameEncodings[strings.ToLower(s.config.Common.FilenameEncoding)]
+	if !ok {
+		logger.Warn("Non-UTF-8 filename kept as is: %q", name)
+		return name
+	}
+
+	decoded, err := enc.NewDecoder().String(name)
+	if err != nil || !utf8.ValidString(decoded) || strings.ContainsRune(decoded, utf8.RuneError) {
+		logger.Warn("Failed to decode filename %q as %s, keeping original bytes", name, s.config.Common.FilenameEncoding)
+		return name
+	}
+	logger.Info("Converted %s filename to UTF-8: %s", s.config.Common.FilenameEncoding, decoded)
+	return decoded
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/utils"
+)
+
+// libraryNumbers 输出目录中已有影片的番号索引，每个目录只扫描一次
+type libraryNumbers struct {
+	mu    sync.Mutex
+	roots map[string]map[string]bool
+}
+
+// IsAlreadyProcessed 判断番号是否已整理到 outputPath 中：存在 <番号>.nfo 或以番号命名的影片文件
+// （含 -C、-cd1 等后缀）。首次调用时扫描 outputPath 建立索引，之后的查询不再访问磁盘
+func (s *Storage) IsAlreadyProcessed(number, outputPath string) bool {
+	if number == "" || outputPath == "" {
+		return false
+	}
+	return s.library.numbers(outputPath, s.config.GetMediaTypes())[numberKey(number)]
+}
+
+// numbers 返回 root 下所有NFO和影片文件的番号集合
+func (l *libraryNumbers) numbers(root string, mediaTypes []string) map[string]bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := filepath.Clean(root)
+	if numbers, ok := l.roots[key]; ok {
+		return numbers
+	}
+	if l.roots == nil {
+		l.roots = make(map[string]map[string]bool)
+	}
+
+	numbers := make(map[string]bool)
+	filepath.WalkDir(key, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".nfo" && !slices.Contains(mediaTypes, ext) {
+			return nil
+		}
+		if number := utils.GetNumberFromFilename(d.Name()); number != "" {
+			numbers[numberKey(number)] = true
+		}
+		return nil
+	})
+	logger.Debug("Indexed %d existing movie(s) in %s", len(numbers), key)
+
+	l.roots[key] = numbers
+	return numbers
+}
+
+// numberKey 番号比较时忽略大小写
+func numberKey(number string) string {
+	return strings.ToUpper(strings.TrimSpace(number))
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Windows 上文件被其他进程占用时打开返回的错误码
+const (
+	errorSharingViolation syscall.Errno = 32 // ERROR_SHARING_VIOLATION
+	errorLockViolation    syscall.Errno = 33 // ERROR_LOCK_VIOLATION
+)
+
+// IsFileInUse 判断文件是否正被其他程序写入或占用（下载中、播放中）
+// quietPeriod 内被修改过的文件视为仍在写入；随后尝试以读写方式打开，
+// Windows 上被其他进程独占的文件会打开失败。只读文件、只读挂载或无权限等其他打开错误不视为占用。
+func IsFileInUse(filePath string, quietPeriod time.Duration) bool {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+	if quietPeriod > 0 && time.Since(info.ModTime()) < quietPeriod {
+		return true
+	}
+
+	file, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return isSharingViolation(err)
+	}
+	file.Close()
+	return false
+}
+
+// isSharingViolation 判断打开错误是否由其他进程的共享冲突或文件锁引起
+func isSharingViolation(err error) bool {
+	var errno syscall.Errno
+	if runtime.GOOS != "windows" || !errors.As(err, &errno) {
+		return false
+	}
+	return errno == errorSharingViolation || errno == errorLockViolation
+}
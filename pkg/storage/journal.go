@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// MoveJournalSuffix 分段影片移动日志的后缀，日志与第一个分段的源文件位于同一目录
+const MoveJournalSuffix = ".mdc-move.json"
+
+// GroupMove 一组移动中的一项
+type GroupMove struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// moveJournal 移动前写入的日志，移动全部完成或回滚后删除
+// 程序中途崩溃时日志保留，下次运行据此补完或回滚整组移动
+type moveJournal struct {
+	Number  string      `json:"number"`
+	Created time.Time   `json:"created"`
+	Moves   []GroupMove `json:"moves"`
+}
+
+// MoveGroup 将一组文件（分段影片的各部分）作为整体移动：全部成功，或回滚已移动的部分后返回错误
+// 移动模式下先写入移动日志，崩溃后由 ResumeMoveJournals 补完或回滚
+func (s *Storage) MoveGroup(number string, moves []GroupMove) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	// 目标文件名按 MoveFile 的规则清理，保证日志和回滚使用实际路径
+	for i := range moves {
+		moves[i].Destination = filepath.Join(filepath.Dir(moves[i].Destination), s.sanitizeFileName(filepath.Base(moves[i].Destination)))
+	}
+
+	// 演练模式和链接模式不移动源文件，无需日志
+	if s.dryRun() || s.config.Common.LinkMode > 0 {
+		return s.moveEach(moves)
+	}
+
+	journalPath := moveJournalPath(number, moves[0].Source)
+	if err := writeMoveJournal(journalPath, &moveJournal{Number: number, Created: time.Now(), Moves: moves}); err != nil {
+		return err
+	}
+
+	if err := s.moveEach(moves); err != nil {
+		os.Remove(journalPath)
+		return err
+	}
+	if err := os.Remove(journalPath); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove move journal %s: %v", journalPath, err)
+	}
+	return nil
+}
+
+// moveEach 依次移动，任一失败时按相反顺序撤销已完成的移动
+func (s *Storage) moveEach(moves []GroupMove) error {
+	for i, move := range moves {
+		if err := s.MoveFile(move.Source, move.Destination); err != nil {
+			if !s.dryRun() {
+				s.rollbackMoves(moves[:i])
+			}
+			return fmt.Errorf("failed to move %s: %w", filepath.Base(move.Source), err)
+		}
+	}
+	return nil
+}
+
+// rollbackMoves 撤销已完成的移动：移动模式移回源位置，链接模式删除创建的链接
+func (s *Storage) rollbackMoves(moves []GroupMove) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		move := moves[i]
+		var err error
+		if s.config.Common.LinkMode > 0 {
+			err = os.Remove(move.Destination)
+		} else {
+			err = s.moveFile(move.Destination, move.Source)
+		}
+		if err != nil {
+			logger.Error("Failed to roll back %s -> %s: %v", move.Source, move.Destination, err)
+		} else {
+			logger.Info("Rolled back move of %s", filepath.Base(move.Source))
+		}
+	}
+}
+
+// ResumeMoveJournals 处理上次运行中断时遗留的移动日志
+// 未移动的文件源文件都还在时补完整组移动，否则将已移动的文件移回源位置，保证一组文件不会被拆散
+func (s *Storage) ResumeMoveJournals(sourceFolder string) {
+	if s.dryRun() {
+		return
+	}
+
+	var journals []string
+	filepath.WalkDir(sourceFolder, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(d.Name(), MoveJournalSuffix) {
+			journals = append(journals, path)
+		}
+		return nil
+	})
+
+	for _, path := range journals {
+		if err := s.resumeMoveJournal(path); err != nil {
+			logger.Warn("Failed to resume interrupted move %s: %v", path, err)
+			continue
+		}
+		os.Remove(path)
+	}
+}
+
+// resumeMoveJournal 补完或回滚一个移动日志记录的移动
+func (s *Storage) resumeMoveJournal(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var journal moveJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return fmt.Errorf("invalid move journal: %w", err)
+	}
+
+	var done, pending []GroupMove
+	complete := true
+	for _, move := range journal.Moves {
+		sourceExists, destExists := fileExists(move.Source), fileExists(move.Destination)
+		switch {
+		case !sourceExists && destExists:
+			done = append(done, move)
+		case sourceExists && !destExists:
+			pending = append(pending, move)
+		case sourceExists && destExists && partialCopy(move.Source, move.Destination):
+			// 跨设备复制中途中断，目标只是不完整的副本，删除后重新移动
+			logger.Info("Removing partial copy %s", move.Destination)
+			if err := os.Remove(move.Destination); err != nil {
+				return fmt.Errorf("failed to remove partial copy %s: %w", move.Destination, err)
+			}
+			pending = append(pending, move)
+		default:
+			// 源文件和目标都不存在（或同时存在），无法确定状态，不再补完
+			complete = false
+		}
+	}
+
+	if complete && len(pending) > 0 {
+		logger.Info("Completing interrupted move of %s: %d of %d file(s) left", journal.Number, len(pending), len(journal.Moves))
+		if err := s.moveEach(pending); err == nil {
+			return nil
+		}
+		// moveEach 已撤销 pending 中完成的部分，继续回滚之前已移动的文件
+	} else if complete {
+		return nil
+	}
+
+	logger.Warn("Rolling back interrupted move of %s: %d file(s) moved", journal.Number, len(done))
+	s.rollbackMoves(done)
+	return nil
+}
+
+// moveJournalPath 返回一组移动的日志路径
+func moveJournalPath(number, firstSource string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(number)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(firstSource), filepath.Ext(firstSource))
+	}
+	return filepath.Join(filepath.Dir(firstSource), name+MoveJournalSuffix)
+}
+
+// writeMoveJournal 通过同目录下的临时文件和重命名写入移动日志，崩溃时不会留下不完整的日志
+func writeMoveJournal(path string, journal *moveJournal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal move journal: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write move journal: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write move journal: %w", err)
+	}
+	return nil
+}
+
+// partialCopy 判断目标是否为源文件未复制完的副本（两者都是普通文件且大小不同）
+func partialCopy(source, destination string) bool {
+	sourceInfo, err := os.Lstat(source)
+	if err != nil || !sourceInfo.Mode().IsRegular() {
+		return false
+	}
+	destInfo, err := os.Lstat(destination)
+	if err != nil || !destInfo.Mode().IsRegular() {
+		return false
+	}
+	return destInfo.Size() != sourceInfo.Size()
+}
+
+// fileExists 判断文件是否存在（含符号链接本身）
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// LockSuffix 影片处理锁文件的后缀，锁文件与影片位于同一目录
+const LockSuffix = ".mdc.lock"
+
+// staleLockAge 超过该时长的锁文件视为上次运行异常退出后的残留
+const staleLockAge = 2 * time.Hour
+
+// ErrLocked 影片正在被其他实例处理
+var ErrLocked = errors.New("file is being processed by another instance")
+
+// LockFile 为影片创建锁文件，保证多个实例同时运行时同一文件只被处理一次
+// 返回的函数用于释放锁；锁已被占用时返回 ErrLocked
+func LockFile(filePath string) (func(), error) {
+	lockPath := filePath + LockSuffix
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(file, "pid=%d\ntime=%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+			file.Close()
+			return func() {
+				if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+					logger.Warn("Failed to remove lock file %s: %v", lockPath, err)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		// 清理异常退出留下的过期锁后重试一次
+		info, statErr := os.Stat(lockPath)
+		if statErr != nil || time.Since(info.ModTime()) < staleLockAge {
+			return nil, ErrLocked
+		}
+		logger.Warn("Removing stale lock file: %s", lockPath)
+		os.Remove(lockPath)
+	}
+	return nil, ErrLocked
+}
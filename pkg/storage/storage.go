@@ -8,11 +8,13 @@ import (
 	"runtime"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"movie-data-capture/internal/config"
 	"movie-data-capture/internal/scraper"
 	"movie-data-capture/pkg/logger"
+	"movie-data-capture/pkg/utils"
 )
 
 const (
@@ -26,7 +28,9 @@ const (
 
 // Storage 处理文件操作和文件夹创建
 type Storage struct {
-	config *config.Config
+	config  *config.Config
+	plan    dryRunPlan
+	library libraryNumbers
 }
 
 // New 创建一个新的存储实例
@@ -38,7 +42,8 @@ func New(cfg *config.Config) *Storage {
 
 // CreateFolder 根据位置规则创建输出文件夹
 func (s *Storage) CreateFolder(data *scraper.MovieData) (string, error) {
-	successFolder := s.config.Common.SuccessOutputFolder
+	// 按有码/无码选择输出根目录
+	successFolder := s.config.OutputRoot(data.Uncensored || utils.IsUncensored(data.Number, s.config))
 	
 	// 评估位置规则
 	locationRule := s.config.NameRule.LocationRule
@@ -60,6 +65,11 @@ func (s *Storage) CreateFolder(data *scraper.MovieData) (string, error) {
 		folderPath = strings.ReplaceAll(folderPath, data.Title, shortTitle)
 	}
 	
+	// 清理每一级目录名中Windows不允许的结尾点/空格和保留设备名
+	if s.windowsSafeNames() {
+		folderPath = sanitizePathComponents(folderPath)
+	}
+	
 	// 确保相对路径（添加 ./ 前缀）
 	if !strings.HasPrefix(folderPath, ".") && !strings.HasPrefix(folderPath, "/") {
 		folderPath = "./" + folderPath
@@ -76,6 +86,12 @@ func (s *Storage) CreateFolder(data *scraper.MovieData) (string, error) {
 		fullPath = s.handleWindowsLongPath(fullPath, data)
 	}
 	
+	// 演练模式只计算路径，不创建目录
+	if s.dryRun() {
+		logger.Debug("[DRY RUN] Would create folder: %s", fullPath)
+		return fullPath, nil
+	}
+	
 	// 创建目录
 	err := os.MkdirAll(fullPath, 0755)
 	if err != nil {
@@ -99,27 +115,61 @@ func (s *Storage) CreateFolder(data *scraper.MovieData) (string, error) {
 	return fullPath, nil
 }
 
+// LocationRuleTokens 位置规则中可用的字段（另可使用 extra.<名称> 引用数据源特有字段）
+//
+//	number, title, actor, studio, director, release, year, series, label  刮削数据
+//	resolution   视频分辨率（如 1080p、4K），由ffprobe检测，不可用时按文件名中的4K标记
+//	codec        视频编码（如 H264、HEVC），由ffprobe检测，不可用时按文件名中的 x264/x265 等标记
+//	source_flag  文件名标记：leak、C（中文字幕）、hack，多个时以 - 连接
+var LocationRuleTokens = []string{
+	"number", "title", "actor", "studio", "director", "release", "year", "series", "label",
+	"resolution", "codec", "source_flag",
+}
+
+// UsesLocationToken 判断位置规则是否引用了字段 token
+func (s *Storage) UsesLocationToken(token string) bool {
+	for _, part := range strings.Split(s.config.NameRule.LocationRule, " + ") {
+		if strings.TrimSpace(part) == token {
+			return true
+		}
+	}
+	return false
+}
+
+// rulePart 位置规则中一段求值后的内容
+type rulePart struct {
+	text    string
+	literal bool // 单引号中的字面字符串
+	empty   bool // 值为空的字段
+}
+
 // evaluateLocationRule 评估位置规则模板
 func (s *Storage) evaluateLocationRule(rule string, data *scraper.MovieData) string {
 	result := rule
 	
 	// 定义字段映射
 	fields := map[string]string{
-		"number":   data.Number,
-		"title":    data.Title,
-		"actor":    data.Actor,
-		"studio":   data.Studio,
-		"director": data.Director,
-		"release":  data.Release,
-		"year":     data.Year,
-		"series":   data.Series,
-		"label":    data.Label,
+		"number":      data.Number,
+		"title":       data.Title,
+		"actor":       data.Actor,
+		"studio":      data.Studio,
+		"director":    data.Director,
+		"release":     data.Release,
+		"year":        data.Year,
+		"series":      data.Series,
+		"label":       data.Label,
+		"resolution":  data.Resolution,
+		"codec":       data.Codec,
+		"source_flag": data.SourceFlag,
+	}
+	for placeholder, value := range data.ExtraPlaceholders() {
+		fields[placeholder] = value
 	}
 	
 	// 处理Python风格的表达式，如 "actor + '/' + number"
 	// 逐步解析表达式
 	parts := strings.Split(result, " + ")
-	var resultParts []string
+	var evaluated []rulePart
 	
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
@@ -128,18 +178,24 @@ func (s *Storage) evaluateLocationRule(rule string, data *scraper.MovieData) str
 		if strings.HasPrefix(part, "'") && strings.HasSuffix(part, "'") {
 			// 移除引号并添加字面字符串
 			literal := part[1 : len(part)-1]
-			resultParts = append(resultParts, literal)
+			evaluated = append(evaluated, rulePart{text: literal, literal: true})
 		} else {
 			// 替换字段占位符
 			if value, exists := fields[part]; exists {
-				resultParts = append(resultParts, value)
+				evaluated = append(evaluated, rulePart{text: value, empty: value == ""})
 			} else {
 				// 如果不是已知字段则保持原样
-				resultParts = append(resultParts, part)
+				evaluated = append(evaluated, rulePart{text: part})
 			}
 		}
 	}
 	
+	// 空字段连同相邻的分隔符一起去掉，避免 "ABC-123-" 这样的多余分隔符
+	var resultParts []string
+	for _, part := range collapseEmptyFields(evaluated) {
+		resultParts = append(resultParts, part.text)
+	}
+	
 	// 连接所有部分，将 '/' 视为路径分隔符
 	var pathComponents []string
 	currentComponent := ""
@@ -177,6 +233,47 @@ func (s *Storage) evaluateLocationRule(rule string, data *scraper.MovieData) str
 	return result
 }
 
+// collapseEmptyFields 去掉值为空的字段两侧的分隔符字面量（如 '-'、' '、'[' 和 ']'）
+// 优先去掉前面的分隔符；字段位于目录名开头时去掉后面的分隔符；括号成对去掉
+func collapseEmptyFields(parts []rulePart) []rulePart {
+	removed := make([]bool, len(parts))
+	for i, part := range parts {
+		if !part.empty {
+			continue
+		}
+		prevRemoved := false
+		if i > 0 && isRuleSeparator(parts[i-1]) && !removed[i-1] {
+			removed[i-1] = true
+			prevRemoved = true
+		}
+		if i+1 < len(parts) && isRuleSeparator(parts[i+1]) &&
+			(!prevRemoved || strings.Trim(parts[i+1].text, " )]）】") == "") {
+			removed[i+1] = true
+		}
+	}
+
+	kept := make([]rulePart, 0, len(parts))
+	for i, part := range parts {
+		if !removed[i] && !part.empty {
+			kept = append(kept, part)
+		}
+	}
+	return kept
+}
+
+// isRuleSeparator 判断是否为只包含标点和空白的字面量（路径分隔符 '/' 除外）
+func isRuleSeparator(part rulePart) bool {
+	if !part.literal || part.text == "" || part.text == "/" {
+		return false
+	}
+	for _, r := range part.text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
 // escapePath 转义文件路径中的有问题字符
 func (s *Storage) escapePath(path string) string {
 	literals := s.config.Escape.Literals
@@ -215,24 +312,35 @@ func (s *Storage) sanitizeFileName(fileName string) string {
 		'*':  "∗", // 星号运算符
 	}
 	
+	// 非UTF-8文件名（如Shift-JIS）先按配置转换编码
+	fileName = s.decodeFileName(fileName)
+	
 	result := ""
 	replaced := false
 	
-	for _, char := range fileName {
+	for i := 0; i < len(fileName); {
+		char, size := utf8.DecodeRuneInString(fileName[i:])
+		raw := fileName[i : i+size]
+		i += size
+		
 		// 检查是否是非法字符
-		if replacement, isIllegal := illegalChars[char]; isIllegal {
+		if char == utf8.RuneError && size == 1 {
+			// 无法识别编码的字节原样保留，避免文件名被替换成乱码
+			result += raw
+		} else if replacement, isIllegal := illegalChars[char]; isIllegal {
 			result += replacement
 			replaced = true
 		} else if char < 32 {
 			// 跳过控制字符
 			replaced = true
 		} else {
-			result += string(char)
+			result += raw
 		}
 	}
 	
 	// 移除文件名末尾的点和空格（Windows限制）
 	result = strings.TrimRight(result, ". ")
+	result = escapeReservedName(result)
 	
 	// 如果文件名为空，使用默认名称
 	if result == "" {
@@ -254,6 +362,22 @@ func (s *Storage) MoveFile(sourcePath, destPath string) error {
 	cleanDestFileName := s.sanitizeFileName(destFileName)
 	cleanDestPath := filepath.Join(destDir, cleanDestFileName)
 	
+	// 源和目标是同一个文件时（如模式3或输出目录与源目录重合）无需移动
+	if SamePath(sourcePath, cleanDestPath) {
+		logger.Debug("Source and destination are the same file, skipping move: %s", sourcePath)
+		return nil
+	}
+	
+	// 演练模式只记录计划的移动
+	if s.dryRun() {
+		collision := s.destinationExists(cleanDestPath)
+		s.planMove(sourcePath, cleanDestPath, collision)
+		if collision {
+			return fmt.Errorf("destination file already exists: %s", cleanDestPath)
+		}
+		return nil
+	}
+	
 	// 检查目标文件是否已存在
 	if _, err := os.Stat(cleanDestPath); err == nil {
 		return fmt.Errorf("destination file already exists: %s", cleanDestPath)
@@ -289,6 +413,28 @@ func (s *Storage) MoveFile(sourcePath, destPath string) error {
 	}
 }
 
+// SamePath 判断两个路径是否指向同一位置
+// 会解析相对路径和目录中的符号链接；Windows 和 macOS 上忽略大小写
+func SamePath(a, b string) bool {
+	resolvedA, resolvedB := resolvePath(a), resolvePath(b)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(resolvedA, resolvedB)
+	}
+	return resolvedA == resolvedB
+}
+
+// resolvePath 返回路径的绝对形式，所在目录存在时解析其中的符号链接
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = filepath.Clean(path)
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
+
 // moveFile 将文件从源位置移动到目标位置
 func (s *Storage) moveFile(sourcePath, destPath string) error {
 	err := os.Rename(sourcePath, destPath)
@@ -342,6 +488,11 @@ func (s *Storage) createHardLink(sourcePath, destPath string) error {
 
 // copyAndDelete 复制文件并删除源文件
 func (s *Storage) copyAndDelete(sourcePath, destPath string) error {
+	// 同一文件时 os.Create 会截断源文件
+	if SamePath(sourcePath, destPath) {
+		return nil
+	}
+	
 	// 打开源文件
 	srcFile, err := os.Open(sourcePath)
 	if err != nil {
@@ -384,6 +535,11 @@ func (s *Storage) copyAndDelete(sourcePath, destPath string) error {
 func (s *Storage) MoveToFailedFolder(filePath string) error {
 	failedFolder := s.config.Common.FailedOutputFolder
 	
+	if s.dryRun() {
+		logger.Info("[DRY RUN] Would handle failed file: %s", filePath)
+		return nil
+	}
+	
 	// 如果失败文件夹不存在则创建
 	if err := os.MkdirAll(failedFolder, 0755); err != nil {
 		return fmt.Errorf("failed to create failed folder: %w", err)
@@ -405,6 +561,59 @@ func (s *Storage) MoveToFailedFolder(filePath string) error {
 	return nil
 }
 
+// MoveToSkippedFolder 将因内容过滤被跳过的文件移动到指定文件夹
+// 模式3或链接模式下不移动源文件
+func (s *Storage) MoveToSkippedFolder(filePath, skipFolder string) error {
+	if skipFolder == "" || s.config.Common.MainMode == 3 || s.config.Common.LinkMode > 0 {
+		return nil
+	}
+	if s.dryRun() {
+		logger.Info("[DRY RUN] Would move skipped file %s to %s", filePath, skipFolder)
+		return nil
+	}
+
+	if err := os.MkdirAll(skipFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create skip folder: %w", err)
+	}
+	return s.moveToSkippedFolder(filePath, skipFolder)
+}
+
+// moveToSkippedFolder 将文件移动到跳过文件夹
+// 同名文件已存在时改用 "name (1).ext" 等名称，从不删除源文件
+func (s *Storage) moveToSkippedFolder(filePath, skipFolder string) error {
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			logger.Warn("Source file no longer exists, skipping move: %s", filePath)
+			return nil
+		}
+		return fmt.Errorf("failed to check source file: %w", err)
+	}
+
+	fileName := s.sanitizeFileName(filepath.Base(filePath))
+	ext := filepath.Ext(fileName)
+	base := strings.TrimSuffix(fileName, ext)
+	destPath := filepath.Join(skipFolder, fileName)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(destPath); os.IsNotExist(err) {
+			break
+		}
+		destPath = filepath.Join(skipFolder, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+
+	if err := os.Rename(filePath, destPath); err != nil {
+		// 跨驱动器时复制后删除
+		if !strings.Contains(err.Error(), "cross-device") && !strings.Contains(err.Error(), "different") {
+			return fmt.Errorf("failed to move file to skip folder: %w", err)
+		}
+		if copyErr := s.copyAndRemove(filePath, destPath); copyErr != nil {
+			return fmt.Errorf("failed to move file (copy method): %w", copyErr)
+		}
+	}
+
+	logger.Info("Moved skipped file: %s -> %s", filePath, destPath)
+	return nil
+}
+
 // addToFailedList 将文件路径添加到失败列表
 func (s *Storage) addToFailedList(filePath, failedFolder string) error {
 	failedListPath := filepath.Join(failedFolder, "failed_list.txt")
@@ -424,6 +633,34 @@ func (s *Storage) addToFailedList(filePath, failedFolder string) error {
 	return nil
 }
 
+// FailedReasonsFile 失败文件夹中记录各数据源失败原因的文件名
+const FailedReasonsFile = "failed_reasons.txt"
+
+// AddFailureReasons 将刮削失败的文件、番号和各数据源的失败原因追加到 failed_reasons.txt
+// 每行格式：文件路径<TAB>番号<TAB>来源: 原因; 来源: 原因
+func (s *Storage) AddFailureReasons(filePath, number, reasons string) error {
+	if s.dryRun() {
+		logger.Info("[DRY RUN] Would record failure reasons for %s: %s", filePath, reasons)
+		return nil
+	}
+
+	failedFolder := s.config.Common.FailedOutputFolder
+	if err := os.MkdirAll(failedFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create failed folder: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(failedFolder, FailedReasonsFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open failure reasons file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s\t%s\t%s\n", filePath, number, reasons); err != nil {
+		return fmt.Errorf("failed to write failure reasons: %w", err)
+	}
+	return nil
+}
+
 // moveToFailedFolder 将文件移动到失败文件夹
 func (s *Storage) moveToFailedFolder(filePath, failedFolder string) error {
 	fileName := filepath.Base(filePath)
@@ -490,6 +727,11 @@ func (s *Storage) moveToFailedFolder(filePath, failedFolder string) error {
 
 // copyAndRemove 复制文件后删除源文件（用于跨驱动器移动）
 func (s *Storage) copyAndRemove(src, dst string) error {
+	// 同一文件时 os.Create 会截断源文件
+	if SamePath(src, dst) {
+		return nil
+	}
+	
 	// 打开源文件
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -631,55 +873,122 @@ func (s *Storage) MoveSubtitleFiles(subtitleFiles []string, videoFileName, destD
 	
 	videoBase := strings.TrimSuffix(videoFileName, filepath.Ext(videoFileName))
 	
-	for _, subtitlePath := range subtitleFiles {
-		// 获取字幕文件信息
-		subtitleName := filepath.Base(subtitlePath)
-		subtitleExt := filepath.Ext(subtitleName)
-		originalBase := strings.TrimSuffix(subtitleName, subtitleExt)
-		
-		// 提取语言/类型后缀（如 .zh, .chs, .eng, .forced 等）
-		// 示例: movie.zh.srt -> .zh
-		//      movie.chs.srt -> .chs
-		//      movie.forced.srt -> .forced
-		suffix := ""
-		if idx := strings.Index(originalBase, "."); idx != -1 {
-			suffix = originalBase[idx:] // 保留所有点号后的部分
-		} else if idx := strings.Index(originalBase, "_"); idx != -1 {
-			// 处理下划线分隔的情况
-			suffix = "." + originalBase[idx+1:]
-		}
-		
-		// 生成新的字幕文件名
-		// 如果有语言后缀，保留它；否则直接使用视频基础名
-		var newSubtitleName string
-		if suffix != "" && suffix != "."+strings.ToLower(videoBase) {
-			newSubtitleName = videoBase + suffix + subtitleExt
-		} else {
-			newSubtitleName = videoBase + subtitleExt
-		}
-		
-		destPath := filepath.Join(destDir, newSubtitleName)
+	// 按原始基础名分组（如 movie.idx + movie.sub 为一组），同组文件使用相同的新名称
+	groups, order := groupSubtitleFiles(subtitleFiles)
+	usedNames := make(map[string]bool)
+	
+	for _, originalBase := range order {
+		group := groups[originalBase]
+		newBase := subtitleTargetBase(originalBase, videoBase)
 		
-		// 检查目标文件是否已存在
-		if _, err := os.Stat(destPath); err == nil {
-			logger.Debug("Subtitle file already exists at destination: %s", newSubtitleName)
-			continue
+		if s.config.Media.SubtitleGrouping {
+			// 为整组选择一个不冲突的名称，避免多个字幕互相覆盖或配对被拆散
+			newBase = s.uniqueSubtitleBase(newBase, group, destDir, usedNames)
 		}
 		
-		// 移动字幕文件（使用与视频文件相同的link_mode）
-		err := s.MoveFile(subtitlePath, destPath)
-		if err != nil {
-			logger.Warn("Failed to move subtitle file %s: %v", subtitleName, err)
-			// 继续处理其他字幕文件，不中断
-			continue
+		for _, subtitlePath := range group {
+			subtitleName := filepath.Base(subtitlePath)
+			subtitleExt := filepath.Ext(subtitleName)
+			newSubtitleName := newBase + subtitleExt
+			usedNames[strings.ToLower(newSubtitleName)] = true
+			
+			destPath := filepath.Join(destDir, newSubtitleName)
+			
+			// 检查目标文件是否已存在
+			if _, err := os.Stat(destPath); err == nil {
+				logger.Debug("Subtitle file already exists at destination: %s", newSubtitleName)
+				continue
+			}
+			
+			// 移动字幕文件（使用与视频文件相同的link_mode）
+			err := s.MoveFile(subtitlePath, destPath)
+			if err != nil {
+				logger.Warn("Failed to move subtitle file %s: %v", subtitleName, err)
+				// 继续处理其他字幕文件，不中断
+				continue
+			}
+			
+			logger.Info("Moved subtitle file: %s -> %s", subtitleName, newSubtitleName)
+			
+			// 可选：将ASS/SSA转换为播放器兼容性更好的SRT
+			if s.config.Media.ConvertAssToSrt && isASSSubtitle(subtitleExt) && !s.dryRun() {
+				srtPath := filepath.Join(destDir, newBase+".srt")
+				if _, err := os.Stat(srtPath); err == nil {
+					logger.Debug("SRT subtitle already exists, skipping conversion: %s", filepath.Base(srtPath))
+					continue
+				}
+				if err := ConvertASSToSRT(destPath, srtPath); err != nil {
+					logger.Warn("Failed to convert subtitle %s to SRT: %v", newSubtitleName, err)
+				} else {
+					logger.Info("Converted subtitle: %s -> %s", newSubtitleName, filepath.Base(srtPath))
+				}
+			}
 		}
-		
-		logger.Info("Moved subtitle file: %s -> %s", subtitleName, newSubtitleName)
 	}
 	
 	return nil
 }
 
+// groupSubtitleFiles 按去掉扩展名后的文件名分组字幕文件，保持原有顺序
+func groupSubtitleFiles(subtitleFiles []string) (map[string][]string, []string) {
+	groups := make(map[string][]string)
+	var order []string
+	for _, subtitlePath := range subtitleFiles {
+		name := filepath.Base(subtitlePath)
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		if _, exists := groups[base]; !exists {
+			order = append(order, base)
+		}
+		groups[base] = append(groups[base], subtitlePath)
+	}
+	return groups, order
+}
+
+// subtitleTargetBase 根据原始字幕名计算新的基础名称（不含扩展名）
+func subtitleTargetBase(originalBase, videoBase string) string {
+	// 提取语言/类型后缀（如 .zh, .chs, .eng, .forced 等）
+	// 示例: movie.zh.srt -> .zh
+	//      movie.chs.srt -> .chs
+	//      movie.forced.srt -> .forced
+	suffix := ""
+	if idx := strings.Index(originalBase, "."); idx != -1 {
+		suffix = originalBase[idx:] // 保留所有点号后的部分
+	} else if idx := strings.Index(originalBase, "_"); idx != -1 {
+		// 处理下划线分隔的情况
+		suffix = "." + originalBase[idx+1:]
+	}
+	
+	// 如果有语言后缀，保留它；否则直接使用视频基础名
+	if suffix != "" && suffix != "."+strings.ToLower(videoBase) {
+		return videoBase + suffix
+	}
+	return videoBase
+}
+
+// uniqueSubtitleBase 为一组字幕选择不与本批次其他字幕冲突的基础名称
+// 目标目录中已存在整组文件时视为重复运行，保持原名称；只存在部分文件时换用新名称，避免配对被拆散
+func (s *Storage) uniqueSubtitleBase(base string, group []string, destDir string, usedNames map[string]bool) string {
+	candidate := base
+	for i := 1; ; i++ {
+		conflict := false
+		existing := 0
+		for _, subtitlePath := range group {
+			name := candidate + filepath.Ext(subtitlePath)
+			if usedNames[strings.ToLower(name)] {
+				conflict = true
+				break
+			}
+			if _, err := os.Stat(filepath.Join(destDir, name)); err == nil {
+				existing++
+			}
+		}
+		if !conflict && (existing == 0 || existing == len(group)) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d", base, i)
+	}
+}
+
 // handleWindowsLongPath 处理Windows平台的长路径问题
 // 如果路径超过限制，采用智能策略缩短路径或添加长路径前缀
 func (s *Storage) handleWindowsLongPath(fullPath string, data *scraper.MovieData) string {
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"movie-data-capture/internal/config"
+)
+
+// TestMoveToSkippedFolder_KeepsSourceOnConflict 测试跳过文件夹中已有同名文件时改名而不删除源文件
+func TestMoveToSkippedFolder_KeepsSourceOnConflict(t *testing.T) {
+	srcDir := t.TempDir()
+	skipDir := t.TempDir()
+
+	src := filepath.Join(srcDir, "ABC-123.mp4")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	for name, content := range map[string]string{"ABC-123.mp4": "first", "ABC-123 (1).mp4": "second"} {
+		if err := os.WriteFile(filepath.Join(skipDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write existing file: %v", err)
+		}
+	}
+
+	s := New(&config.Config{})
+	if err := s.MoveToSkippedFolder(src, skipDir); err != nil {
+		t.Fatalf("MoveToSkippedFolder failed: %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be moved, got: %v", err)
+	}
+	for name, content := range map[string]string{"ABC-123.mp4": "first", "ABC-123 (1).mp4": "second", "ABC-123 (2).mp4": "new"} {
+		got, err := os.ReadFile(filepath.Join(skipDir, name))
+		if err != nil {
+			t.Errorf("Expected %s in skip folder: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s contains %q, want %q", name, got, content)
+		}
+	}
+}
+
+// TestIsFileInUse_ReadOnly 测试只读文件和只读挂载不被视为占用
+func TestIsFileInUse_ReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ABC-123.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0444); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if IsFileInUse(path, 0) {
+		t.Error("Expected a read-only file not to be in use")
+	}
+	if !IsFileInUse(path, time.Hour) {
+		t.Error("Expected a recently modified file to be in use")
+	}
+
+	for _, errno := range []syscall.Errno{syscall.EROFS, syscall.EACCES, syscall.EPERM} {
+		err := &os.PathError{Op: "open", Path: path, Err: errno}
+		if isSharingViolation(err) {
+			t.Errorf("Expected %v not to count as a sharing violation", errno)
+		}
+	}
+}
+
+// TestResumeMoveJournal_RemovesPartialCopy 测试补完中断的移动时删除不完整的目标副本
+func TestResumeMoveJournal_RemovesPartialCopy(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	moves := []GroupMove{
+		{Source: filepath.Join(srcDir, "ABC-123-cd1.mp4"), Destination: filepath.Join(destDir, "ABC-123-cd1.mp4")},
+		{Source: filepath.Join(srcDir, "ABC-123-cd2.mp4"), Destination: filepath.Join(destDir, "ABC-123-cd2.mp4")},
+	}
+	for path, content := range map[string]string{
+		moves[0].Destination: "part one",
+		moves[1].Source:      "part two",
+		moves[1].Destination: "part",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	journalPath := moveJournalPath("ABC-123", moves[0].Source)
+	if err := writeMoveJournal(journalPath, &moveJournal{Number: "ABC-123", Created: time.Now(), Moves: moves}); err != nil {
+		t.Fatalf("writeMoveJournal failed: %v", err)
+	}
+
+	New(&config.Config{}).ResumeMoveJournals(srcDir)
+
+	for _, move := range moves {
+		if _, err := os.Stat(move.Source); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved, got: %v", move.Source, err)
+		}
+	}
+	if got, err := os.ReadFile(moves[1].Destination); err != nil || string(got) != "part two" {
+		t.Errorf("Destination contains %q (%v), want %q", got, err, "part two")
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Errorf("Expected journal to be removed, got: %v", err)
+	}
+}
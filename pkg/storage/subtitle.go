@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// assOverrideRegex 匹配ASS样式覆盖标签，如 {\an8} {\pos(10,10)}
+var assOverrideRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// chineseSubtitleTokens 文件名中表示中文字幕的标记
+var chineseSubtitleTokens = map[string]bool{
+	"zh": true, "chs": true, "cht": true, "chi": true, "zho": true, "chn": true,
+	"sc": true, "tc": true, "gb": true, "big5": true, "zh-cn": true, "zh-tw": true,
+	"zh-hans": true, "zh-hant": true, "简体": true, "繁体": true, "中文": true, "简中": true, "繁中": true,
+}
+
+// otherSubtitleTokens 文件名中表示其他语言字幕的标记
+var otherSubtitleTokens = map[string]bool{
+	"en": true, "eng": true, "english": true, "ja": true, "jp": true, "jpn": true, "ko": true, "kor": true,
+}
+
+// textSubtitleExts 可以读取内容判断语言的文本字幕格式
+var textSubtitleExts = map[string]bool{
+	".srt": true, ".ass": true, ".ssa": true, ".vtt": true, ".smi": true, ".lrc": true, ".sbv": true, ".ttml": true,
+}
+
+// subtitleSniffSize 判断字幕语言时读取的最大字节数
+const subtitleSniffSize = 64 * 1024
+
+// IsChineseSubtitle 判断字幕文件是否为中文字幕
+// 优先根据文件名中的语言标记判断，没有标记时读取文本字幕内容，按汉字与假名的比例判断
+func IsChineseSubtitle(path string) bool {
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if strings.Contains(base, "中文") || strings.Contains(base, "简体") || strings.Contains(base, "繁体") {
+		return true
+	}
+
+	tokens := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '.' || r == '_' || r == ' ' || r == '[' || r == ']' || r == '(' || r == ')'
+	})
+	for _, token := range tokens {
+		if chineseSubtitleTokens[token] {
+			return true
+		}
+		if otherSubtitleTokens[token] {
+			return false
+		}
+	}
+
+	if !textSubtitleExts[strings.ToLower(filepath.Ext(path))] {
+		return false
+	}
+	return sniffChineseText(path)
+}
+
+// sniffChineseText 读取字幕开头部分，汉字明显多于假名时认为是中文
+// 非UTF-8编码（如GBK、Shift-JIS）无法可靠区分，按非中文处理
+func sniffChineseText(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, subtitleSniffSize))
+	if err != nil {
+		return false
+	}
+
+	var han, kana int
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		if r == utf8.RuneError && size == 1 {
+			// 截断处的不完整字符可以忽略，其余视为非UTF-8
+			if len(data) > 3 {
+				return false
+			}
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			kana++
+		}
+	}
+
+	return han >= 20 && kana*10 < han
+}
+
+// isASSSubtitle 判断扩展名是否为ASS/SSA字幕
+func isASSSubtitle(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".ass" || ext == ".ssa"
+}
+
+// ConvertASSToSRT 将ASS/SSA字幕转换为SRT格式
+// 仅保留时间轴和文本，样式与特效标签会被移除
+func ConvertASSToSRT(srcPath, dstPath string) error {
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open subtitle: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		inEvents   bool
+		startIdx   = 1
+		endIdx     = 2
+		textIdx    = 9
+		fieldCount = 10
+		builder    strings.Builder
+		index      int
+	)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		// 根据Format行确定各字段位置
+		if strings.HasPrefix(line, "Format:") {
+			fields := strings.Split(strings.TrimPrefix(line, "Format:"), ",")
+			fieldCount = len(fields)
+			for i, field := range fields {
+				switch strings.ToLower(strings.TrimSpace(field)) {
+				case "start":
+					startIdx = i
+				case "end":
+					endIdx = i
+				case "text":
+					textIdx = i
+				}
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(line, "Dialogue:") {
+			continue
+		}
+
+		// Text为最后一个字段，可能包含逗号
+		fields := strings.SplitN(strings.TrimPrefix(line, "Dialogue:"), ",", fieldCount)
+		if len(fields) < fieldCount || textIdx >= len(fields) {
+			continue
+		}
+
+		start, err := assTimeToSRT(fields[startIdx])
+		if err != nil {
+			continue
+		}
+		end, err := assTimeToSRT(fields[endIdx])
+		if err != nil {
+			continue
+		}
+
+		text := assTextToSRT(fields[textIdx])
+		if text == "" {
+			continue
+		}
+
+		index++
+		fmt.Fprintf(&builder, "%d\n%s --> %s\n%s\n\n", index, start, end, text)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read subtitle: %w", err)
+	}
+	if index == 0 {
+		return fmt.Errorf("no dialogue lines found")
+	}
+
+	if err := os.WriteFile(dstPath, []byte(builder.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write SRT subtitle: %w", err)
+	}
+	return nil
+}
+
+// assTimeToSRT 将ASS时间（H:MM:SS.cc）转换为SRT时间（HH:MM:SS,mmm）
+func assTimeToSRT(value string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid time: %s", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return "", err
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", err
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return "", err
+	}
+
+	millis := int(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", hours, minutes, millis/1000, millis%1000), nil
+}
+
+// assTextToSRT 移除ASS样式标签并转换换行符
+func assTextToSRT(text string) string {
+	text = assOverrideRegex.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, `\N`, "\n")
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	text = strings.ReplaceAll(text, `\h`, " ")
+	return strings.TrimSpace(text)
+}
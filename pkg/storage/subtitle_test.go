@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"movie-data-capture/internal/config"
+)
+
+const assHeader = "[Script Info]\nTitle: Test\n\n[V4+ Styles]\nFormat: Name, Fontname\nStyle: Default,Arial\n\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n"
+
+// TestConvertASSToSRT 测试ASS到SRT的转换
+func TestConvertASSToSRT(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   string
+		expected string
+	}{
+		{
+			name:     "plain dialogue",
+			events:   "Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,Hello\n",
+			expected: "1\n00:00:01,000 --> 00:00:02,500\nHello\n\n",
+		},
+		{
+			name:     "override tags",
+			events:   "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\an8}{\\i1}Top{\\i0} line\n",
+			expected: "1\n00:00:01,000 --> 00:00:02,000\nTop line\n\n",
+		},
+		{
+			name:     "line breaks and hard spaces",
+			events:   "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,First\\NSecond\\nThird\\hword\n",
+			expected: "1\n00:00:01,000 --> 00:00:02,000\nFirst\nSecond\nThird word\n\n",
+		},
+		{
+			name:     "commas in text",
+			events:   "Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,One, two, three\n",
+			expected: "1\n00:00:01,000 --> 00:00:02,000\nOne, two, three\n\n",
+		},
+		{
+			name:     "timestamp formatting",
+			events:   "Dialogue: 0,1:02:03.45,10:59:59.99,Default,,0,0,0,,Late\n",
+			expected: "1\n01:02:03,450 --> 10:59:59,990\nLate\n\n",
+		},
+		{
+			name: "skips empty and malformed lines",
+			events: "Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,Note\n" +
+				"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\fad(100,100)}\n" +
+				"Dialogue: 0,bad,0:00:02.00,Default,,0,0,0,,Broken\n" +
+				"Dialogue: 0,0:00:03.00,0:00:04.00,Default,,0,0,0,,Kept\n",
+			expected: "1\n00:00:03,000 --> 00:00:04,000\nKept\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "movie.ass")
+			dst := filepath.Join(dir, "movie.srt")
+			if err := os.WriteFile(src, []byte("\ufeff"+assHeader+tt.events), 0644); err != nil {
+				t.Fatalf("Failed to write ASS subtitle: %v", err)
+			}
+
+			if err := ConvertASSToSRT(src, dst); err != nil {
+				t.Fatalf("ConvertASSToSRT failed: %v", err)
+			}
+
+			got, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatalf("Failed to read SRT subtitle: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("ConvertASSToSRT() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestConvertASSToSRT_NoDialogue 测试没有对白时返回错误且不写入文件
+func TestConvertASSToSRT_NoDialogue(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "movie.ass")
+	dst := filepath.Join(dir, "movie.srt")
+	if err := os.WriteFile(src, []byte(assHeader), 0644); err != nil {
+		t.Fatalf("Failed to write ASS subtitle: %v", err)
+	}
+
+	if err := ConvertASSToSRT(src, dst); err == nil {
+		t.Error("Expected an error for a subtitle without dialogue")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("Expected no SRT file to be written, got: %v", err)
+	}
+}
+
+// TestMoveSubtitleFiles_KeepsVobSubPairs 测试.idx/.sub配对始终使用相同的新名称
+func TestMoveSubtitleFiles_KeepsVobSubPairs(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	var subtitles []string
+	for _, name := range []string{"first.idx", "first.sub", "second.idx", "second.sub"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write subtitle: %v", err)
+		}
+		subtitles = append(subtitles, path)
+	}
+
+	// 目标目录中已有一个不成对的.sub，第一组不能与之拼成一对
+	if err := os.WriteFile(filepath.Join(destDir, "ABC-123.sub"), []byte("existing"), 0644); err != nil {
+		t.Fatalf("Failed to write existing subtitle: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.SubtitleGrouping = true
+	s := New(cfg)
+
+	if err := s.MoveSubtitleFiles(subtitles, "ABC-123.mp4", destDir); err != nil {
+		t.Fatalf("MoveSubtitleFiles failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"ABC-123.sub":   "existing",
+		"ABC-123.1.idx": "first.idx",
+		"ABC-123.1.sub": "first.sub",
+		"ABC-123.2.idx": "second.idx",
+		"ABC-123.2.sub": "second.sub",
+	}
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		t.Fatalf("Failed to read destination: %v", err)
+	}
+	if len(entries) != len(expected) {
+		t.Errorf("Expected %d files in destination, got %d", len(expected), len(entries))
+	}
+	for name, content := range expected {
+		got, err := os.ReadFile(filepath.Join(destDir, name))
+		if err != nil {
+			t.Errorf("Expected %s in destination: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s contains %q, want %q", name, got, content)
+		}
+	}
+}
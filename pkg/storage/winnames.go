@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// windowsReservedNames Windows保留的设备名，无论扩展名如何都不能用作文件或目录名
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsSafeNames 是否按Windows规则清理目录名（Windows上总是清理）
+func (s *Storage) windowsSafeNames() bool {
+	return runtime.GOOS == "windows" || s.config.NameRule.WindowsSafePaths
+}
+
+// escapeReservedName 保留设备名（如 CON、NUL.txt）后加下划线
+func escapeReservedName(name string) string {
+	base := name
+	if i := strings.Index(base, "."); i >= 0 {
+		base = base[:i]
+	}
+	if windowsReservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+		return strings.TrimSpace(base) + "_" + name[len(base):]
+	}
+	return name
+}
+
+// sanitizePathComponent 清理单级目录名：去掉结尾的点和空格，转义保留设备名
+// "." 和 ".." 保持不变
+func sanitizePathComponent(name string) string {
+	if name == "." || name == ".." || name == "" {
+		return name
+	}
+	cleaned := strings.TrimRight(name, ". ")
+	if cleaned == "" {
+		cleaned = "_"
+	}
+	return escapeReservedName(cleaned)
+}
+
+// sanitizePathComponents 清理相对路径中的每一级目录名
+func sanitizePathComponents(path string) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		parts[i] = sanitizePathComponent(part)
+	}
+	return filepath.FromSlash(strings.Join(parts, "/"))
+}
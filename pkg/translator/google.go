@@ -0,0 +1,177 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/httpclient"
+)
+
+// defaultGoogleSite serves the free web endpoint
+const defaultGoogleSite = "translate.googleapis.com"
+
+// deadGoogleSites no longer serve translations (translate.google.cn closed in 2022),
+// older configs naming them use defaultGoogleSite instead
+var deadGoogleSites = map[string]bool{
+	"translate.google.cn": true,
+}
+
+// googleCloudEndpoint is the Cloud Translation v2 API used when translate.key is set
+const googleCloudEndpoint = "https://translation.googleapis.com/language/translate/v2"
+
+// GoogleTranslator translates through Google Translate. Without an API key it uses the
+// free web endpoint on translate.service_site; with a key it uses the Cloud Translation API.
+type GoogleTranslator struct {
+	client *httpclient.Client
+	site   string
+	key    string
+	delay  time.Duration
+
+	mu       sync.Mutex
+	lastCall time.Time
+}
+
+// NewGoogleTranslator creates a Google translator using the proxy settings of cfg
+func NewGoogleTranslator(cfg *config.Config) *GoogleTranslator {
+	site := strings.TrimSpace(cfg.Translate.ServiceSite)
+	if site == "" || deadGoogleSites[strings.ToLower(strings.TrimSuffix(site, "/"))] {
+		site = defaultGoogleSite
+	}
+	key := ""
+	if strings.EqualFold(cfg.Translate.Engine, "google") {
+		key = cfg.Translate.Key
+	}
+	return &GoogleTranslator{
+		client: httpclient.NewClient(&cfg.Proxy),
+		site:   strings.TrimSuffix(site, "/"),
+		key:    key,
+		delay:  time.Duration(cfg.Translate.Delay) * time.Second,
+	}
+}
+
+// Translate implements Translator
+func (g *GoogleTranslator) Translate(ctx context.Context, text, fromLang, toLang string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+
+	if g.key != "" {
+		return g.translateCloud(ctx, text, fromLang, toLang)
+	}
+	return g.translateFree(ctx, text, fromLang, toLang)
+}
+
+// wait keeps translate.delay between two requests
+func (g *GoogleTranslator) wait(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if remaining := g.delay - time.Since(g.lastCall); remaining > 0 {
+		select {
+		case <-time.After(remaining):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	g.lastCall = time.Now()
+	return nil
+}
+
+// translateFree uses the web endpoint, which answers with nested arrays:
+// [[["translated part","source part",...],...],...]
+func (g *GoogleTranslator) translateFree(ctx context.Context, text, fromLang, toLang string) (string, error) {
+	source := languageCode(fromLang)
+	if source == "" {
+		source = "auto"
+	}
+	query := url.Values{}
+	query.Set("client", "gtx")
+	query.Set("dt", "t")
+	query.Set("sl", source)
+	query.Set("tl", languageCode(toLang))
+	query.Set("q", text)
+
+	body, err := g.client.GetBytes(ctx, "https://"+g.site+"/translate_a/single?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("google translate request failed: %w", err)
+	}
+
+	var response []interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse google translate response: %w", err)
+	}
+	if len(response) == 0 {
+		return "", fmt.Errorf("empty google translate response")
+	}
+	segments, ok := response[0].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("unexpected google translate response")
+	}
+
+	var b strings.Builder
+	for _, segment := range segments {
+		parts, ok := segment.([]interface{})
+		if !ok || len(parts) == 0 {
+			continue
+		}
+		if translated, ok := parts[0].(string); ok {
+			b.WriteString(translated)
+		}
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("google translate returned no text")
+	}
+	return b.String(), nil
+}
+
+// translateCloud uses the Cloud Translation v2 API
+func (g *GoogleTranslator) translateCloud(ctx context.Context, text, fromLang, toLang string) (string, error) {
+	form := url.Values{}
+	form.Set("q", text)
+	form.Set("target", languageCode(toLang))
+	form.Set("format", "text")
+	if source := languageCode(fromLang); source != "" {
+		form.Set("source", source)
+	}
+
+	resp, err := g.client.Post(ctx, googleCloudEndpoint+"?key="+url.QueryEscape(g.key), strings.NewReader(form.Encode()),
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+	if err != nil {
+		return "", fmt.Errorf("google cloud translate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read google cloud translate response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("google cloud translate: HTTP %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse google cloud translate response: %w", err)
+	}
+	if len(response.Data.Translations) == 0 {
+		return "", fmt.Errorf("google cloud translate returned no text")
+	}
+	return response.Data.Translations[0].TranslatedText, nil
+}
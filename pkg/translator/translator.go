@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"movie-data-capture/internal/config"
+)
+
+// Translator translates scraped text such as titles and outlines
+type Translator interface {
+	// Translate returns text translated from fromLang to toLang.
+	// An empty fromLang lets the provider detect the source language.
+	Translate(ctx context.Context, text, fromLang, toLang string) (string, error)
+}
+
+// NopTranslator returns the text unchanged. It is used when translation is disabled.
+type NopTranslator struct{}
+
+// Translate implements Translator
+func (NopTranslator) Translate(ctx context.Context, text, fromLang, toLang string) (string, error) {
+	return text, nil
+}
+
+// New creates the translator configured in cfg.Translate.
+// It returns a NopTranslator when translation is disabled.
+func New(cfg *config.Config) (Translator, error) {
+	if !cfg.Translate.Switch {
+		return NopTranslator{}, nil
+	}
+
+	switch strings.ToLower(cfg.Translate.Engine) {
+	case "", "google-free", "google":
+		return NewGoogleTranslator(cfg), nil
+	default:
+		return nil, fmt.Errorf("translate engine %q is not supported yet", cfg.Translate.Engine)
+	}
+}
+
+// Fields returns the MovieData fields listed in cfg.Translate.Values, lowercased
+func Fields(cfg *config.Config) map[string]bool {
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(cfg.Translate.Values, ",") {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			fields[field] = true
+		}
+	}
+	return fields
+}
+
+// languageCode converts the configured language (zh_cn) to the code used by the APIs (zh-CN)
+func languageCode(lang string) string {
+	switch strings.ToLower(lang) {
+	case "":
+		return ""
+	case "zh_cn", "zh-cn":
+		return "zh-CN"
+	case "zh_tw", "zh-tw":
+		return "zh-TW"
+	default:
+		return strings.ToLower(lang)
+	}
+}
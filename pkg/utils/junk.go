@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/logger"
+)
+
+// isJunkFile 判断文件名是否匹配垃圾文件模式（不区分大小写）且小于大小上限
+func isJunkFile(name string, size int64, patterns []string, maxSizeMB int) bool {
+	if maxSizeMB > 0 && size >= int64(maxSizeMB)*1024*1024 {
+		return false
+	}
+
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// FindJunkFiles 查找源目录中匹配 Content.JunkPatterns 的样片/预告片等垃圾文件
+func FindJunkFiles(sourceFolder string, cfg *config.Config) []string {
+	patterns := cfg.Content.JunkPatterns
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	// 跳过转义文件夹和垃圾存放目录本身
+	skipFolders := strings.Split(cfg.Escape.Folders, ",")
+	if cfg.Content.JunkFolder != "" {
+		skipFolders = append(skipFolders, cfg.Content.JunkFolder)
+	}
+	for i, folder := range skipFolders {
+		skipFolders[i] = strings.TrimSpace(folder)
+	}
+
+	maxDepth := cfg.Common.ScanMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultScanMaxDepth
+	}
+
+	var junk []string
+	walkFollowingSymlinks(sourceFolder, maxDepth, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			for _, folder := range skipFolders {
+				if folder != "" && path != sourceFolder && strings.Contains(path, folder) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if isJunkFile(info.Name(), info.Size(), patterns, cfg.Content.JunkMaxSize) {
+			junk = append(junk, path)
+		}
+		return nil
+	})
+	return junk
+}
+
+// CleanJunkFiles 在扫描影片前删除或移走源目录中的垃圾文件，返回处理的文件数
+func CleanJunkFiles(sourceFolder string, cfg *config.Config) int {
+	files := FindJunkFiles(sourceFolder, cfg)
+	if len(files) == 0 {
+		return 0
+	}
+
+	move := !strings.EqualFold(cfg.Content.JunkAction, "delete")
+	junkFolder := cfg.Content.JunkFolder
+	if move && !filepath.IsAbs(junkFolder) {
+		junkFolder = filepath.Join(sourceFolder, junkFolder)
+	}
+
+	// 演练模式只列出将要处理的垃圾文件
+	if cfg.Common.DryRun {
+		for _, path := range files {
+			if move {
+				logger.Info("[DRY RUN] Would move junk file %s to %s", path, junkFolder)
+			} else {
+				logger.Info("[DRY RUN] Would remove junk file %s", path)
+			}
+		}
+		return 0
+	}
+
+	cleaned := 0
+	for _, path := range files {
+		var err error
+		if move {
+			err = moveJunkFile(path, junkFolder)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			logger.Warn("Failed to clean junk file %s: %v", path, err)
+			continue
+		}
+		logger.Debug("Cleaned junk file: %s", path)
+		cleaned++
+	}
+
+	if move {
+		logger.Info("Moved %d junk file(s) to %s", cleaned, junkFolder)
+	} else {
+		logger.Info("Deleted %d junk file(s)", cleaned)
+	}
+	return cleaned
+}
+
+// moveJunkFile 将垃圾文件移动到存放目录，重名时追加序号
+func moveJunkFile(path, junkFolder string) error {
+	if err := os.MkdirAll(junkFolder, 0755); err != nil {
+		return fmt.Errorf("failed to create junk folder: %w", err)
+	}
+
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	target := filepath.Join(junkFolder, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(junkFolder, fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+	return os.Rename(path, target)
+}
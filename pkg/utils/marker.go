@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// ProcessedMarkerName 源目录中表示“该目录下的影片已全部处理”的标记文件名
+const ProcessedMarkerName = ".mdc_processed"
+
+// hasProcessedMarker 检查目录是否带有有效的已处理标记
+// 标记写入后目录又有变动（新增或删除文件会更新目录修改时间）时标记视为失效
+func hasProcessedMarker(dir string) bool {
+	markerInfo, err := os.Stat(filepath.Join(dir, ProcessedMarkerName))
+	if err != nil {
+		return false
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	return !dirInfo.ModTime().After(markerInfo.ModTime())
+}
+
+// WriteProcessedMarkers 为源目录下所有影片都处理成功的子目录写入标记文件
+// outcomes 为本次扫描到的每个文件及其是否处理成功；只要子目录（含其下级目录）中
+// 有一个文件未成功，该子目录就不会被标记。源目录本身不会被标记。
+func WriteProcessedMarkers(sourceFolder string, outcomes map[string]bool) int {
+	root, err := filepath.Abs(sourceFolder)
+	if err != nil {
+		return 0
+	}
+
+	// 统计每个子目录下的文件总数和成功数
+	total := make(map[string]int)
+	succeeded := make(map[string]int)
+	for path, ok := range outcomes {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		for dir := filepath.Dir(abs); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+			total[dir]++
+			if ok {
+				succeeded[dir]++
+			}
+		}
+	}
+
+	written := 0
+	for dir, count := range total {
+		if succeeded[dir] != count {
+			continue
+		}
+		// 移动模式下目录可能已作为空目录被清理
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		content := fmt.Sprintf("processed %d file(s) at %s\n", count, time.Now().Format("2006-01-02 15:04:05"))
+		if err := os.WriteFile(filepath.Join(dir, ProcessedMarkerName), []byte(content), 0644); err != nil {
+			logger.Warn("Failed to write processed marker in %s: %v", dir, err)
+			continue
+		}
+		written++
+	}
+	return written
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +19,9 @@ func GetNumberFromFilename(filename string) string {
 	// 移除文件扩展名
 	name := strings.TrimSuffix(filename, filepath.Ext(filename))
 	
+	// 非UTF-8文件名（如Shift-JIS）中无法识别的字节按分隔符处理，番号部分通常是ASCII
+	name = strings.ToValidUTF8(name, " ")
+	
 	return GetNumberFromFilenameWithConfig(name, nil)
 }
 
@@ -35,6 +39,9 @@ func getNumberByBuiltinPatterns(name string) string {
 	return numberParser.GetNumber(name)
 }
 
+// DefaultScanMaxDepth 未配置 Common.ScanMaxDepth 时的最大扫描深度
+const DefaultScanMaxDepth = 32
+
 // GetMovieList 返回源文件夹中的电影文件列表
 func GetMovieList(sourceFolder string, cfg *config.Config) ([]string, error) {
 	var movieList []string
@@ -44,12 +51,20 @@ func GetMovieList(sourceFolder string, cfg *config.Config) ([]string, error) {
 	
 	// 获取要跳过的转义文件夹
 	escapeFolders := strings.Split(cfg.Escape.Folders, ",")
+	if len(cfg.Content.JunkPatterns) > 0 && cfg.Content.JunkFolder != "" {
+		escapeFolders = append(escapeFolders, cfg.Content.JunkFolder)
+	}
 	for i, folder := range escapeFolders {
 		escapeFolders[i] = strings.TrimSpace(folder)
 	}
 	
-	// 遍历源目录
-	err := filepath.Walk(sourceFolder, func(path string, info os.FileInfo, err error) error {
+	maxDepth := cfg.Common.ScanMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultScanMaxDepth
+	}
+	
+	// 遍历源目录（跟随符号链接，自动跳过循环）
+	err := walkFollowingSymlinks(sourceFolder, maxDepth, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 出错时继续
 		}
@@ -62,36 +77,19 @@ func GetMovieList(sourceFolder string, cfg *config.Config) ([]string, error) {
 					return filepath.SkipDir
 				}
 			}
-			return nil
-		}
-		
-		// 检查文件扩展名
-		ext := strings.ToLower(filepath.Ext(path))
-		supported := false
-		for _, mediaType := range mediaTypes {
-			if ext == mediaType {
-				supported = true
-				break
+			// 跳过已全部处理过的子目录（--force 时忽略标记）
+			if cfg.Common.ProcessedMarker && !cfg.Common.ForceRescan && path != sourceFolder && hasProcessedMarker(path) {
+				logger.Debug("Skipping processed folder: %s", path)
+				return filepath.SkipDir
 			}
-		}
-		
-		if !supported {
 			return nil
 		}
 		
-		// 跳过预告片文件
-		if strings.Contains(strings.ToLower(filepath.Base(path)), "trailer") {
+		// 按扩展名及其规则判断是否为要处理的影片
+		if !acceptVideoFile(path, info.Size(), mediaTypes, cfg) {
 			return nil
 		}
 		
-		// 跳过小文件（可能是广告）- 但允许大小为 0 的文件用于测试
-		if info.Size() > 0 && info.Size() < 125829120 { // 120MB
-			// 如果是调试/测试模式或明确配置则允许处理
-			if !cfg.DebugMode.Switch {
-				return nil
-			}
-		}
-		
 		// 检查文件是否在失败列表中（如果不忽略）
 		if cfg.Common.MainMode == 3 || cfg.Common.LinkMode > 0 {
 			if !cfg.Common.IgnoreFailedList {
@@ -121,6 +119,44 @@ func GetMovieList(sourceFolder string, cfg *config.Config) ([]string, error) {
 	return movieList, err
 }
 
+// DefaultMinVideoSize 小于该大小的视频文件视为广告跳过（可按扩展名通过 min_size_mb 调整）
+const DefaultMinVideoSize = 120 << 20
+
+// acceptVideoFile 按支持的扩展名和 Media.ExtensionRules 判断文件是否作为影片处理
+func acceptVideoFile(path string, size int64, mediaTypes []string, cfg *config.Config) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains(mediaTypes, ext) {
+		return false
+	}
+	
+	// 跳过预告片文件
+	name := strings.ToLower(filepath.Base(path))
+	if strings.Contains(name, "trailer") {
+		return false
+	}
+	
+	rule := cfg.GetExtensionRule(ext)
+	for _, pattern := range rule.IgnorePatterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" && strings.Contains(name, pattern) {
+			logger.Debug("Skipping %s: matches ignore pattern %q for %s", filepath.Base(path), pattern, ext)
+			return false
+		}
+	}
+	
+	// 跳过小文件（可能是广告）- 但允许大小为 0 的文件用于测试，调试模式下不限制
+	minSize := int64(DefaultMinVideoSize)
+	if rule.MinSizeMB > 0 {
+		minSize = int64(rule.MinSizeMB) << 20
+	} else if rule.MinSizeMB < 0 {
+		minSize = 0
+	}
+	if size > 0 && size < minSize && !cfg.DebugMode.Switch {
+		return false
+	}
+	return true
+}
+
 // isInFailedList 检查文件路径是否在失败列表中
 func isInFailedList(filePath, failedFolder string) bool {
 	failedListPath := filepath.Join(failedFolder, "failed_list.txt")
@@ -147,6 +183,27 @@ func IsUncensored(number string, cfg *config.Config) bool {
 	return numberParser.IsUncensored(number)
 }
 
+// HasAllowedPrefix 检查番号是否以允许的前缀开头（不区分大小写）
+// 前缀之后必须是分隔符或数字，避免 "SS" 误匹配 "SSIS-123"；未配置前缀时总是允许
+func HasAllowedPrefix(number string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+
+	number = strings.ToUpper(strings.TrimSpace(number))
+	for _, prefix := range prefixes {
+		prefix = strings.ToUpper(strings.TrimSpace(prefix))
+		if prefix == "" || !strings.HasPrefix(number, prefix) {
+			continue
+		}
+		rest := number[len(prefix):]
+		if rest == "" || strings.ContainsRune("-_ 0123456789", rune(rest[0])) {
+			return true
+		}
+	}
+	return false
+}
+
 // DebugPrint 以调试格式打印电影数据
 func DebugPrint(data *scraper.MovieData) {
 	if data == nil {
@@ -198,6 +255,26 @@ func GetImageExtension(url string) string {
 	return ".jpg"
 }
 
+// filenameYearRegex 匹配文件名中独立出现的年份，如 "[2023]"、"(2023)"、".2023."
+var filenameYearRegex = regexp.MustCompile(`(?:^|[^0-9A-Za-z-])((?:19|20)\d{2})(?:[^0-9A-Za-z]|$)`)
+
+// GetYearFromFilename 提取文件名中的年份（忽略番号中的数字），没有时返回空字符串
+func GetYearFromFilename(filename, number string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if number != "" {
+		// 不区分大小写地把番号替换为空格
+		for i := 0; i+len(number) <= len(name); i++ {
+			if strings.EqualFold(name[i:i+len(number)], number) {
+				name = name[:i] + " " + name[i+len(number):]
+			}
+		}
+	}
+	if match := filenameYearRegex.FindStringSubmatch(name); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
 // MovieFlags 表示电影文件的各种标志
 type MovieFlags struct {
 	Leak            bool   // 是否为泄露版本
@@ -298,4 +375,72 @@ func FileExists(filePath string) bool {
 // CreateDirectory 如果目录不存在则创建目录
 func CreateDirectory(path string) error {
 	return os.MkdirAll(path, 0755)
-}
\ No newline at end of file
+}
+// walkFollowingSymlinks 类似 filepath.Walk，但会跟随指向目录的符号链接。
+// 通过记录已访问目录的真实路径来检测循环，并用 maxDepth 限制递归深度
+func walkFollowingSymlinks(root string, maxDepth int, fn filepath.WalkFunc) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+
+	visited := make(map[string]bool)
+	err = walkPath(root, info, 0, maxDepth, visited, fn)
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkPath 递归遍历单个路径
+func walkPath(path string, info os.FileInfo, depth, maxDepth int, visited map[string]bool, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fn(path, info, err)
+	}
+	if visited[realPath] {
+		logger.Warn("跳过已扫描的目录（符号链接循环或重复链接）: %s -> %s", path, realPath)
+		return nil
+	}
+	visited[realPath] = true
+
+	if err := fn(path, info, nil); err != nil {
+		return err
+	}
+
+	if depth >= maxDepth {
+		logger.Warn("超过最大扫描深度 %d，跳过子目录: %s", maxDepth, path)
+		return nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fn(path, info, err)
+	}
+
+	for _, entry := range entries {
+		childPath := filepath.Join(path, entry.Name())
+
+		// os.Stat 跟随符号链接，获取目标的信息
+		childInfo, err := os.Stat(childPath)
+		if err != nil {
+			if err := fn(childPath, nil, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+
+		if err := walkPath(childPath, childInfo, depth+1, maxDepth, visited, fn); err != nil {
+			if err == filepath.SkipDir {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
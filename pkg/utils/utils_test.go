@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"movie-data-capture/internal/config"
+)
+
+func TestGetMovieList_SymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "ABC-123.mp4"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create movie file: %v", err)
+	}
+
+	// sub/loop -> root creates a cycle
+	if err := os.Symlink(root, filepath.Join(sub, "loop")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.MediaType = ".mp4"
+
+	done := make(chan []string, 1)
+	go func() {
+		movies, err := GetMovieList(root, cfg)
+		if err != nil {
+			t.Errorf("GetMovieList failed: %v", err)
+		}
+		done <- movies
+	}()
+
+	select {
+	case movies := <-done:
+		if len(movies) != 1 {
+			t.Errorf("Expected 1 movie, got %d: %v", len(movies), movies)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("GetMovieList did not finish, symlink loop not detected")
+	}
+}
+
+func TestGetMovieList_MaxDepth(t *testing.T) {
+	root := t.TempDir()
+	deep := filepath.Join(root, "a", "b", "c")
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "ABC-123.mp4"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create movie file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(deep, "ABC-456.mp4"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create movie file: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.MediaType = ".mp4"
+	cfg.Common.ScanMaxDepth = 2
+
+	movies, err := GetMovieList(root, cfg)
+	if err != nil {
+		t.Fatalf("GetMovieList failed: %v", err)
+	}
+	if len(movies) != 1 || filepath.Base(movies[0]) != "ABC-123.mp4" {
+		t.Errorf("Expected only ABC-123.mp4, got %v", movies)
+	}
+}
+
+func TestGetMovieList_ExtensionRules(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"ABC-123.ts", "ABC-456.mkv", "ABC-456-sample.mkv", "ABC-789.avi"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create movie file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.MediaType = ".mkv"
+	cfg.Media.VideoExtensions = []string{"TS"}
+	cfg.Media.ExtensionRules = map[string]config.ExtensionRule{
+		".mkv": {IgnorePatterns: []string{"Sample"}},
+	}
+
+	movies, err := GetMovieList(root, cfg)
+	if err != nil {
+		t.Fatalf("GetMovieList failed: %v", err)
+	}
+	var names []string
+	for _, movie := range movies {
+		names = append(names, filepath.Base(movie))
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "ABC-123.ts,ABC-456.mkv" {
+		t.Errorf("Expected ABC-123.ts and ABC-456.mkv, got %v", names)
+	}
+}
+
+func TestGetMovieList_ProcessedMarker(t *testing.T) {
+	root := t.TempDir()
+	done := filepath.Join(root, "done")
+	pending := filepath.Join(root, "pending")
+	for _, dir := range []string{done, pending} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	files := map[string]bool{
+		filepath.Join(done, "ABC-123.mp4"):    true,
+		filepath.Join(pending, "ABC-456.mp4"): true,
+		filepath.Join(pending, "ABC-789.mp4"): false,
+	}
+	for path := range files {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to create movie file: %v", err)
+		}
+	}
+
+	if n := WriteProcessedMarkers(root, files); n != 1 {
+		t.Fatalf("Expected 1 marker, got %d", n)
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.MediaType = ".mp4"
+	cfg.Common.ProcessedMarker = true
+
+	movies, err := GetMovieList(root, cfg)
+	if err != nil {
+		t.Fatalf("GetMovieList failed: %v", err)
+	}
+	if len(movies) != 2 {
+		t.Errorf("Expected the marked folder to be skipped, got %v", movies)
+	}
+
+	cfg.Common.ForceRescan = true
+	movies, err = GetMovieList(root, cfg)
+	if err != nil {
+		t.Fatalf("GetMovieList failed: %v", err)
+	}
+	if len(movies) != 3 {
+		t.Errorf("Expected all movies with --force, got %v", movies)
+	}
+}
+
+func TestCleanJunkFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"ABC-123.mp4", "sample-abc123.mp4", "Sample-ABC123.MP4"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Media.MediaType = ".mp4"
+	cfg.Content.JunkPatterns = []string{"sample-*"}
+	cfg.Content.JunkAction = "move"
+	cfg.Content.JunkFolder = "junk"
+
+	if n := CleanJunkFiles(root, cfg); n != 2 {
+		t.Fatalf("Expected 2 junk files cleaned, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(root, "junk", "sample-abc123.mp4")); err != nil {
+		t.Errorf("Expected junk file in junk folder: %v", err)
+	}
+
+	movies, err := GetMovieList(root, cfg)
+	if err != nil {
+		t.Fatalf("GetMovieList failed: %v", err)
+	}
+	if len(movies) != 1 || filepath.Base(movies[0]) != "ABC-123.mp4" {
+		t.Errorf("Expected only the movie to be listed, got %v", movies)
+	}
+}
+
+func TestHasAllowedPrefix(t *testing.T) {
+	prefixes := []string{"SSIS", "fc2"}
+	cases := map[string]bool{
+		"SSIS-123":       true,
+		"ssis123":        true,
+		"FC2-PPV-123456": true,
+		"SSNI-456":       false,
+		"SSISX-1":        false,
+		"VID_20230101":   false,
+	}
+	for number, want := range cases {
+		if got := HasAllowedPrefix(number, prefixes); got != want {
+			t.Errorf("HasAllowedPrefix(%q) = %v, want %v", number, got, want)
+		}
+	}
+	if !HasAllowedPrefix("ANY-1", nil) {
+		t.Errorf("Expected every number to be allowed without prefixes")
+	}
+}
+
+func TestGetNumberFromFilename_NonUTF8(t *testing.T) {
+	// "ABC-123 テスト.mp4" encoded as Shift-JIS
+	name := "ABC-123 \x83e\x83X\x83g.mp4"
+	if got := GetNumberFromFilename(name); got != "ABC-123" {
+		t.Errorf("GetNumberFromFilename(%q) = %q, want ABC-123", name, got)
+	}
+}
+
+func TestGetNumberFromFilename_Patterns(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected string
+	}{
+		// FC2
+		{"FC2-PPV-1234567.mp4", "FC2-1234567"},
+		{"FC2PPV_1234567.mp4", "FC2-1234567"},
+		{"fc2-ppv-1234567-1.mp4", "FC2-1234567"},
+		{"[JAV] FC2-PPV-1234567 [1080p].mp4", "FC2-1234567"},
+		// 无码片商
+		{"heyzo-1234.mp4", "HEYZO-1234"},
+		{"HEYZO_hd_1234_full.mp4", "HEYZO-1234"},
+		{"1pondo_012345_678.mp4", "012345-678"},
+		{"pacopacomama_123456_789.mp4", "123456-789"},
+		{"10musume_123456_01.mp4", "123456-01"},
+		{"carib-123456-789.mp4", "123456-789"},
+		{"caribbeancom_123456_789.mp4", "123456-789"},
+		{"Tokyo-Hot n1234.mp4", "N1234"},
+		// 分辨率和发布组标签
+		{"[1080p] ABC-123.mp4", "ABC-123"},
+		{"ABC-123 [1080p].mp4", "ABC-123"},
+		{"[HD] [Group] ABC-123 [1080p].mkv", "ABC-123"},
+		{"[ThZu.Cc]ABC-123.mp4", "ABC-123"},
+		{"[44x.me]SSIS-001.mp4", "SSIS-001"},
+		{"【字幕组】[SSIS-001].mp4", "SSIS-001"},
+	}
+
+	for _, tt := range tests {
+		if got := GetNumberFromFilename(tt.filename); got != tt.expected {
+			t.Errorf("GetNumberFromFilename(%q) = %q, want %q", tt.filename, got, tt.expected)
+		}
+	}
+}
+
+func TestGetYearFromFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		number   string
+		expected string
+	}{
+		{"ABC-123 (2019).mp4", "ABC-123", "2019"},
+		{"abc-2020 [2018].mkv", "ABC-2020", "2018"},
+		{"ABC-2020.mp4", "ABC-2020", ""},
+		{"【字幕】ABC-123 2021.mp4", "ABC-123", "2021"},
+		{"ABC-123.mp4", "ABC-123", ""},
+	}
+
+	for _, tt := range tests {
+		if got := GetYearFromFilename(tt.filename, tt.number); got != tt.expected {
+			t.Errorf("GetYearFromFilename(%q, %q) = %q, want %q", tt.filename, tt.number, got, tt.expected)
+		}
+	}
+}
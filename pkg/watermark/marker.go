@@ -0,0 +1,70 @@
+package watermark
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+
+	"movie-data-capture/pkg/logger"
+)
+
+// MarkerSuffix 水印记录文件的后缀，与图片位于同一目录（如 poster.jpg.watermark）
+const MarkerSuffix = ".watermark"
+
+// markerInfo 水印记录：添加的水印和添加后图片内容的SHA1
+// 图片被重新下载或裁剪后内容改变，记录即失效，会重新添加水印
+type markerInfo struct {
+	Marks []string `json:"marks"`
+	SHA1  string   `json:"sha1"`
+}
+
+// fileSHA1 计算文件内容的SHA1
+func fileSHA1(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// alreadyMarked 判断图片是否已由之前的运行添加过水印（记录存在且图片内容未变）
+func alreadyMarked(imagePath string) ([]string, bool) {
+	data, err := os.ReadFile(imagePath + MarkerSuffix)
+	if err != nil {
+		return nil, false
+	}
+	var info markerInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, false
+	}
+
+	sum, err := fileSHA1(imagePath)
+	if err != nil || sum != info.SHA1 {
+		return nil, false
+	}
+	return info.Marks, true
+}
+
+// writeMarker 记录图片已添加的水印
+func writeMarker(imagePath string, marks []string) {
+	sum, err := fileSHA1(imagePath)
+	if err != nil {
+		logger.Warn("Failed to record watermark of %s: %v", imagePath, err)
+		return
+	}
+	data, err := json.Marshal(markerInfo{Marks: marks, SHA1: sum})
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(imagePath+MarkerSuffix, data, 0644); err != nil {
+		logger.Warn("Failed to record watermark of %s: %v", imagePath, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"movie-data-capture/internal/config"
+	"movie-data-capture/pkg/httpclient"
 	"movie-data-capture/pkg/logger"
 )
 
@@ -91,21 +92,40 @@ func (wp *WatermarkProcessor) AddWatermarksExtended(posterPath, thumbPath string
 		return nil
 	}
 
-	// 向海报和缩略图添加水印
-	err := wp.addWatermarksToImageExtended(posterPath, cnSub, leak, uncensored, hack, fourK, eightK, iso, youma, umr)
-	if err != nil {
-		logger.Warn("Failed to add watermarks to poster: %v", err)
+	// 按 Watermark.ApplyTo 向海报和/或缩略图添加水印
+	toPoster, toThumb := wp.config.GetWatermarkTargets()
+	if toPoster && !wp.skipMarked(posterPath) {
+		if err := wp.addWatermarksToImageExtended(posterPath, cnSub, leak, uncensored, hack, fourK, eightK, iso, youma, umr); err != nil {
+			logger.Warn("Failed to add watermarks to poster: %v", err)
+		} else if wp.config.Watermark.SkipMarked {
+			writeMarker(posterPath, markTypes)
+		}
 	}
 
-	err = wp.addWatermarksToImageExtended(thumbPath, cnSub, leak, uncensored, hack, fourK, eightK, iso, youma, umr)
-	if err != nil {
-		logger.Warn("Failed to add watermarks to thumbnail: %v", err)
+	if toThumb && !wp.skipMarked(thumbPath) {
+		if err := wp.addWatermarksToImageExtended(thumbPath, cnSub, leak, uncensored, hack, fourK, eightK, iso, youma, umr); err != nil {
+			logger.Warn("Failed to add watermarks to thumbnail: %v", err)
+		} else if wp.config.Watermark.SkipMarked {
+			writeMarker(thumbPath, markTypes)
+		}
 	}
 
 	logger.Info("[+]Add Mark: %s", strings.Join(markTypes, ","))
 	return nil
 }
 
+// skipMarked 开启 Watermark.SkipMarked 时，图片已由之前的运行添加过水印则跳过，避免重复叠加角标
+func (wp *WatermarkProcessor) skipMarked(imagePath string) bool {
+	if !wp.config.Watermark.SkipMarked || imagePath == "" {
+		return false
+	}
+	marks, ok := alreadyMarked(imagePath)
+	if ok {
+		logger.Debug("Watermarks already applied to %s (%s), skipping", filepath.Base(imagePath), strings.Join(marks, ","))
+	}
+	return ok
+}
+
 // addWatermarksToImage 向单个图像添加水印（旧版接口）
 func (wp *WatermarkProcessor) addWatermarksToImage(imagePath string, cnSub, leak, uncensored, hack, fourK, iso bool) error {
 	return wp.addWatermarksToImageExtended(imagePath, cnSub, leak, uncensored, hack, fourK, false, iso, false, hack)
@@ -281,7 +301,8 @@ func (wp *WatermarkProcessor) getWatermarkImage(wmType WatermarkType) (image.Ima
 
 // downloadWatermarkImage 从URL下载水印图像
 func (wp *WatermarkProcessor) downloadWatermarkImage(url string) (image.Image, error) {
-	resp, err := http.Get(url)
+	client := &http.Client{Transport: httpclient.WrapTransport(nil)}
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download watermark image: %w", err)
 	}